@@ -0,0 +1,142 @@
+// Package activity builds per-wallet activity statistics (transactions, active periods, distinct
+// tasks and contracts, volume and fees) from the transaction log and checks them against the
+// airdrop-eligibility targets from the config.
+package activity
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"retro/internal/storage"
+	"retro/internal/types"
+)
+
+// Stats is the activity of one wallet in one network.
+type Stats struct {
+	WalletAddress string `json:"wallet_address"`
+	Network       string `json:"network"`
+	// TxCount is the number of on-chain transactions: the tracked ones, or the nonce when it was
+	// read from the network and is higher (transactions sent outside the application).
+	TxCount int `json:"tx_count"`
+	// TrackedTxs is the number of transactions known from the database.
+	TrackedTxs int `json:"tracked_txs"`
+	// Nonce is the on-chain transaction count, if it was requested.
+	Nonce        *uint64 `json:"nonce,omitempty"`
+	ActiveDays   int     `json:"active_days"`
+	ActiveWeeks  int     `json:"active_weeks"`
+	ActiveMonths int     `json:"active_months"`
+	// DistinctTasks counts the task types executed successfully.
+	DistinctTasks int `json:"distinct_tasks"`
+	// DistinctContracts counts the recipient addresses of the sent transactions.
+	DistinctContracts int `json:"distinct_contracts"`
+	// VolumeWei is the native coin value sent with the transactions.
+	VolumeWei *big.Int `json:"volume_wei"`
+	// FeesWei is the total fee paid for the transactions.
+	FeesWei       *big.Int   `json:"fees_wei"`
+	FirstActivity *time.Time `json:"first_activity,omitempty"`
+	LastActivity  *time.Time `json:"last_activity,omitempty"`
+	// Shortfalls lists the targets the wallet has not reached; set by Check.
+	Shortfalls []string `json:"shortfalls,omitempty"`
+}
+
+// accumulator collects the distinct values behind Stats.
+type accumulator struct {
+	stats     *Stats
+	txHashes  map[string]bool
+	days      map[string]bool
+	weeks     map[string]bool
+	months    map[string]bool
+	tasks     map[types.TaskName]bool
+	contracts map[string]bool
+}
+
+func (a *accumulator) active(at time.Time) {
+	at = at.UTC()
+	year, week := at.ISOWeek()
+	a.days[at.Format(time.DateOnly)] = true
+	a.weeks[fmt.Sprintf("%d-W%02d", year, week)] = true
+	a.months[at.Format("2006-01")] = true
+	if a.stats.FirstActivity == nil || at.Before(*a.stats.FirstActivity) {
+		a.stats.FirstActivity = &at
+	}
+	if a.stats.LastActivity == nil || at.After(*a.stats.LastActivity) {
+		a.stats.LastActivity = &at
+	}
+}
+
+// Build computes the stats of every wallet in every network, in the given order. Successful task executions count
+// as activity; transactions are taken from the broadcasts table (mined or reverted, as both consume
+// a nonce) and from the transaction hashes of successful executions.
+func Build(wallets, networks []string, records []storage.TransactionRecord, broadcasts []storage.BroadcastRecord) []*Stats {
+	accumulators := make(map[string]*accumulator)
+	key := func(wallet, network string) string {
+		return strings.ToLower(wallet) + "|" + network
+	}
+	var result []*Stats
+	for _, wallet := range wallets {
+		for _, network := range networks {
+			stats := &Stats{WalletAddress: wallet, Network: network, VolumeWei: new(big.Int), FeesWei: new(big.Int)}
+			accumulators[key(wallet, network)] = &accumulator{
+				stats:     stats,
+				txHashes:  make(map[string]bool),
+				days:      make(map[string]bool),
+				weeks:     make(map[string]bool),
+				months:    make(map[string]bool),
+				tasks:     make(map[types.TaskName]bool),
+				contracts: make(map[string]bool),
+			}
+			result = append(result, stats)
+		}
+	}
+
+	for _, record := range records {
+		acc, ok := accumulators[key(record.WalletAddress, record.Network)]
+		if !ok || record.Status != types.TxStatusSuccess {
+			continue
+		}
+		acc.active(record.Timestamp)
+		acc.tasks[record.TaskName] = true
+		if record.TxHash != "" {
+			acc.txHashes[strings.ToLower(record.TxHash)] = true
+		}
+	}
+	for _, broadcast := range broadcasts {
+		acc, ok := accumulators[key(broadcast.WalletAddress, broadcast.Network)]
+		if !ok || broadcast.Status != types.BroadcastMined && broadcast.Status != types.BroadcastReverted {
+			continue
+		}
+		acc.active(broadcast.Timestamp)
+		acc.txHashes[strings.ToLower(broadcast.TxHash)] = true
+		if broadcast.To != "" {
+			acc.contracts[strings.ToLower(broadcast.To)] = true
+		}
+		if value, ok := new(big.Int).SetString(broadcast.ValueWei, 10); ok && broadcast.Status == types.BroadcastMined {
+			acc.stats.VolumeWei.Add(acc.stats.VolumeWei, value)
+		}
+		if fee, ok := new(big.Int).SetString(broadcast.FeeWei, 10); ok {
+			acc.stats.FeesWei.Add(acc.stats.FeesWei, fee)
+		}
+	}
+
+	for _, acc := range accumulators {
+		stats := acc.stats
+		stats.TrackedTxs = len(acc.txHashes)
+		stats.TxCount = stats.TrackedTxs
+		stats.ActiveDays = len(acc.days)
+		stats.ActiveWeeks = len(acc.weeks)
+		stats.ActiveMonths = len(acc.months)
+		stats.DistinctTasks = len(acc.tasks)
+		stats.DistinctContracts = len(acc.contracts)
+	}
+	return result
+}
+
+// SetNonce records the on-chain nonce; it raises TxCount when transactions were sent outside the application.
+func (s *Stats) SetNonce(nonce uint64) {
+	s.Nonce = &nonce
+	if int(nonce) > s.TxCount {
+		s.TxCount = int(nonce)
+	}
+}
@@ -0,0 +1,37 @@
+package activity
+
+import (
+	"fmt"
+
+	"retro/internal/config"
+	"retro/internal/utils"
+)
+
+// Check sets the shortfalls of the stats against the targets and reports whether all were reached.
+func (s *Stats) Check(targets config.ActivityTargets) bool {
+	s.Shortfalls = nil
+	counters := []struct {
+		name   string
+		value  int
+		target int
+	}{
+		{"tx_count", s.TxCount, targets.TxCount},
+		{"active_days", s.ActiveDays, targets.ActiveDays},
+		{"active_weeks", s.ActiveWeeks, targets.ActiveWeeks},
+		{"active_months", s.ActiveMonths, targets.ActiveMonths},
+		{"distinct_tasks", s.DistinctTasks, targets.DistinctTasks},
+		{"distinct_contracts", s.DistinctContracts, targets.DistinctContracts},
+	}
+	for _, counter := range counters {
+		if counter.value < counter.target {
+			s.Shortfalls = append(s.Shortfalls, fmt.Sprintf("%s %d/%d", counter.name, counter.value, counter.target))
+		}
+	}
+	if targets.MinVolume != "" {
+		// The config validation guarantees a decimal amount.
+		if minVolume, err := utils.ToWei(targets.MinVolume); err == nil && s.VolumeWei.Cmp(minVolume) < 0 {
+			s.Shortfalls = append(s.Shortfalls, fmt.Sprintf("min_volume %s/%s", utils.FromWei(s.VolumeWei), targets.MinVolume))
+		}
+	}
+	return len(s.Shortfalls) == 0
+}
@@ -3,10 +3,16 @@ package app
 import (
 	"context"
 	"sync"
+	"time"
 
 	"retro/internal/config"
+	"retro/internal/control"
+	"retro/internal/evm"
 	"retro/internal/keyloader"
 	"retro/internal/logger"
+	"retro/internal/metrics"
+	"retro/internal/notify"
+	"retro/internal/schedule"
 	"retro/internal/storage"
 )
 
@@ -18,6 +24,61 @@ type Application struct {
 	txLogger     storage.TransactionLogger
 	stateStorage storage.StateStorage
 	log          logger.Logger
+	// runID identifies the current run; it scopes task idempotency keys.
+	runID string
+	// networkGuard is the RPC rate limiter and circuit breaker shared by all wallets.
+	networkGuard *evm.NetworkGuard
+	// clientPool shares EVM clients between all tasks and wallets of the application.
+	clientPool *evm.ClientPool
+	// walletFilter restricts the run to some of the wallets; nil processes all of them.
+	walletFilter func(originalIndex int, key *keyloader.LoadedKey) bool
+	// plan is the start time schedule of the wallets when schedule mode is enabled.
+	plan   *schedule.Plan
+	planMu sync.Mutex
+	// control receives the commands of the control API; nil when the API is disabled.
+	control *control.Controller
+	// notifier sends notifications about the run; nil when notifications are disabled.
+	notifier *notify.Notifier
+	stats    runStats
+	statsMu  sync.Mutex
+}
+
+// Option configures optional dependencies of an Application.
+type Option func(*Application)
+
+// WithClientDialer replaces the RPC dialer of the EVM client pool, e.g. with a local devnet in tests.
+func WithClientDialer(dial evm.DialFunc) Option {
+	return func(a *Application) {
+		a.clientPool = evm.NewClientPool(dial, a.log)
+	}
+}
+
+// WithWalletFilter restricts the run to the wallets accepted by filter. The index is the
+// wallet's position in the keys file, as used by wallet groups.
+func WithWalletFilter(filter func(originalIndex int, key *keyloader.LoadedKey) bool) Option {
+	return func(a *Application) {
+		a.walletFilter = filter
+	}
+}
+
+// WithController connects the run to the control API: its pause points, cancellation and status.
+func WithController(c *control.Controller) Option {
+	return func(a *Application) {
+		a.control = c
+	}
+}
+
+// WithNotifier sends notifications about the run: its start and summary, failed wallets and
+// networks paused by the circuit breaker.
+func WithNotifier(n *notify.Notifier) Option {
+	return func(a *Application) {
+		a.notifier = n
+		if n != nil {
+			a.networkGuard.Breaker.OnOpen(func(network string, until time.Time) {
+				n.Notify(notify.CircuitOpen(network, until))
+			})
+		}
+	}
 }
 
 // NewApplication creates a new Application instance.
@@ -28,15 +89,42 @@ func NewApplication(
 	txLogger storage.TransactionLogger,
 	stateStorage storage.StateStorage,
 	log logger.Logger,
+	opts ...Option,
 ) *Application {
-	return &Application{
+	networkGuard := NewNetworkGuard(cfg, log)
+	a := &Application{
 		cfg:          cfg,
 		wallets:      wallets,
 		wg:           wg,
 		txLogger:     txLogger,
 		stateStorage: stateStorage,
 		log:          log,
+		networkGuard: networkGuard,
+		clientPool:   evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, networkGuard, log), log),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Close releases resources owned by the application, such as pooled EVM clients.
+// It must be called after all wallet processing has finished.
+func (a *Application) Close() error {
+	return a.clientPool.Close()
+}
+
+// NewNetworkGuard builds the shared RPC rate limiter and circuit breaker from the config.
+func NewNetworkGuard(cfg *config.Config, log logger.Logger) *evm.NetworkGuard {
+	limits := cfg.RPC.RateLimit
+	guard := &evm.NetworkGuard{
+		Limiter: evm.NewRateLimiter(limits.DefaultRPS, limits.Networks, limits.Endpoints),
 	}
+	if breaker := cfg.RPC.CircuitBreaker; breaker.Enabled {
+		cooldown, _ := breaker.Cooldown.Duration() // validated on config load
+		guard.Breaker = evm.NewCircuitBreaker(breaker.FailureThreshold, cooldown, log)
+	}
+	return guard
 }
 
 // Run starts the main application logic loop, processing wallets.
@@ -52,6 +140,11 @@ func (a *Application) Run(ctx context.Context) {
 		return
 	}
 
+	// Resolved after the wallets: planning a new schedule starts a new run.
+	a.runID = a.resolveRunID(ctx)
+	a.log.Info("Идентификатор запуска", "run_id", a.runID)
+
+	metrics.SetWalletsRemaining(len(keysToProcess))
 	a.runProcessing(ctx, keysToProcess)
 
 	a.log.Info("Завершение основного потока Application.Run.")
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"retro/internal/control"
+	"retro/internal/keyloader"
+	"retro/internal/notify"
+)
+
+// runStats counts the wallet results of the current run for its summary notification.
+type runStats struct {
+	startedAt time.Time
+	succeeded int
+	failed    int
+}
+
+// notifyRunStarted resets the run statistics and sends the run_started notification.
+func (a *Application) notifyRunStarted(wallets, workers int) {
+	a.statsMu.Lock()
+	a.stats = runStats{startedAt: time.Now()}
+	a.statsMu.Unlock()
+	a.notifier.Notify(notify.RunStarted(a.runID, wallets, workers))
+}
+
+// recordWalletResult counts the result of a wallet and sends wallet_failed for a wallet that
+// failed on its own, i.e. was neither interrupted nor canceled by the operator.
+func (a *Application) recordWalletResult(walletCtx context.Context, key *keyloader.LoadedKey, originalIndex int, err error) {
+	interrupted := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		control.WalletCancelled(walletCtx)
+
+	a.statsMu.Lock()
+	switch {
+	case err == nil:
+		a.stats.succeeded++
+	case !interrupted:
+		a.stats.failed++
+	}
+	a.statsMu.Unlock()
+
+	if err != nil && !interrupted {
+		a.notifier.Notify(notify.WalletFailed(key.Address.Hex(), originalIndex, err))
+	}
+}
+
+// allSucceeded reports whether all total wallets of the run were processed successfully.
+func (a *Application) allSucceeded(total int) bool {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+	return a.stats.succeeded == total
+}
+
+// notifyRunFinished sends the run_finished summary notification.
+func (a *Application) notifyRunFinished(ctx context.Context, total int) {
+	a.statsMu.Lock()
+	stats := a.stats
+	a.statsMu.Unlock()
+	a.notifier.Notify(notify.RunFinished(notify.RunSummary{
+		RunID:       a.runID,
+		Total:       total,
+		Succeeded:   stats.succeeded,
+		Failed:      stats.failed,
+		Interrupted: ctx.Err() != nil,
+		Duration:    time.Since(stats.startedAt),
+	}))
+}
@@ -7,12 +7,19 @@ import (
 	"sync"
 	"time"
 
+	"retro/internal/control"
+	"retro/internal/errclass"
 	"retro/internal/keyloader"
+	"retro/internal/metrics"
 	"retro/internal/processor"
 	"retro/internal/storage"
 	"retro/internal/utils"
 )
 
+// errWalletNotStarted is reported for wallets whose worker was never launched, so the
+// results handler still receives one result per wallet.
+var errWalletNotStarted = errors.New("обработка кошелька не была запущена")
+
 // result is used in the channel for parallel processing results.
 type result struct {
 	originalIndex int
@@ -51,10 +58,21 @@ func (a *Application) processWalletWorker(
 	}
 
 	a.log.Debug("Воркер начинает обработку кошелька.", "wIdx", originalIndex, "addr", key.Address.Hex())
-	proc := processor.NewProcessor(a.cfg, key, originalIndex, currentNum, totalNum, a.txLogger, a.log)
-	processErr = proc.Process(ctx)
+	proc := processor.NewProcessor(a.cfg, key, originalIndex, currentNum, totalNum, a.runID, a.networkGuard, a.clientPool, a.control, a.notifier, a.txLogger, a.log)
+	walletCtx := a.control.WalletStarted(ctx, key.Address, originalIndex)
+	metrics.WorkerStarted()
+	processErr = proc.Process(walletCtx)
+	metrics.WorkerFinished()
+	a.control.WalletFinished(key.Address, processErr)
+	a.recordWalletResult(walletCtx, key, originalIndex, processErr)
+	if control.WalletCancelled(walletCtx) && ctx.Err() == nil {
+		a.log.Warn("Обработка кошелька отменена через API управления.", "wIdx", originalIndex)
+		return
+	}
 
-	if processErr == nil {
+	if processErr == nil && a.plan != nil {
+		a.completeSlot(key)
+	} else if processErr == nil {
 		delayDuration, delayErr := utils.RandomDuration(a.cfg.Delay.BetweenAccounts)
 		if delayErr != nil {
 			a.log.Error("Ошибка получения времени задержки между аккаунтами (в воркере)",
@@ -73,11 +91,12 @@ func (a *Application) processWalletWorker(
 }
 
 // handleParallelResults listens on the results channel and processes completed wallet results.
-func (a *Application) handleParallelResults(ctx context.Context, resultsChan <-chan result, totalKeys int) {
+// A fatal wallet error cancels the run via cancelRun so no further wallets are started.
+func (a *Application) handleParallelResults(ctx context.Context, resultsChan <-chan result, totalKeys int, cancelRun context.CancelFunc) {
 	processedCount := 0
 	highestCompletedIndex := -1
 
-	if a.cfg.State.ResumeEnabled {
+	if a.resumeByIndex() {
 		loadStateCtx, loadStateCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		lastIndexStr, loadErr := a.stateStorage.GetState(loadStateCtx, "last_completed_wallet_index")
 		loadStateCancel()
@@ -102,13 +121,17 @@ func (a *Application) handleParallelResults(ctx context.Context, resultsChan <-c
 			}
 			processedCount++
 
+			if errors.Is(res.err, errWalletNotStarted) {
+				continue
+			}
+
 			if res.err == nil {
 				a.log.Debug("Кошелек успешно обработан (получен результат).", "originalIndex", res.originalIndex)
 				if res.originalIndex > highestCompletedIndex {
 					newHighest := res.originalIndex
 					a.log.Debug("Новый максимальный успешно обработанный индекс.", "newHighestIndex", newHighest, "previousHighest", highestCompletedIndex)
 					highestCompletedIndex = newHighest
-					if a.cfg.State.ResumeEnabled {
+					if a.resumeByIndex() {
 						currentIndexToSave := highestCompletedIndex
 						go func(indexValueToSave int) {
 							setStateCtx, setStateCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -126,6 +149,9 @@ func (a *Application) handleParallelResults(ctx context.Context, resultsChan <-c
 			} else {
 				if errors.Is(res.err, context.Canceled) || errors.Is(res.err, context.DeadlineExceeded) {
 					a.log.Warn("Обработка кошелька была прервана контекстом (получен результат).", "originalIndex", res.originalIndex, "error", res.err)
+				} else if errclass.IsFatal(res.err) {
+					a.log.Error("Фатальная ошибка при обработке кошелька, запуск останавливается.", "originalIndex", res.originalIndex, "error", res.err)
+					cancelRun()
 				} else {
 					a.log.Error("Обработка кошелька завершилась с ошибкой (получен результат).", "originalIndex", res.originalIndex, "error", res.err)
 				}
@@ -139,7 +165,8 @@ func (a *Application) handleParallelResults(ctx context.Context, resultsChan <-c
 }
 
 // runParallel handles processing wallets concurrently using worker goroutines.
-func (a *Application) runParallel(ctx context.Context, keysToProcess []*keyloader.LoadedKey, numWorkers int) {
+// onDone is called once the results of all wallets are handled.
+func (a *Application) runParallel(ctx context.Context, keysToProcess []*keyloader.LoadedKey, numWorkers int, onDone func()) {
 	totalWalletsInRun := len(keysToProcess)
 	a.log.Info("Запуск параллельной обработки кошельков", "count", totalWalletsInRun, "workers", numWorkers)
 
@@ -149,13 +176,17 @@ func (a *Application) runParallel(ctx context.Context, keysToProcess []*keyloade
 	}
 
 	resultsChan := make(chan result, len(keysToProcess))
+	runCtx, cancelRun := context.WithCancel(ctx)
 
 	a.wg.Add(1)
 	go func() {
 		defer a.wg.Done()
-		a.handleParallelResults(ctx, resultsChan, totalWalletsInRun)
+		defer onDone()
+		defer cancelRun()
+		a.handleParallelResults(ctx, resultsChan, totalWalletsInRun, cancelRun)
 	}()
 
+	launched := 0
 	for i, key := range keysToProcess {
 		originalIndex, findErr := a.findOriginalIndex(key.Address)
 		if findErr != nil {
@@ -165,20 +196,33 @@ func (a *Application) runParallel(ctx context.Context, keysToProcess []*keyloade
 		}
 
 		select {
-		case <-ctx.Done():
+		case <-runCtx.Done():
 			a.log.Warn("Параллельная обработка прервана (контекст отменен) перед запуском воркера.",
 				"lastAttemptedOriginalIndex", originalIndex)
 			goto endParallelLoop
 		default:
 		}
 
+		if err := a.waitForSlot(runCtx, key); err != nil {
+			a.log.Warn("Параллельная обработка прервана (контекст отменен) во время ожидания слота расписания.",
+				"lastAttemptedOriginalIndex", originalIndex)
+			goto endParallelLoop
+		}
+
+		if err := a.control.WaitIfPaused(runCtx); err != nil {
+			a.log.Warn("Параллельная обработка прервана (контекст отменен) во время паузы.",
+				"lastAttemptedOriginalIndex", originalIndex)
+			goto endParallelLoop
+		}
+
 		a.log.Debug("Ожидание свободного слота воркера...", "wIdx", originalIndex)
 		select {
 		case <-semaphore:
 			a.log.Debug("Слот воркера получен, запуск горутины.", "wIdx", originalIndex)
 			a.wg.Add(1)
-			go a.processWalletWorker(ctx, key, originalIndex, i+1, totalWalletsInRun, resultsChan, semaphore, a.wg)
-		case <-ctx.Done():
+			launched++
+			go a.processWalletWorker(runCtx, key, originalIndex, i+1, totalWalletsInRun, resultsChan, semaphore, a.wg)
+		case <-runCtx.Done():
 			a.log.Warn("Параллельная обработка прервана (контекст отменен) во время ожидания слота воркера.",
 				"lastAttemptedOriginalIndex", originalIndex)
 			goto endParallelLoop
@@ -186,5 +230,8 @@ func (a *Application) runParallel(ctx context.Context, keysToProcess []*keyloade
 	}
 
 endParallelLoop:
+	for notStarted := totalWalletsInRun - launched; notStarted > 0; notStarted-- {
+		resultsChan <- result{originalIndex: -1, err: errWalletNotStarted}
+	}
 	a.log.Info("Цикл запуска воркеров завершен. Ожидание завершения всех активных воркеров и обработки результатов...")
 }
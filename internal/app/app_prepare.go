@@ -2,9 +2,12 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"math/rand"
+	mathrand "math/rand"
 	"strconv"
+	"time"
 
 	"retro/internal/keyloader"
 	"retro/internal/storage"
@@ -13,6 +16,11 @@ import (
 
 // prepareWalletsToProcess determines the list of wallets to process based on resume state and shuffling.
 func (a *Application) prepareWalletsToProcess(ctx context.Context) ([]*keyloader.LoadedKey, error) {
+	if a.cfg.Schedule.Enabled {
+		// The schedule keeps its own progress and wallet order.
+		return a.prepareSchedule(ctx)
+	}
+
 	processedWallets := a.wallets // Start with all wallets
 	originalWalletCount := len(processedWallets)
 	lastCompletedIndex := -1
@@ -58,12 +66,77 @@ func (a *Application) prepareWalletsToProcess(ctx context.Context) ([]*keyloader
 		a.log.Info("Возобновление состояния отключено.")
 	}
 
+	processedWallets = a.filterWallets(processedWallets)
+
 	if shouldShuffle && len(processedWallets) > 1 {
 		a.log.Info("Перемешивание порядка кошельков...", "count", len(processedWallets))
-		rand.Shuffle(len(processedWallets), func(i, j int) {
+		mathrand.Shuffle(len(processedWallets), func(i, j int) {
 			processedWallets[i], processedWallets[j] = processedWallets[j], processedWallets[i]
 		})
 	}
 
 	return processedWallets, nil
 }
+
+// filterWallets keeps the wallets accepted by the wallet filter, if one is set.
+func (a *Application) filterWallets(keys []*keyloader.LoadedKey) []*keyloader.LoadedKey {
+	if a.walletFilter == nil {
+		return keys
+	}
+	filtered := make([]*keyloader.LoadedKey, 0, len(keys))
+	for _, key := range keys {
+		if originalIndex, err := a.findOriginalIndex(key.Address); err == nil && a.walletFilter(originalIndex, key) {
+			filtered = append(filtered, key)
+		}
+	}
+	if len(filtered) < len(keys) {
+		a.log.Info("Кошельки отфильтрованы", "selected", len(filtered), "skipped", len(keys)-len(filtered))
+	}
+	return filtered
+}
+
+// currentRunIDStateKey is the state key holding the ID of the run being resumed.
+const currentRunIDStateKey = "current_run_id"
+
+// resolveRunID returns the ID of the current run. With resume enabled the ID of an unfinished
+// run is reused, so steps completed before a restart are recognized by their idempotency keys.
+// The saved ID is cleared once every wallet of the run succeeds and when a new schedule is planned.
+func (a *Application) resolveRunID(ctx context.Context) string {
+	if a.cfg.State.ResumeEnabled {
+		runID, err := a.stateStorage.GetState(ctx, currentRunIDStateKey)
+		if err == nil && runID != "" {
+			a.log.Info("Продолжение сохраненного запуска", "run_id", runID)
+			return runID
+		}
+		if err != nil && !errors.Is(err, storage.ErrStateNotFound) {
+			a.log.Error("Ошибка чтения идентификатора запуска, будет создан новый.", "error", err)
+		}
+	}
+
+	runID := newRunID()
+	if a.cfg.State.ResumeEnabled {
+		if err := a.stateStorage.SetState(ctx, currentRunIDStateKey, runID); err != nil {
+			a.log.Error("Ошибка сохранения идентификатора запуска", "run_id", runID, "error", err)
+		}
+	}
+	return runID
+}
+
+// clearRunID forgets the saved run ID, so the next run gets a new one instead of reusing the
+// idempotency keys of a finished run and skipping all of its steps.
+func (a *Application) clearRunID() {
+	deleteCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := a.stateStorage.DeleteState(deleteCtx, currentRunIDStateKey); err != nil {
+		a.log.Error("Ошибка удаления идентификатора запуска", "run_id", a.runID, "error", err)
+	}
+}
+
+// newRunID generates a sortable, unique run ID.
+func newRunID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"retro/internal/keyloader"
+	"retro/internal/metrics"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -31,10 +32,21 @@ func (a *Application) runProcessing(ctx context.Context, keysToProcess []*keyloa
 			"count", len(keysToProcess), "workers", numWorkers)
 	}
 
+	metrics.SetMaxWorkers(numWorkers)
+	a.notifyRunStarted(len(keysToProcess), numWorkers)
+	runCtx, finishControl := a.control.RunStarted(ctx, a.runID, len(keysToProcess))
+	finishRun := func() {
+		a.notifyRunFinished(runCtx, len(keysToProcess))
+		if runCtx.Err() == nil && a.allSucceeded(len(keysToProcess)) {
+			a.clearRunID()
+		}
+		finishControl()
+	}
 	if isSequential {
-		a.runSequentially(ctx, keysToProcess)
+		a.runSequentially(runCtx, keysToProcess)
+		finishRun()
 	} else {
-		a.runParallel(ctx, keysToProcess, numWorkers)
+		a.runParallel(runCtx, keysToProcess, numWorkers, finishRun)
 	}
 }
 
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	mathrand "math/rand"
+	"time"
+
+	"retro/internal/keyloader"
+	"retro/internal/schedule"
+)
+
+// prepareSchedule loads the saved schedule of the wallets, or plans a new one when there is none,
+// it covers other wallets or it has been completed. It returns the wallets still to be processed in
+// start time order.
+func (a *Application) prepareSchedule(ctx context.Context) ([]*keyloader.LoadedKey, error) {
+	wallets := a.filterWallets(a.wallets)
+	addresses := make([]string, len(wallets))
+	keysByAddress := make(map[string]*keyloader.LoadedKey, len(wallets))
+	for i, key := range wallets {
+		addresses[i] = key.Address.Hex()
+		keysByAddress[addresses[i]] = key
+	}
+	if len(wallets) == 0 {
+		return nil, nil
+	}
+
+	var plan *schedule.Plan
+	if !a.cfg.DryRun {
+		saved, err := schedule.Load(ctx, a.stateStorage)
+		if err != nil {
+			a.log.Error("Ошибка чтения сохраненного расписания, будет составлено новое.", "error", err)
+		}
+		switch {
+		case saved == nil:
+		case !saved.Covers(addresses):
+			a.log.Warn("Сохраненное расписание составлено для другого набора кошельков, будет составлено новое.")
+		case len(saved.Pending()) == 0:
+			a.log.Info("Сохраненное расписание выполнено полностью, будет составлено новое.")
+		default:
+			plan = saved
+			a.log.Info("Продолжение сохраненного расписания",
+				"created_at", plan.CreatedAt.Local().Format(time.DateTime), "pending", len(plan.Pending()), "total", len(plan.Slots))
+		}
+	}
+
+	if plan == nil {
+		var err error
+		plan, err = schedule.NewPlan(a.cfg.Schedule, addresses, time.Now(), mathrand.New(mathrand.NewSource(time.Now().UnixNano())))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка составления расписания: %w", err)
+		}
+		if !a.cfg.DryRun {
+			if err := schedule.Save(ctx, a.stateStorage, plan); err != nil {
+				a.log.Error("Ошибка сохранения расписания, после перезапуска оно будет составлено заново.", "error", err)
+			}
+			// A new plan is a new run: its steps must not match those of the previous one.
+			a.clearRunID()
+		}
+		first, last := plan.Slots[0].StartAt, plan.Slots[len(plan.Slots)-1].StartAt
+		a.log.Info("Составлено расписание запуска кошельков", "wallets", len(plan.Slots),
+			"first", first.Local().Format(time.DateTime), "last", last.Local().Format(time.DateTime))
+	}
+	a.plan = plan
+
+	var keys []*keyloader.LoadedKey
+	for _, slot := range plan.Pending() {
+		keys = append(keys, keysByAddress[slot.Wallet])
+		a.log.Debug("Слот кошелька", "addr", slot.Wallet, "start_at", slot.StartAt.Local().Format(time.DateTime))
+	}
+	return keys, nil
+}
+
+// waitForSlot sleeps until the wallet's start time from the schedule. It returns at once without
+// a schedule, for a slot in the past and in dry-run mode.
+func (a *Application) waitForSlot(ctx context.Context, key *keyloader.LoadedKey) error {
+	if a.plan == nil {
+		return nil
+	}
+	var startAt time.Time
+	for _, slot := range a.plan.Slots {
+		if slot.Wallet == key.Address.Hex() {
+			startAt = slot.StartAt
+			break
+		}
+	}
+	wait := time.Until(startAt)
+	if wait <= 0 {
+		return nil
+	}
+	if a.cfg.DryRun {
+		a.log.Info("Слот кошелька по расписанию (dry-run, без ожидания)",
+			"addr", key.Address.Hex(), "start_at", startAt.Local().Format(time.DateTime))
+		return nil
+	}
+
+	a.log.Info("Ожидание слота кошелька по расписанию", "addr", key.Address.Hex(),
+		"start_at", startAt.Local().Format(time.DateTime), "wait", wait.Round(time.Second))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// completeSlot marks the wallet's slot as processed and saves the schedule.
+func (a *Application) completeSlot(key *keyloader.LoadedKey) {
+	if a.plan == nil {
+		return
+	}
+	a.planMu.Lock()
+	defer a.planMu.Unlock()
+	a.plan.MarkDone(key.Address.Hex())
+	if a.cfg.DryRun {
+		return
+	}
+	saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := schedule.Save(saveCtx, a.stateStorage, a.plan); err != nil {
+		a.log.Error("Ошибка сохранения расписания", "addr", key.Address.Hex(), "error", err)
+	}
+}
+
+// resumeByIndex reports whether progress is tracked by the last completed wallet index.
+// A schedule tracks the progress of every wallet itself.
+func (a *Application) resumeByIndex() bool {
+	return a.cfg.State.ResumeEnabled && a.plan == nil
+}
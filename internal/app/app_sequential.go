@@ -7,7 +7,10 @@ import (
 	"strconv"
 	"time"
 
+	"retro/internal/control"
+	"retro/internal/errclass"
 	"retro/internal/keyloader"
+	"retro/internal/metrics"
 	"retro/internal/processor"
 	"retro/internal/storage"
 	"retro/internal/utils"
@@ -18,11 +21,25 @@ func (a *Application) processSingleWalletSequentially(ctx context.Context, key *
 	a.log.Debug("Начало обработки одного кошелька (последовательно)",
 		"origIdx", originalIndex, "num", fmt.Sprintf("%d/%d", currentNum, totalNum), "addr", key.Address.Hex())
 
-	proc := processor.NewProcessor(a.cfg, key, originalIndex, currentNum, totalNum, a.txLogger, a.log)
-	err := proc.Process(ctx)
+	proc := processor.NewProcessor(a.cfg, key, originalIndex, currentNum, totalNum, a.runID, a.networkGuard, a.clientPool, a.control, a.notifier, a.txLogger, a.log)
+	walletCtx := a.control.WalletStarted(ctx, key.Address, originalIndex)
+	metrics.WorkerStarted()
+	err := proc.Process(walletCtx)
+	metrics.WorkerFinished()
+	a.control.WalletFinished(key.Address, err)
+	a.recordWalletResult(walletCtx, key, originalIndex, err)
 
+	if control.WalletCancelled(walletCtx) && ctx.Err() == nil {
+		// The operator stopped only this wallet: the run goes on without marking it as done. With
+		// resume by index the next completed wallet still moves last_completed_wallet_index past
+		// it, so a restart does not return to this wallet; it has to be run again separately.
+		a.log.Warn("Обработка кошелька отменена через API управления, переход к следующему (последовательно).",
+			"originalIndex", originalIndex)
+		return nil
+	}
 	if err == nil {
-		if a.cfg.State.ResumeEnabled {
+		a.completeSlot(key)
+		if a.resumeByIndex() {
 			a.log.Debug("Кошелек успешно обработан (последовательно), попытка сохранения состояния.",
 				"originalIndex", originalIndex)
 			setStateCtx, setStateCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -37,6 +54,9 @@ func (a *Application) processSingleWalletSequentially(ctx context.Context, key *
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			a.log.Warn("Обработка кошелька прервана контекстом (последовательно).",
 				"originalIndex", originalIndex, "error", err)
+		} else if errclass.IsFatal(err) {
+			a.log.Error("Фатальная ошибка при обработке кошелька, запуск останавливается (последовательно).",
+				"originalIndex", originalIndex, "error", err)
 		} else {
 			a.log.Error("Ошибка обработки кошелька (последовательно).",
 				"originalIndex", originalIndex, "error", err)
@@ -51,7 +71,7 @@ func (a *Application) runSequentially(ctx context.Context, keysToProcess []*keyl
 	a.log.Info("Запуск последовательной обработки кошельков", "count", totalWalletsInRun)
 
 	lastCompletedIndex := -1
-	if a.cfg.State.ResumeEnabled {
+	if a.resumeByIndex() {
 		loadStateCtx, loadStateCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		lastIndexStr, loadErr := a.stateStorage.GetState(loadStateCtx, "last_completed_wallet_index")
 		loadStateCancel()
@@ -75,7 +95,7 @@ func (a *Application) runSequentially(ctx context.Context, keysToProcess []*keyl
 			continue
 		}
 
-		if a.cfg.State.ResumeEnabled && originalIndex <= lastCompletedIndex {
+		if a.resumeByIndex() && originalIndex <= lastCompletedIndex {
 			a.log.Debug("Пропуск уже обработанного кошелька (последовательно).",
 				"originalIndex", originalIndex, "lastCompletedIndex", lastCompletedIndex)
 			continue
@@ -89,13 +109,25 @@ func (a *Application) runSequentially(ctx context.Context, keysToProcess []*keyl
 		default:
 		}
 
+		if err := a.waitForSlot(ctx, key); err != nil {
+			a.log.Warn("Ожидание слота кошелька прервано (контекст отменен, последовательно).",
+				"walletIndex", originalIndex)
+			return
+		}
+		if err := a.control.WaitIfPaused(ctx); err != nil {
+			a.log.Warn("Ожидание снятия паузы прервано (контекст отменен, последовательно).",
+				"walletIndex", originalIndex)
+			return
+		}
+
 		err := a.processSingleWalletSequentially(ctx, key, originalIndex, i+1, totalWalletsInRun)
 		if err != nil {
 			a.log.Warn("Прерываем последовательную обработку из-за ошибки/отмены в кошельке.", "walletIndex", originalIndex)
 			return
 		}
 
-		if i < len(keysToProcess)-1 {
+		// With a schedule the wallets are already spread over the window.
+		if i < len(keysToProcess)-1 && a.plan == nil {
 			delayDuration, delayErr := utils.RandomDuration(a.cfg.Delay.BetweenAccounts)
 			if delayErr != nil {
 				a.log.Error("Ошибка получения времени задержки между кошельками (последовательно)",
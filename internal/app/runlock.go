@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"retro/internal/logger"
+	"retro/internal/storage"
+)
+
+// RunLockName is the lock held during every run, so runs of the daemon and runs started by
+// external cron never work on the same state at the same time.
+const RunLockName = "run"
+
+// ErrRunLocked is returned when another process or schedule holds the run lock.
+var ErrRunLocked = errors.New("другой запуск уже выполняется (блокировка в БД занята)")
+
+// RunLock is a held run lock. It is renewed in the background until Close.
+type RunLock struct {
+	state     storage.StateStorage
+	owner     string
+	ttl       time.Duration
+	log       logger.Logger
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// AcquireRunLock takes the run lock for ttl and keeps renewing it every ttl/3, so only a crashed
+// holder's lock expires. It returns ErrRunLocked if the lock is held by someone else.
+func AcquireRunLock(ctx context.Context, state storage.StateStorage, ttl time.Duration, log logger.Logger) (*RunLock, error) {
+	owner := newLockOwner()
+	acquired, err := state.AcquireLock(ctx, RunLockName, owner, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения блокировки запуска: %w", err)
+	}
+	if !acquired {
+		return nil, ErrRunLocked
+	}
+	log.Debug("Блокировка запуска получена", "owner", owner, "ttl", ttl)
+
+	lock := &RunLock{state: state, owner: owner, ttl: ttl, log: log, stop: make(chan struct{}), done: make(chan struct{})}
+	go lock.renew()
+	return lock, nil
+}
+
+// renew extends the lock until Close.
+func (l *RunLock) renew() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			acquired, err := l.state.AcquireLock(ctx, RunLockName, l.owner, l.ttl)
+			cancel()
+			if err != nil {
+				l.log.Error("Ошибка продления блокировки запуска", "error", err)
+			} else if !acquired {
+				l.log.Error("Блокировка запуска потеряна: она истекла и занята другим процессом", "owner", l.owner)
+			}
+		}
+	}
+}
+
+// Close stops the renewal and releases the lock. It is safe to call more than once.
+func (l *RunLock) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err = l.state.ReleaseLock(ctx, RunLockName, l.owner)
+		if err == nil {
+			l.log.Debug("Блокировка запуска освобождена", "owner", l.owner)
+		}
+	})
+	return err
+}
+
+// newLockOwner identifies the lock holder: host, process and a random suffix per run.
+func newLockOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
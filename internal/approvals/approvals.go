@@ -0,0 +1,110 @@
+// Package approvals finds the open ERC-20 allowances of the wallets, either for known token and
+// spender pairs or for the pairs found in the Approval events of a wallet.
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// UnlimitedThreshold is the smallest allowance treated as unlimited: the maximum uint96. It also
+// covers max uint256 approvals partly spent since, and tokens that cap allowances at 96 bits.
+var UnlimitedThreshold = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1))
+
+// approvalTopic is the topic of the ERC-20 Approval(owner, spender, value) event.
+var approvalTopic = evm.ERC20ABI.Events["Approval"].ID
+
+// Pair is a token and a spender that may hold an allowance of a wallet.
+type Pair struct {
+	Token   common.Address
+	Spender common.Address
+	// Name describes the spender, e.g. "uniswap router"; empty for pairs found in logs.
+	Name string
+}
+
+// Allowance is a non-zero allowance of a wallet.
+type Allowance struct {
+	Pair
+	Owner  common.Address
+	Amount *big.Int
+}
+
+// IsUnlimited reports whether the allowance is unlimited in practice.
+func IsUnlimited(amount *big.Int) bool {
+	return amount.Cmp(UnlimitedThreshold) >= 0
+}
+
+// Unlimited reports whether the allowance is unlimited in practice.
+func (a Allowance) Unlimited() bool {
+	return IsUnlimited(a.Amount)
+}
+
+// PairsFromLogs returns the distinct token and spender pairs of the Approval events emitted for
+// owner in the block range; a nil toBlock means the latest block. ERC-721 approvals, which index
+// the token ID as well, are ignored.
+func PairsFromLogs(ctx context.Context, client evm.EVMClient, owner common.Address, fromBlock uint64, toBlock *big.Int) ([]Pair, error) {
+	logs, err := evm.FilterLogs(ctx, client, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   toBlock,
+		Topics:    [][]common.Hash{{approvalTopic}, {common.BytesToHash(owner.Bytes())}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения событий Approval: %w", err)
+	}
+	var pairs []Pair
+	for _, log := range logs {
+		if len(log.Topics) != 3 {
+			continue
+		}
+		pairs = append(pairs, Pair{Token: log.Address, Spender: common.BytesToAddress(log.Topics[2].Bytes())})
+	}
+	return Merge(pairs), nil
+}
+
+// Merge removes repeated token and spender pairs, keeping the first one (and its name).
+func Merge(pairs ...[]Pair) []Pair {
+	seen := make(map[[2]common.Address]bool)
+	var merged []Pair
+	for _, list := range pairs {
+		for _, pair := range list {
+			key := [2]common.Address{pair.Token, pair.Spender}
+			if !seen[key] {
+				seen[key] = true
+				merged = append(merged, pair)
+			}
+		}
+	}
+	return merged
+}
+
+// Scan reads the current allowance of owner for every pair and returns the non-zero ones, or
+// only the unlimited ones with unlimitedOnly. Pairs whose allowance cannot be read because the
+// token is not an ERC-20 contract are logged and skipped; RPC errors are returned.
+func Scan(ctx context.Context, client evm.EVMClient, owner common.Address, pairs []Pair, unlimitedOnly bool, log logger.Logger) ([]Allowance, error) {
+	var allowances []Allowance
+	for _, pair := range pairs {
+		amount, err := evm.ERC20Allowance(ctx, client, pair.Token, owner, pair.Spender)
+		if err != nil {
+			if errclass.Classify(err) != types.ErrorClassNonRetryable {
+				return nil, err
+			}
+			log.Warn("Не удалось прочитать allowance, пара пропущена", "network", client.Network().Name,
+				"wallet", owner.Hex(), "token", pair.Token.Hex(), "spender", pair.Spender.Hex(), "error", err)
+			continue
+		}
+		if amount.Sign() == 0 || unlimitedOnly && !IsUnlimited(amount) {
+			continue
+		}
+		allowances = append(allowances, Allowance{Pair: pair, Owner: owner, Amount: amount})
+	}
+	return allowances, nil
+}
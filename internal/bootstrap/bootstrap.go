@@ -6,22 +6,33 @@ import (
 	"retro/internal/tasks"
 	"retro/internal/types"
 
+	bridgetask "retro/internal/tasks/bridge"
 	dummytask "retro/internal/tasks/dummy"
+	revoketask "retro/internal/tasks/revoke"
+	sweeptask "retro/internal/tasks/sweep"
+	waittask "retro/internal/tasks/wait"
 )
 
 var allTask = map[types.TaskName]tasks.TaskConstructor{
-	types.TaskNameLogBalance: tasks.NewLogBalanceTask,
-	types.TaskNameDummy:      dummytask.NewTask,
+	types.TaskNameLogBalance:      tasks.NewLogBalanceTask,
+	types.TaskNameDummy:           dummytask.NewTask,
+	types.TaskNameSweep:           sweeptask.NewTask,
+	types.TaskNameRevokeApprovals: revoketask.NewTask,
+	types.TaskNameWaitForBalance:  waittask.NewTask,
+	types.TaskNameBridge:          bridgetask.NewTask,
 }
 
-// RegisterTasksFromConfig registers task constructors found in the config and the local map
+// RegisterTasksFromConfig registers task constructors found in the config and the local map.
+// Tasks that are already registered (several entries of one task, or a reloaded config) are skipped.
 func RegisterTasksFromConfig(cfg *config.Config, log logger.Logger) {
 	log.Info("Регистрация задач из конфигурации в центральном реестре...")
 	registeredCount := 0
 	for _, taskCfg := range cfg.Tasks {
 		if taskCfg.Enabled {
 			constructor, ok := allTask[taskCfg.Name]
-			if ok {
+			if ok && tasks.IsRegistered(taskCfg.Name) {
+				log.Debug("Конструктор задачи уже зарегистрирован", "task", taskCfg.Name)
+			} else if ok {
 				log.Debug("Регистрация конструктора в центральном реестре", "task", taskCfg.Name)
 				tasks.MustRegisterConstructor(taskCfg.Name, constructor)
 				registeredCount++
@@ -34,3 +45,12 @@ func RegisterTasksFromConfig(cfg *config.Config, log logger.Logger) {
 	log.Info("Задачи, зарегистрированные и доступные для выполнения",
 		"count", registeredCount, "tasks", tasks.ListTasks())
 }
+
+// KnownTasks returns the constructors of all tasks built into the application, whether enabled or not.
+func KnownTasks() map[types.TaskName]tasks.TaskConstructor {
+	known := make(map[types.TaskName]tasks.TaskConstructor, len(allTask))
+	for name, constructor := range allTask {
+		known[name] = constructor
+	}
+	return known
+}
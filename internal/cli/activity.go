@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"retro/internal/activity"
+	"retro/internal/app"
+	"retro/internal/evm"
+	"retro/internal/storage"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// activityCommand prints the activity stats of every wallet in every network and flags the wallets
+// below the airdrop-eligibility targets from activity.targets.
+func activityCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("activity")
+	format := fs.String("format", formatTable, "Output format: table, csv or json")
+	networksFlag := fs.String("networks", "", "Comma-separated networks from rpc_nodes (default: all)")
+	onchain := fs.Bool("onchain", false, "Read nonces from the networks to count transactions sent outside the application")
+	below := fs.Bool("below", false, "Only show wallets below the targets")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != formatJSON {
+		if err := checkFormat(*format); err != nil {
+			return fmt.Errorf("неизвестный формат '%s' (ожидается '%s', '%s' или '%s')", *format, formatTable, formatCSV, formatJSON)
+		}
+	}
+
+	cfg := e.config()
+	wallets := e.loadWallets()
+	networks, err := selectNetworks(cfg.RPCNodes, *networksFlag)
+	if err != nil {
+		return err
+	}
+
+	txLogger, _ := e.storage(ctx)
+	records, err := txLogger.ListTransactions(ctx, storage.TransactionFilter{})
+	if err != nil {
+		return fmt.Errorf("ошибка чтения журнала транзакций: %w", err)
+	}
+	broadcasts, err := txLogger.ListBroadcasts(ctx, storage.BroadcastFilter{})
+	if err != nil {
+		return fmt.Errorf("ошибка чтения отправленных транзакций: %w", err)
+	}
+
+	addresses := make([]string, len(wallets))
+	for i, wallet := range wallets {
+		addresses[i] = wallet.Address.Hex()
+	}
+	stats := activity.Build(addresses, networks, records, broadcasts)
+
+	if *onchain {
+		pool := evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, app.NewNetworkGuard(cfg, e.log), e.log), e.log)
+		defer pool.Close()
+		for _, network := range networks {
+			client, err := pool.Get(ctx, evm.ClientKey{Network: network})
+			if err != nil {
+				e.log.Error("Не удалось подключиться к сети, nonce не учтены", "network", network, "error", err)
+				continue
+			}
+			for _, s := range stats {
+				if s.Network != network {
+					continue
+				}
+				nonce, err := client.GetNonce(ctx, common.HexToAddress(s.WalletAddress))
+				if err != nil {
+					e.log.Warn("Не удалось получить nonce", "network", network, "wallet", s.WalletAddress, "error", err)
+					continue
+				}
+				s.SetNonce(nonce)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+
+	belowCount := 0
+	selected := make([]*activity.Stats, 0, len(stats))
+	for _, s := range stats {
+		if !s.Check(cfg.ActivityTargetsFor(s.Network)) {
+			belowCount++
+		} else if *below {
+			continue
+		}
+		selected = append(selected, s)
+	}
+	e.log.Info("Статистика активности собрана", "rows", len(stats), "below_target", belowCount)
+
+	if *format == formatJSON {
+		return writeJSON(os.Stdout, selected)
+	}
+
+	rows := make([][]string, 0, len(selected))
+	for _, s := range selected {
+		nonce, last, status := "", "", "ok"
+		if s.Nonce != nil {
+			nonce = strconv.FormatUint(*s.Nonce, 10)
+		}
+		if s.LastActivity != nil {
+			last = s.LastActivity.Local().Format(time.DateTime)
+		}
+		if len(s.Shortfalls) > 0 {
+			status = "below"
+		}
+		if *format == formatTable {
+			nonce, last = orDash(nonce), orDash(last)
+		}
+		rows = append(rows, []string{
+			s.WalletAddress, s.Network,
+			strconv.Itoa(s.TxCount), nonce,
+			strconv.Itoa(s.ActiveDays), strconv.Itoa(s.ActiveWeeks), strconv.Itoa(s.ActiveMonths),
+			strconv.Itoa(s.DistinctTasks), strconv.Itoa(s.DistinctContracts),
+			utils.FromWei(s.VolumeWei), utils.FromWei(s.FeesWei),
+			last, status, strings.Join(s.Shortfalls, "; "),
+		})
+	}
+	return writeTable(os.Stdout, *format, []string{"wallet", "network", "txs", "nonce", "active_days", "active_weeks",
+		"active_months", "tasks", "contracts", "volume", "fees", "last_activity", "status", "shortfalls"}, rows)
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"retro/internal/app"
+	"retro/internal/approvals"
+	"retro/internal/config"
+	"retro/internal/evm"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// tokenInfo is the cached metadata of a token for formatting allowances.
+type tokenInfo struct {
+	symbol   string
+	decimals uint8
+	ok       bool
+}
+
+// approvalsCommand lists the non-zero ERC-20 allowances of every wallet: for the pairs from
+// approvals.pairs and, with --from-block, for the pairs found in the wallet's Approval events.
+func approvalsCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("approvals")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	networksFlag := fs.String("networks", "", "Comma-separated networks (default: those of approvals.pairs, or all of rpc_nodes)")
+	fromBlockFlag := fs.String("from-block", "", "Also scan Approval events from this block; a negative value counts back from the latest block")
+	toBlockFlag := fs.String("to-block", "", "Last block of the event scan (default: latest)")
+	unlimited := fs.Bool("unlimited", false, "Only list unlimited allowances")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	wallets := e.loadWallets()
+	networks, err := approvalNetworks(cfg, *networksFlag)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Approvals.Pairs) == 0 && *fromBlockFlag == "" {
+		fmt.Fprintln(os.Stderr, "нет пар для проверки: задайте approvals.pairs в конфигурации или --from-block")
+		return errUsage
+	}
+
+	pool := evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, app.NewNetworkGuard(cfg, e.log), e.log), e.log)
+	defer pool.Close()
+
+	var rows [][]string
+	for _, network := range networks {
+		client, err := pool.Get(ctx, evm.ClientKey{Network: network})
+		if err != nil {
+			e.log.Error("Не удалось подключиться к сети, сеть пропущена", "network", network, "error", err)
+			continue
+		}
+		var configured []approvals.Pair
+		for _, pair := range cfg.Approvals.Pairs[network] {
+			configured = append(configured, approvals.Pair{
+				Token:   common.HexToAddress(pair.Token),
+				Spender: common.HexToAddress(pair.Spender),
+				Name:    pair.Name,
+			})
+		}
+		fromBlock, toBlock, scanLogs, err := blockRange(ctx, client, *fromBlockFlag, *toBlockFlag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", network, err)
+		}
+
+		tokens := make(map[common.Address]tokenInfo)
+		for i, wallet := range wallets {
+			pairs := configured
+			if scanLogs {
+				found, err := approvals.PairsFromLogs(ctx, client, wallet.Address, fromBlock, toBlock)
+				if err != nil {
+					e.log.Error("Не удалось прочитать события Approval", "network", network, "wallet", wallet.Address.Hex(), "error", err)
+				}
+				pairs = approvals.Merge(configured, found)
+			}
+			allowances, err := approvals.Scan(ctx, client, wallet.Address, pairs, *unlimited, e.log)
+			if err != nil {
+				e.log.Error("Не удалось проверить allowance кошелька", "network", network, "wallet", wallet.Address.Hex(), "error", err)
+				continue
+			}
+			for _, allowance := range allowances {
+				info, ok := tokens[allowance.Token]
+				if !ok {
+					info = readTokenInfo(ctx, client, allowance.Token)
+					tokens[allowance.Token] = info
+				}
+				amount := allowance.Amount.String()
+				if allowance.Unlimited() {
+					amount = "unlimited"
+				} else if info.ok {
+					amount = utils.FromUnits(allowance.Amount, info.decimals)
+				}
+				rows = append(rows, []string{strconv.Itoa(i), wallet.Address.Hex(), network, info.symbol, allowance.Token.Hex(),
+					allowance.Spender.Hex(), allowance.Name, amount, allowance.Amount.String()})
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+
+	header := []string{"index", "address", "network", "token", "token_address", "spender", "spender_name", "allowance", "allowance_raw"}
+	if *format == formatTable {
+		// The table shows formatted allowances only; raw amounts are in CSV.
+		header = header[:len(header)-1]
+		for i, row := range rows {
+			row[6] = orDash(row[6])
+			rows[i] = row[:len(row)-1]
+		}
+	}
+	return writeTable(os.Stdout, *format, header, rows)
+}
+
+// approvalNetworks returns the networks to check: the requested ones, those of approvals.pairs,
+// or all networks of rpc_nodes.
+func approvalNetworks(cfg *config.Config, requested string) ([]string, error) {
+	if requested != "" || len(cfg.Approvals.Pairs) == 0 {
+		return selectNetworks(cfg.RPCNodes, requested)
+	}
+	networks := make([]string, 0, len(cfg.Approvals.Pairs))
+	for network := range cfg.Approvals.Pairs {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	return networks, nil
+}
+
+// blockRange resolves the --from-block and --to-block flags; scan is false without --from-block.
+// A nil toBlock means the latest block.
+func blockRange(ctx context.Context, client evm.EVMClient, fromFlag, toFlag string) (from uint64, to *big.Int, scan bool, err error) {
+	if fromFlag == "" {
+		return 0, nil, false, nil
+	}
+	if back, ok := strings.CutPrefix(fromFlag, "-"); ok {
+		blocks, err := strconv.ParseUint(back, 10, 64)
+		if err != nil {
+			return 0, nil, false, fmt.Errorf("неверный --from-block '%s'", fromFlag)
+		}
+		latest, err := client.BlockNumber(ctx)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		if blocks < latest {
+			from = latest - blocks
+		}
+	} else if from, err = strconv.ParseUint(fromFlag, 10, 64); err != nil {
+		return 0, nil, false, fmt.Errorf("неверный --from-block '%s'", fromFlag)
+	}
+	if toFlag != "" {
+		block, err := strconv.ParseUint(toFlag, 10, 64)
+		if err != nil || block < from {
+			return 0, nil, false, fmt.Errorf("неверный --to-block '%s'", toFlag)
+		}
+		to = new(big.Int).SetUint64(block)
+	}
+	return from, to, true, nil
+}
+
+// readTokenInfo reads the symbol and decimals of a token; the address is shown when they are unknown.
+func readTokenInfo(ctx context.Context, client evm.EVMClient, token common.Address) tokenInfo {
+	info := tokenInfo{symbol: token.Hex()}
+	if symbol, err := evm.ERC20Symbol(ctx, client, token); err == nil && symbol != "" {
+		info.symbol = symbol
+	}
+	if decimals, err := evm.ERC20Decimals(ctx, client, token); err == nil {
+		info.decimals, info.ok = decimals, true
+	}
+	return info
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"retro/internal/app"
+	"retro/internal/config"
+	"retro/internal/evm"
+	"retro/internal/snapshot"
+	"retro/internal/utils"
+)
+
+// balancesCommand prints the native balance and the configured token balances of every wallet
+// in every network (or in the networks given by --networks).
+func balancesCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("balances")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	networksFlag := fs.String("networks", "", "Comma-separated networks from rpc_nodes (default: all)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	wallets := e.loadWallets()
+
+	networks, err := selectNetworks(cfg.RPCNodes, *networksFlag)
+	if err != nil {
+		return err
+	}
+
+	pool := evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, app.NewNetworkGuard(cfg, e.log), e.log), e.log)
+	defer pool.Close()
+
+	var rows [][]string
+	for _, network := range networks {
+		client, err := pool.Get(ctx, evm.ClientKey{Network: network})
+		if err != nil {
+			e.log.Error("Не удалось подключиться к сети, сеть пропущена", "network", network, "error", err)
+			continue
+		}
+		assets := snapshot.NetworkAssets(ctx, client, cfg.Balances.Tokens[network], e.log)
+
+		for i, wallet := range wallets {
+			for _, asset := range assets {
+				balance, raw := "ERROR", ""
+				amount, err := asset.BalanceOf(ctx, client, wallet.Address)
+				if err != nil {
+					e.log.Warn("Не удалось получить баланс", "network", network, "asset", asset.Symbol,
+						"wallet", wallet.Address.Hex(), "error", err)
+				} else {
+					balance, raw = utils.FromUnits(amount, asset.Decimals), amount.String()
+				}
+				rows = append(rows, []string{strconv.Itoa(i), wallet.Address.Hex(), wallet.Label, network, asset.Symbol, asset.TokenHex(), balance, raw})
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+
+	header := []string{"index", "address", "label", "network", "asset", "token", "balance", "balance_raw"}
+	if *format == formatTable {
+		// The table shows formatted balances only; raw amounts and token addresses are in CSV.
+		header = header[:len(header)-1]
+		for i, row := range rows {
+			row[2], row[5] = orDash(row[2]), orDash(row[5])
+			rows[i] = row[:len(row)-1]
+		}
+	}
+	return writeTable(os.Stdout, *format, header, rows)
+}
+
+// selectNetworks returns the sorted networks to query: all from rpc_nodes or the requested ones.
+func selectNetworks(rpcNodes map[string]config.NetworkConfig, requested string) ([]string, error) {
+	var networks []string
+	if requested == "" {
+		for network := range rpcNodes {
+			networks = append(networks, network)
+		}
+	} else {
+		for _, network := range strings.Split(requested, ",") {
+			network = strings.TrimSpace(network)
+			if _, ok := rpcNodes[network]; !ok {
+				return nil, fmt.Errorf("сеть '%s' не найдена в rpc_nodes", network)
+			}
+			networks = append(networks, network)
+		}
+	}
+	sort.Strings(networks)
+	return networks, nil
+}
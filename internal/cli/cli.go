@@ -0,0 +1,140 @@
+// Package cli implements the command-line interface: the `run` command and the everyday
+// maintenance commands sharing config, keys and storage loading.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"retro/internal/logger"
+)
+
+// Default paths of the config and private keys files.
+const (
+	defaultConfigPath  = "config/config.yml"
+	defaultWalletsPath = "local/data/private_keys.txt"
+)
+
+// command is a CLI subcommand. Its name may consist of two words, e.g. "wallets list".
+type command struct {
+	name    string
+	summary string
+	// dataOutput commands print their result to stdout, so their logs go to stderr.
+	dataOutput bool
+	run        func(ctx context.Context, e *env, args []string) error
+}
+
+// commands lists all subcommands in the order they are shown in the usage text.
+var commands = []command{
+	{name: "run", summary: "выполнить задачи для всех кошельков (команда по умолчанию)", run: runCommand},
+	{name: "daemon", summary: "работать постоянно и запускать задачи по cron-расписаниям из daemon.schedules", run: daemonCommand},
+	{name: "balances", summary: "нативные и токен-балансы кошельков по сетям", dataOutput: true, run: balancesCommand},
+	{name: "fund", summary: "пополнить кошельки с мастер-кошелька до случайного целевого баланса", dataOutput: true, run: fundCommand},
+	{name: "sweep", summary: "собрать токены и нативную монету кошельков на адрес сбора", dataOutput: true, run: sweepCommand},
+	{name: "wallets list", summary: "список кошельков: индекс, адрес, метка, группы", dataOutput: true, run: walletsListCommand},
+	{name: "tasks list", summary: "задачи и схемы их параметров", dataOutput: true, run: tasksListCommand},
+	{name: "state show", summary: "показать сохраненное состояние", dataOutput: true, run: stateShowCommand},
+	{name: "state reset", summary: "удалить сохраненное состояние (все ключи или указанные)", dataOutput: true, run: stateResetCommand},
+	{name: "snapshot", summary: "снять и сохранить снимок балансов и nonce по всем сетям", dataOutput: true, run: snapshotCommand},
+	{name: "snapshot list", summary: "список сохраненных снимков", dataOutput: true, run: snapshotListCommand},
+	{name: "snapshot diff", summary: "изменения между двумя снимками и потраченный газ", dataOutput: true, run: snapshotDiffCommand},
+	{name: "approvals", summary: "открытые ERC-20 allowance кошельков по парам токен/spender и событиям Approval", dataOutput: true, run: approvalsCommand},
+	{name: "activity", summary: "статистика активности кошельков и проверка целевых порогов", dataOutput: true, run: activityCommand},
+	{name: "report", summary: "сводка по журналу транзакций из БД", dataOutput: true, run: reportCommand},
+}
+
+// errUsage is returned for an unknown command or invalid flags; the usage has already been printed.
+var errUsage = errors.New("invalid usage")
+
+// Execute runs the command given by args (os.Args[1:]) and returns the process exit code.
+// Without a command (or when args start with a flag) the full run is started, as before subcommands existed.
+func Execute(args []string) int {
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		printUsage(os.Stderr)
+		if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "\nнеизвестная команда: %s\n", strings.Join(args, " "))
+		return 2
+	}
+
+	log := logger.NewColorLogger()
+	if cmd.dataOutput {
+		log = logger.NewColorLoggerTo(os.Stderr)
+	}
+	e := &env{log: log}
+	defer e.close()
+
+	ctx := context.Background()
+	if cmd.name != "run" {
+		// run installs its own handler that also closes the storage.
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+	}
+
+	if err := cmd.run(ctx, e, rest); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		log.Error("Команда завершилась с ошибкой", "command", cmd.name, "error", err)
+		return 1
+	}
+	return 0
+}
+
+// findCommand matches the longest command name at the start of args.
+func findCommand(args []string) (command, []string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help" {
+		return commands[0], args, true
+	}
+	if len(args) >= 2 {
+		for _, cmd := range commands {
+			if cmd.name == args[0]+" "+args[1] {
+				return cmd, args[2:], true
+			}
+		}
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd, args[1:], true
+		}
+	}
+	return command{}, nil, false
+}
+
+// printUsage writes the list of commands.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Использование: retro [команда] [флаги]")
+	fmt.Fprintln(w, "\nКоманды:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nОбщие флаги: --config (по умолчанию "+defaultConfigPath+"), --wallets (по умолчанию "+defaultWalletsPath+").")
+	fmt.Fprintln(w, "Флаги команды: retro <команда> -h")
+}
+
+// newFlagSet creates the flag set of a command with the shared --config and --wallets flags.
+func (e *env) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.StringVar(&e.configPath, "config", defaultConfigPath, "Path to the configuration file")
+	fs.StringVar(&e.walletsPath, "wallets", defaultWalletsPath, "Path to the private keys file")
+	return fs
+}
+
+// parseFlags parses command flags; positional arguments are returned.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+
+	"retro/internal/app"
+	"retro/internal/control"
+	"retro/internal/daemon"
+	"retro/internal/metrics"
+	"retro/internal/notify"
+	"retro/internal/types"
+)
+
+// daemonCommand keeps the application running and starts runs on the cron schedules from daemon.schedules.
+func daemonCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("daemon")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	if cfg.Database.Type == types.None || cfg.Database.Type == "" {
+		e.log.Warn("Хранилище отключено (database.type: none): запуски не блокируются и не возобновляются")
+	}
+	if err := metrics.Serve(ctx, cfg.Metrics, e.log); err != nil {
+		return err
+	}
+	var controller *control.Controller
+	if cfg.Control.Enabled {
+		controller = control.New(e.log)
+		if err := control.Serve(ctx, cfg.Control, controller, e.log); err != nil {
+			return err
+		}
+	}
+	notifier, err := notify.New(cfg.Notifications, e.log)
+	if err != nil {
+		return err
+	}
+	defer notifier.Close(notifyFlushTimeout)
+	txLogger, stateStorage := e.storage(ctx)
+	wallets := e.loadWallets()
+
+	return daemon.New(e.configPath, cfg, wallets, txLogger, stateStorage, e.log,
+		app.WithController(controller), app.WithNotifier(notifier)).Run(ctx)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"retro/internal/config"
+	"retro/internal/keyloader"
+	"retro/internal/logger"
+	"retro/internal/platform/database"
+	"retro/internal/storage"
+)
+
+// env loads the config, keys and storage shared by all commands. Each is loaded at most once,
+// on first use; a loading failure is fatal, as for the run itself.
+type env struct {
+	configPath  string
+	walletsPath string
+	log         logger.Logger
+
+	cfg          *config.Config
+	wallets      []*keyloader.LoadedKey
+	txLogger     storage.TransactionLogger
+	stateStorage storage.StateStorage
+}
+
+// config loads and validates the configuration file.
+func (e *env) config() *config.Config {
+	if e.cfg != nil {
+		return e.cfg
+	}
+	e.log.Info("Загрузка конфигурации...", "path", e.configPath)
+	cfg, err := config.LoadConfig(e.configPath)
+	if err != nil {
+		if errors.Is(err, config.ErrConfigNotFound) {
+			e.log.Fatal("Файл конфигурации не найден", "path", e.configPath, "error", err)
+		} else if errors.Is(err, config.ErrConfigParseFailed) {
+			e.log.Fatal("Ошибка парсинга файла конфигурации (проверьте YAML синтаксис)",
+				"path", e.configPath, "error", err)
+		} else {
+			e.log.Fatal("Не удалось прочитать файл конфигурации",
+				"path", e.configPath, "error", err)
+		}
+	}
+	e.log.Info("Конфигурация успешно загружена", "max_parallel", cfg.Concurrency.MaxParallelWallets)
+	e.cfg = cfg
+	return cfg
+}
+
+// loadWallets reads the private keys file.
+func (e *env) loadWallets() []*keyloader.LoadedKey {
+	if e.wallets != nil {
+		return e.wallets
+	}
+	e.log.Info("Загрузка приватных ключей...", "path", e.walletsPath)
+	loadedKeys, err := keyloader.LoadKeys(e.walletsPath, e.log)
+	if err != nil {
+		if errors.Is(err, keyloader.ErrWalletsFileNotFound) {
+			e.log.Fatal("Файл ключей не найден", "path", e.walletsPath, "error", err)
+		} else if errors.Is(err, keyloader.ErrNoValidKeysFound) {
+			e.log.Fatal("В файле ключей не найдено валидных ключей",
+				"path", e.walletsPath, "error", err)
+		} else {
+			e.log.Fatal("Не удалось прочитать файл ключей",
+				"path", e.walletsPath, "error", err)
+		}
+	}
+	e.log.Info("Ключи успешно загружены", "count", len(loadedKeys))
+	e.wallets = loadedKeys
+	return loadedKeys
+}
+
+// storage connects to the database configured in the config (or the .env overrides).
+func (e *env) storage(ctx context.Context) (storage.TransactionLogger, storage.StateStorage) {
+	if e.txLogger != nil {
+		return e.txLogger, e.stateStorage
+	}
+	cfg := e.config()
+	txLogger, stateStorage, err := database.NewStorage(
+		ctx,
+		e.log,
+		cfg.Database.Type,
+		cfg.Database.ConnectionString,
+		cfg.Database.PoolMaxConns,
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrUnsupportedDBType) || errors.Is(err, database.ErrMissingConnectionString) {
+			e.log.Fatal("Ошибка конфигурации хранилища данных",
+				"db_type", cfg.Database.Type, "error", err)
+		} else {
+			e.log.Fatal("Не удалось инициализировать хранилище данных",
+				"db_type", cfg.Database.Type, "error", err)
+		}
+	}
+	e.txLogger, e.stateStorage = txLogger, stateStorage
+	return txLogger, stateStorage
+}
+
+// close releases the storage if it was opened.
+func (e *env) close() {
+	if e.txLogger == nil {
+		return
+	}
+	if err := e.txLogger.Close(); err != nil {
+		e.log.Error("Ошибка закрытия хранилища", "error", err)
+	}
+	// Both interfaces are usually implemented by the same store.
+	if any(e.stateStorage) != any(e.txLogger) {
+		if err := e.stateStorage.Close(); err != nil {
+			e.log.Error("Ошибка закрытия хранилища состояния", "error", err)
+		}
+	}
+	e.txLogger = nil
+}
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"retro/internal/app"
+	"retro/internal/evm"
+	"retro/internal/funding"
+	"retro/internal/keyloader"
+	"retro/internal/utils"
+)
+
+// fundCommand tops up the wallets from a master wallet: each wallet gets the shortfall to a random
+// target balance in --min..--max. The plan and its total cost are shown first; transfers are sent
+// in random order with random pauses after the confirmation.
+func fundCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("fund")
+	format := fs.String("format", formatTable, "Output format of the plan: table or csv")
+	network := fs.String("network", "", "Network from rpc_nodes to fund the wallets in")
+	masterPath := fs.String("master", "", "Path to the private keys file of the master wallet")
+	masterIndex := fs.Int("master-index", 0, "Index of the master wallet in the --master file")
+	minFlag := fs.String("min", "", "Minimum target balance in the native coin, e.g. 0.01")
+	maxFlag := fs.String("max", "", "Maximum target balance in the native coin (default: --min)")
+	group := fs.String("group", "", "Fund only the wallets of this wallet group")
+	delayFlag := fs.String("delay", "20s-2m", "Random pause between transfers, e.g. 30s-5m")
+	dryRun := fs.Bool("dry-run", false, "Show the plan without sending transfers")
+	confirmed := fs.Bool("yes", false, "Send without asking for confirmation")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if *network == "" || *masterPath == "" || *minFlag == "" {
+		fmt.Fprintln(os.Stderr, "флаги --network, --master и --min обязательны")
+		return errUsage
+	}
+	if *maxFlag == "" {
+		*maxFlag = *minFlag
+	}
+	target, err := parseFundTarget(*minFlag, *maxFlag)
+	if err != nil {
+		return err
+	}
+	delayMin, delayMax, err := parseDelayRange(*delayFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	if _, ok := cfg.RPCNodes[*network]; !ok {
+		return fmt.Errorf("сеть '%s' не найдена в rpc_nodes", *network)
+	}
+	if *group != "" {
+		if _, ok := cfg.WalletGroups[*group]; !ok {
+			return fmt.Errorf("группа кошельков '%s' не найдена в wallet_groups", *group)
+		}
+	}
+	master, err := loadMaster(*masterPath, *masterIndex, e)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wallets []*keyloader.LoadedKey
+		indexes []int
+	)
+	for i, wallet := range e.loadWallets() {
+		if *group == "" || cfg.WalletInGroup(*group, wallet.Address.Hex(), i) {
+			wallets = append(wallets, wallet)
+			indexes = append(indexes, i)
+		}
+	}
+
+	pool := evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, app.NewNetworkGuard(cfg, e.log), e.log), e.log)
+	defer pool.Close()
+	client, err := pool.Get(ctx, evm.ClientKey{Network: *network})
+	if err != nil {
+		return fmt.Errorf("не удалось подключиться к сети '%s': %w", *network, err)
+	}
+
+	plan, err := funding.BuildPlan(ctx, client, master, wallets, indexes, target)
+	if err != nil {
+		return err
+	}
+	if err := writeFundPlan(*format, plan); err != nil {
+		return err
+	}
+	if len(plan.Transfers) == 0 {
+		e.log.Info("Все кошельки уже имеют целевой баланс, переводы не нужны.", "skipped", plan.Skipped)
+		return nil
+	}
+
+	masterBalance, err := client.GetBalance(ctx, master.Address())
+	if err != nil {
+		return fmt.Errorf("ошибка получения баланса мастер-кошелька: %w", err)
+	}
+	symbol := client.Network().NativeSymbol
+	e.log.Info("План пополнения", "network", *network, "master", master.Address().Hex(),
+		"transfers", len(plan.Transfers), "skipped", plan.Skipped,
+		"amount", utils.FromWei(plan.Amount()), "max_fees", utils.FromWei(plan.Fees()),
+		"total", utils.FromWei(plan.Cost()), "master_balance", utils.FromWei(masterBalance), "symbol", symbol)
+	if masterBalance.Cmp(plan.Cost()) < 0 {
+		costErr := fmt.Errorf("баланс мастер-кошелька %s меньше общей стоимости %s", utils.FromWei(masterBalance), utils.FromWei(plan.Cost()))
+		if *dryRun {
+			e.log.Warn("Пополнение не может быть выполнено", "error", costErr)
+			return nil
+		}
+		return costErr
+	}
+	if *dryRun {
+		e.log.Info("Режим dry-run: переводы не отправлены.")
+		return nil
+	}
+	txLogger, _ := e.storage(ctx)
+	if !*confirmed && !confirmFunding(plan.Cost(), symbol) {
+		e.log.Warn("Пополнение отменено, переводы не отправлены.")
+		return nil
+	}
+
+	sender := &funding.Sender{
+		Client:   client,
+		Master:   master,
+		TxLogger: txLogger,
+		Log:      e.log,
+		Delay: func() time.Duration {
+			return delayMin + time.Duration(rand.Int63n(int64(delayMax-delayMin)+1))
+		},
+	}
+	failed, err := sender.Execute(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("пополнение прервано: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("не выполнено переводов: %d из %d", failed, len(plan.Transfers))
+	}
+	e.log.Success("Пополнение завершено", "transfers", len(plan.Transfers))
+	return nil
+}
+
+// parseFundTarget parses the target balance range in the native coin.
+func parseFundTarget(minValue, maxValue string) (funding.Target, error) {
+	var target funding.Target
+	for _, bound := range []struct {
+		value string
+		dst   **big.Int
+	}{{minValue, &target.Min}, {maxValue, &target.Max}} {
+		wei, err := utils.ToWei(bound.value)
+		if err != nil || wei.Sign() <= 0 {
+			return target, fmt.Errorf("неверный целевой баланс '%s'", bound.value)
+		}
+		*bound.dst = wei
+	}
+	if target.Min.Cmp(target.Max) > 0 {
+		return target, fmt.Errorf("--min (%s) больше --max (%s)", minValue, maxValue)
+	}
+	return target, nil
+}
+
+// parseDelayRange parses a "min-max" pause range, e.g. "30s-5m"; a single value is a fixed pause.
+func parseDelayRange(value string) (time.Duration, time.Duration, error) {
+	minValue, maxValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		maxValue = minValue
+	}
+	delayMin, err := parseWindow(strings.TrimSpace(minValue))
+	if err != nil {
+		return 0, 0, fmt.Errorf("--delay: %w", err)
+	}
+	delayMax, err := parseWindow(strings.TrimSpace(maxValue))
+	if err != nil {
+		return 0, 0, fmt.Errorf("--delay: %w", err)
+	}
+	if delayMin > delayMax {
+		return 0, 0, fmt.Errorf("--delay: минимум больше максимума в '%s'", value)
+	}
+	return delayMin, delayMax, nil
+}
+
+// loadMaster loads the master wallet from its own keys file, kept apart from the farm wallets.
+func loadMaster(path string, index int, e *env) (*evm.Signer, error) {
+	keys, err := keyloader.LoadKeys(path, e.log)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки ключа мастер-кошелька: %w", err)
+	}
+	if index < 0 || index >= len(keys) {
+		return nil, fmt.Errorf("в файле %s нет ключа с индексом %d (ключей: %d)", path, index, len(keys))
+	}
+	return evm.NewSigner(keys[index].PrivateKey), nil
+}
+
+// writeFundPlan prints the planned transfers in the order they will be sent.
+func writeFundPlan(format string, plan *funding.Plan) error {
+	rows := make([][]string, 0, len(plan.Transfers))
+	for _, t := range plan.Transfers {
+		label := t.Wallet.Label
+		if format == formatTable {
+			label = orDash(label)
+		}
+		rows = append(rows, []string{strconv.Itoa(t.Index), t.Wallet.Address.Hex(), label,
+			utils.FromWei(t.Balance), utils.FromWei(t.Target), utils.FromWei(t.Amount), utils.FromWei(t.Fee)})
+	}
+	header := []string{"index", "address", "label", "balance", "target", "amount", "max_fee"}
+	return writeTable(os.Stdout, format, header, rows)
+}
+
+// confirmFunding asks on the terminal to confirm the total cost; anything but "yes" cancels.
+func confirmFunding(cost *big.Int, symbol string) bool {
+	fmt.Fprintf(os.Stderr, "\nБудет списано до %s %s с мастер-кошелька. Введите yes для подтверждения: ", utils.FromWei(cost), symbol)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false
+	}
+	return strings.TrimSpace(answer) == "yes"
+}
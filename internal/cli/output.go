@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats of the data commands.
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	// formatJSON is supported by the commands whose results are exported for further processing.
+	formatJSON = "json"
+)
+
+// checkFormat validates the value of a --format flag.
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatCSV:
+		return nil
+	default:
+		return fmt.Errorf("неизвестный формат '%s' (ожидается '%s' или '%s')", format, formatTable, formatCSV)
+	}
+}
+
+// writeJSON writes value as indented JSON.
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writeTable writes rows as an aligned text table or as CSV. Table headers are upper-cased;
+// CSV headers are kept as given (snake_case).
+func writeTable(w io.Writer, format string, header []string, rows [][]string) error {
+	if format == formatCSV {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	upper := make([]string, len(header))
+	for i, column := range header {
+		upper[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// orDash returns value, or "-" for an empty value in table output.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"retro/internal/storage"
+	"retro/internal/types"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Groupings supported by `report --by`.
+const (
+	reportByTask    = "task"
+	reportByWallet  = "wallet"
+	reportByNetwork = "network"
+)
+
+// reportRow aggregates transaction log records of one group.
+type reportRow struct {
+	group    string
+	network  string
+	statuses map[types.TxStatus]int
+	total    int
+	// broadcasts and fees come from the broadcasts table (transactions actually sent).
+	broadcasts int
+	fees       *big.Int
+	last       time.Time
+}
+
+// reportCommand prints execution counts per task, wallet or network from the transaction log,
+// with the number of sent transactions and the fees paid in the network's native coin.
+func reportCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("report")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	by := fs.String("by", reportByTask, "Group by: task, wallet or network")
+	sinceFlag := fs.String("since", "", "Only records newer than this, e.g. 24h or 7d (default: all)")
+	wallet := fs.String("wallet", "", "Only records of this wallet address")
+	network := fs.String("network", "", "Only records of this network")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	switch *by {
+	case reportByTask, reportByWallet, reportByNetwork:
+	default:
+		return fmt.Errorf("неизвестная группировка '%s' (ожидается task, wallet или network)", *by)
+	}
+	var since time.Time
+	if *sinceFlag != "" {
+		window, err := parseWindow(*sinceFlag)
+		if err != nil {
+			return err
+		}
+		since = time.Now().Add(-window)
+	}
+
+	if *wallet != "" {
+		if !common.IsHexAddress(*wallet) {
+			return fmt.Errorf("неверный адрес кошелька '%s'", *wallet)
+		}
+		*wallet = common.HexToAddress(*wallet).Hex()
+	}
+
+	txLogger, _ := e.storage(ctx)
+	records, err := txLogger.ListTransactions(ctx, storage.TransactionFilter{
+		WalletAddress: *wallet, Network: *network, Since: since,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка чтения журнала транзакций: %w", err)
+	}
+	broadcasts, err := txLogger.ListBroadcasts(ctx, storage.BroadcastFilter{
+		WalletAddress: *wallet, Network: *network, Since: since,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка чтения отправленных транзакций: %w", err)
+	}
+
+	groups := make(map[string]*reportRow)
+	groupOf := func(walletAddress, network string, task types.TaskName) *reportRow {
+		name := string(task)
+		if *by == reportByWallet {
+			name = walletAddress
+		} else if *by == reportByNetwork {
+			name = network
+		}
+		key := name + "|" + network
+		row, ok := groups[key]
+		if !ok {
+			row = &reportRow{group: name, network: network, statuses: make(map[types.TxStatus]int), fees: new(big.Int)}
+			groups[key] = row
+		}
+		return row
+	}
+
+	taskByKey := make(map[string]types.TaskName)
+	for _, record := range records {
+		row := groupOf(record.WalletAddress, record.Network, record.TaskName)
+		row.total++
+		row.statuses[record.Status]++
+		if record.Timestamp.After(row.last) {
+			row.last = record.Timestamp
+		}
+		if record.IdempotencyKey != "" {
+			taskByKey[record.IdempotencyKey] = record.TaskName
+		}
+	}
+	for _, broadcast := range broadcasts {
+		task, ok := taskByKey[broadcast.IdempotencyKey]
+		if !ok && *by == reportByTask {
+			task = "(unknown)"
+		}
+		row := groupOf(broadcast.WalletAddress, broadcast.Network, task)
+		row.broadcasts++
+		if fee, ok := new(big.Int).SetString(broadcast.FeeWei, 10); ok {
+			row.fees.Add(row.fees, fee)
+		}
+	}
+
+	sorted := make([]*reportRow, 0, len(groups))
+	for _, row := range groups {
+		sorted = append(sorted, row)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].group != sorted[j].group {
+			return sorted[i].group < sorted[j].group
+		}
+		return sorted[i].network < sorted[j].network
+	})
+
+	header := []string{*by, "network", "total", "success", "failed", "skipped", "dry_run", "sent_txs", "fees"}
+	if *by == reportByNetwork {
+		header = append(header[:1], header[2:]...)
+	}
+	header = append(header, "last_run")
+	rows := make([][]string, 0, len(sorted))
+	for _, row := range sorted {
+		failed := row.statuses[types.TxStatusFailed] + row.statuses[types.TxStatusErrorBeforeSend]
+		last := ""
+		if !row.last.IsZero() {
+			last = row.last.Local().Format(time.DateTime)
+		}
+		columns := []string{row.group, row.network}
+		if *by == reportByNetwork {
+			columns = columns[:1]
+		}
+		columns = append(columns,
+			strconv.Itoa(row.total),
+			strconv.Itoa(row.statuses[types.TxStatusSuccess]),
+			strconv.Itoa(failed),
+			strconv.Itoa(row.statuses[types.TxStatusSkipped]),
+			strconv.Itoa(row.statuses[types.TxStatusDryRun]),
+			strconv.Itoa(row.broadcasts),
+			utils.FromWei(row.fees),
+		)
+		if *format == formatTable {
+			last = orDash(last)
+		}
+		rows = append(rows, append(columns, last))
+	}
+	return writeTable(os.Stdout, *format, header, rows)
+}
+
+// parseWindow parses a Go duration with an additional day unit ("7d").
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("неверный период '%s'", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return 0, fmt.Errorf("неверный период '%s' (пример: 24h, 7d)", value)
+	}
+	return window, nil
+}
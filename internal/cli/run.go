@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"retro/internal/app"
+	"retro/internal/bootstrap"
+	"retro/internal/control"
+	"retro/internal/logger"
+	"retro/internal/metrics"
+	"retro/internal/notify"
+)
+
+// runCommand processes all wallets: the application's main mode.
+func runCommand(_ context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("run")
+	dryRun := fs.Bool("dry-run", false, "Sign and log transactions without sending them; delays are skipped")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	e.log.Info("Запуск Retro Template...")
+
+	var wg sync.WaitGroup
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := e.config()
+	if *dryRun {
+		cfg.EnableDryRun()
+		e.log.Warn("Режим dry-run: транзакции подписываются и логируются, но не отправляются; задержки и возобновление отключены")
+	}
+
+	if err := metrics.Serve(ctx, cfg.Metrics, e.log); err != nil {
+		return err
+	}
+	var controller *control.Controller
+	if cfg.Control.Enabled {
+		controller = control.New(e.log)
+		if err := control.Serve(ctx, cfg.Control, controller, e.log); err != nil {
+			return err
+		}
+	}
+
+	notifier, err := notify.New(cfg.Notifications, e.log)
+	if err != nil {
+		return err
+	}
+	defer notifier.Close(notifyFlushTimeout)
+
+	txLogger, stateStorage := e.storage(ctx)
+	loadedKeys := e.loadWallets()
+
+	// A dry run neither reads nor writes resume state, so it may run alongside a real one.
+	var runLock io.Closer
+	if !cfg.DryRun {
+		lock, err := app.AcquireRunLock(ctx, stateStorage, cfg.Daemon.RunLockTTL(), e.log)
+		if err != nil {
+			return err
+		}
+		defer lock.Close()
+		runLock = lock
+	}
+
+	bootstrap.RegisterTasksFromConfig(cfg, e.log)
+
+	appInstance := app.NewApplication(cfg, loadedKeys, &wg, txLogger, stateStorage, e.log,
+		app.WithController(controller), app.WithNotifier(notifier))
+
+	// The lock is released before the storage is closed.
+	go gracefulShutdown(cancel, e.log, runLock, txLogger, stateStorage)
+
+	appInstance.Run(ctx)
+
+	select {
+	case <-ctx.Done():
+		e.log.Warn("Контекст был отменен.")
+	default:
+	}
+
+	e.log.Info("Retro Template ожидает завершения операций перед выходом...")
+	wg.Wait()
+	if err := appInstance.Close(); err != nil {
+		e.log.Error("Ошибка освобождения ресурсов приложения", "error", err)
+	}
+	e.log.Info("Retro Template завершил работу.")
+	return nil
+}
+
+// notifyFlushTimeout bounds the wait for queued notifications on exit.
+const notifyFlushTimeout = 15 * time.Second
+
+// gracefulShutdown handles termination signals and cleans up resources.
+func gracefulShutdown(cancel context.CancelFunc, log logger.Logger, closers ...io.Closer) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+
+	sig := <-signalChan
+	log.Warn("Получен сигнал завершения", "signal", sig.String())
+	log.Warn("Инициируется плавная остановка... Отменяем контекст.")
+	cancel()
+
+	log.Info("Graceful shutdown: закрытие ресурсов...")
+
+	for i, closer := range closers {
+		if closer != nil {
+			log.Debug("Closing resource...", "index", i+1)
+			if err := closer.Close(); err != nil {
+				log.Error("Ошибка закрытия ресурса при остановке", "index", i+1, "error", err)
+			} else {
+				log.Debug("Resource closed.", "index", i+1)
+			}
+		}
+	}
+
+	log.Info("Graceful shutdown: сигнал обработан, контекст отменен, ресурсы закрыты.")
+}
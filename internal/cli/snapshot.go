@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"retro/internal/app"
+	"retro/internal/evm"
+	"retro/internal/snapshot"
+	"retro/internal/storage"
+	"retro/internal/types"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// snapshotCommand takes a portfolio snapshot: the native and configured token balances and the
+// nonce of every wallet in every network (or in the networks given by --networks), saved to the database.
+func snapshotCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("snapshot")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	networksFlag := fs.String("networks", "", "Comma-separated networks from rpc_nodes (default: all)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	wallets := e.loadWallets()
+	networks, err := selectNetworks(cfg.RPCNodes, *networksFlag)
+	if err != nil {
+		return err
+	}
+	if cfg.Database.Type == types.None || cfg.Database.Type == "" {
+		e.log.Warn("Хранилище отключено (database.type: none), снимок не будет сохранен")
+	}
+	txLogger, _ := e.storage(ctx)
+
+	pool := evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, app.NewNetworkGuard(cfg, e.log), e.log), e.log)
+	defer pool.Close()
+
+	addresses := make([]common.Address, len(wallets))
+	for i, wallet := range wallets {
+		addresses[i] = wallet.Address
+	}
+	at := time.Now().UTC()
+	snapshotID := snapshot.NewID(at)
+	records, err := snapshot.NewCollector(pool, cfg.Balances.Tokens, e.log).Collect(ctx, snapshotID, at, addresses, networks)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("не удалось получить ни одного баланса, снимок не сохранен")
+	}
+	if err := txLogger.SaveSnapshot(ctx, records); err != nil {
+		return fmt.Errorf("ошибка сохранения снимка: %w", err)
+	}
+	e.log.Success("Снимок сохранен", "snapshot_id", snapshotID, "records", len(records))
+
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		balance, token := record.Balance, record.TokenAddress
+		if *format == formatTable {
+			if amount, ok := new(big.Int).SetString(record.Balance, 10); ok {
+				balance = utils.FromUnits(amount, record.Decimals)
+			}
+			token = orDash(token)
+		}
+		rows = append(rows, []string{record.WalletAddress, record.Network, record.Asset, token, balance,
+			strconv.FormatUint(record.Nonce, 10)})
+	}
+	return writeTable(os.Stdout, *format, []string{"wallet", "network", "asset", "token", "balance", "nonce"}, rows)
+}
+
+// snapshotListCommand prints the stored snapshots, newest first.
+func snapshotListCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("snapshot list")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	txLogger, _ := e.storage(ctx)
+	summaries, err := txLogger.ListSnapshots(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения снимков: %w", err)
+	}
+	rows := make([][]string, 0, len(summaries))
+	for _, summary := range summaries {
+		rows = append(rows, []string{summary.SnapshotID, summary.Timestamp.Local().Format(time.DateTime),
+			strconv.Itoa(summary.Wallets), strconv.Itoa(summary.Networks), strconv.Itoa(summary.Records)})
+	}
+	return writeTable(os.Stdout, *format, []string{"snapshot_id", "time", "wallets", "networks", "records"}, rows)
+}
+
+// snapshotDiffCommand prints the balance changes between two snapshots (by default the two latest;
+// with one argument, between it and the latest) and the gas spent in each network in between.
+func snapshotDiffCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("snapshot diff")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	all := fs.Bool("all", false, "Also show unchanged balances")
+	ids, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if len(ids) > 2 {
+		e.log.Error("Ожидается не более двух идентификаторов снимков", "args", ids)
+		return errUsage
+	}
+
+	txLogger, _ := e.storage(ctx)
+	fromID, toID, err := resolveSnapshotPair(ctx, txLogger, ids)
+	if err != nil {
+		return err
+	}
+	from, err := loadSnapshot(ctx, txLogger, fromID)
+	if err != nil {
+		return err
+	}
+	to, err := loadSnapshot(ctx, txLogger, toID)
+	if err != nil {
+		return err
+	}
+	since, until := from[0].Timestamp, to[0].Timestamp
+	if until.Before(since) {
+		since, until = until, since
+	}
+	// Broadcast timestamps are stored with second precision.
+	since = since.Truncate(time.Second)
+	broadcasts, err := txLogger.ListBroadcasts(ctx, storage.BroadcastFilter{Since: since, Until: until})
+	if err != nil {
+		return fmt.Errorf("ошибка чтения отправленных транзакций: %w", err)
+	}
+	e.log.Info("Сравнение снимков", "from", fromID, "to", toID)
+
+	var rows [][]string
+	for _, change := range snapshot.Diff(from, to) {
+		delta := change.Delta()
+		txs, gas := "", ""
+		if change.IsNative() {
+			if sent, ok := change.Sent(); ok {
+				txs = strconv.FormatUint(sent, 10)
+			}
+			estimate := snapshot.EstimateGas(change, broadcasts, since, until)
+			if estimate.Fees != nil {
+				gas = utils.FromWei(estimate.Fees)
+				if estimate.Extrapolated {
+					gas = "~" + gas
+				}
+			}
+			if !*all && delta.Sign() == 0 && txs == "0" {
+				continue
+			}
+		} else if !*all && delta.Sign() == 0 && change.From != nil && change.To != nil {
+			continue
+		}
+
+		fromBalance := amountText(change.From, change.Decimals, *format)
+		toBalance := amountText(change.To, change.Decimals, *format)
+		deltaText := amountText(delta, change.Decimals, *format)
+		if *format == formatTable {
+			if delta.Sign() > 0 {
+				deltaText = "+" + deltaText
+			}
+			txs, gas = orDash(txs), orDash(gas)
+		}
+		rows = append(rows, []string{change.WalletAddress, change.Network, change.Asset, fromBalance, toBalance, deltaText, txs, gas})
+	}
+	return writeTable(os.Stdout, *format,
+		[]string{"wallet", "network", "asset", "from", "to", "change", "txs", "gas_spent"}, rows)
+}
+
+// resolveSnapshotPair returns the snapshot IDs to compare, defaulting to the latest snapshots.
+func resolveSnapshotPair(ctx context.Context, txLogger storage.TransactionLogger, ids []string) (string, string, error) {
+	if len(ids) == 2 {
+		return ids[0], ids[1], nil
+	}
+	summaries, err := txLogger.ListSnapshots(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("ошибка чтения снимков: %w", err)
+	}
+	if len(ids) == 1 {
+		if len(summaries) == 0 {
+			return "", "", fmt.Errorf("сохраненных снимков нет")
+		}
+		return ids[0], summaries[0].SnapshotID, nil
+	}
+	if len(summaries) < 2 {
+		return "", "", fmt.Errorf("для сравнения нужно не менее двух снимков, сохранено: %d", len(summaries))
+	}
+	return summaries[1].SnapshotID, summaries[0].SnapshotID, nil
+}
+
+// loadSnapshot reads the records of a snapshot; a missing snapshot is an error.
+func loadSnapshot(ctx context.Context, txLogger storage.TransactionLogger, snapshotID string) ([]storage.SnapshotRecord, error) {
+	records, err := txLogger.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения снимка '%s': %w", snapshotID, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("снимок '%s' не найден", snapshotID)
+	}
+	return records, nil
+}
+
+// amountText formats an amount in the asset's units for tables and raw units for CSV;
+// a missing amount is "-" in tables and empty in CSV.
+func amountText(amount *big.Int, decimals uint8, format string) string {
+	if amount == nil {
+		if format == formatTable {
+			return "-"
+		}
+		return ""
+	}
+	if format == formatCSV {
+		return amount.String()
+	}
+	return utils.FromUnits(amount, decimals)
+}
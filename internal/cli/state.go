@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// stateShowCommand prints the saved application state (resume index, current run ID, ...).
+func stateShowCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("state show")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	_, stateStorage := e.storage(ctx)
+	state, err := stateStorage.ListState(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения состояния: %w", err)
+	}
+
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	rows := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		rows = append(rows, []string{key, state[key]})
+	}
+	return writeTable(os.Stdout, *format, []string{"key", "value"}, rows)
+}
+
+// stateResetCommand deletes all saved state, or only the keys given as arguments,
+// so the next run starts from the first wallet with a new run ID.
+func stateResetCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("state reset")
+	confirmed := fs.Bool("yes", false, "Confirm the deletion")
+	keys, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	_, stateStorage := e.storage(ctx)
+	if len(keys) == 0 {
+		state, err := stateStorage.ListState(ctx)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения состояния: %w", err)
+		}
+		for key := range state {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	if len(keys) == 0 {
+		e.log.Info("Сохраненное состояние пусто, удалять нечего.")
+		return nil
+	}
+	if !*confirmed {
+		e.log.Warn("Будут удалены ключи состояния; повторите команду с --yes для подтверждения", "keys", keys)
+		return errUsage
+	}
+
+	for _, key := range keys {
+		if err := stateStorage.DeleteState(ctx, key); err != nil {
+			return fmt.Errorf("ошибка удаления ключа состояния '%s': %w", key, err)
+		}
+		e.log.Success("Ключ состояния удален", "key", key)
+	}
+	return nil
+}
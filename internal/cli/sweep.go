@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"retro/internal/app"
+	"retro/internal/bootstrap"
+	"retro/internal/config"
+	"retro/internal/evm"
+	"retro/internal/keyloader"
+	"retro/internal/runctx"
+	"retro/internal/snapshot"
+	"retro/internal/storage"
+	"retro/internal/tasks/sweep"
+	"retro/internal/types"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// sweepTotal is the amount of one asset collected in one network.
+type sweepTotal struct {
+	amount  *big.Int
+	wallets int
+}
+
+// sweepCommand collects the listed tokens and the native balance of every wallet at the collector
+// address. The wallets are processed by the application like a run, with the configured
+// concurrency and delays; the collected totals per network are printed at the end.
+func sweepCommand(ctx context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("sweep")
+	format := fs.String("format", formatTable, "Output format of the totals: table or csv")
+	toFlag := fs.String("to", "", "Collector address (default: sweep.to)")
+	networksFlag := fs.String("networks", "", "Comma-separated networks (default: sweep.networks, or all of rpc_nodes)")
+	group := fs.String("group", "", "Sweep only the wallets of this wallet group")
+	dryRun := fs.Bool("dry-run", false, "Sign and log the transfers without sending them")
+	confirmed := fs.Bool("yes", false, "Confirm the transfers")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	to := *toFlag
+	if to == "" {
+		to = cfg.Sweep.To
+	}
+	if !common.IsHexAddress(to) {
+		return fmt.Errorf("не задан или неверен адрес сбора (--to или sweep.to): '%s'", to)
+	}
+	networks := cfg.Sweep.DefaultNetworks(cfg)
+	if *networksFlag != "" {
+		var err error
+		if networks, err = selectNetworks(cfg.RPCNodes, *networksFlag); err != nil {
+			return err
+		}
+	}
+	if *group != "" {
+		if _, ok := cfg.WalletGroups[*group]; !ok {
+			return fmt.Errorf("группа кошельков '%s' не найдена в wallet_groups", *group)
+		}
+	}
+	if !*dryRun && !*confirmed {
+		e.log.Warn("Средства кошельков будут отправлены на адрес сбора; проверьте план с --dry-run и повторите команду с --yes",
+			"to", common.HexToAddress(to).Hex(), "networks", networks)
+		return errUsage
+	}
+
+	sweepCfg := cfg.ForSweep(common.HexToAddress(to).Hex(), networks)
+	if *dryRun {
+		sweepCfg.EnableDryRun()
+		e.log.Warn("Режим dry-run: транзакции подписываются и логируются, но не отправляются")
+	}
+
+	txLogger, stateStorage := e.storage(ctx)
+	wallets := e.loadWallets()
+	if !*dryRun {
+		lock, err := app.AcquireRunLock(ctx, stateStorage, cfg.Daemon.RunLockTTL(), e.log)
+		if err != nil {
+			return err
+		}
+		defer lock.Close()
+	}
+
+	bootstrap.RegisterTasksFromConfig(sweepCfg, e.log)
+	var opts []app.Option
+	if *group != "" {
+		opts = append(opts, app.WithWalletFilter(func(index int, key *keyloader.LoadedKey) bool {
+			return cfg.WalletInGroup(*group, key.Address.Hex(), index)
+		}))
+	}
+
+	started := time.Now().Truncate(time.Second)
+	var wg sync.WaitGroup
+	// The sweep keeps its own state, apart from the resume state of regular runs.
+	state := storage.WithKeyPrefix(stateStorage, "sweep.")
+	application := app.NewApplication(sweepCfg, wallets, &wg, txLogger, state, e.log, opts...)
+	application.Run(ctx)
+	wg.Wait()
+	if err := application.Close(); err != nil {
+		e.log.Error("Ошибка освобождения ресурсов приложения", "error", err)
+	}
+
+	status := types.TxStatusSuccess
+	if *dryRun {
+		status = types.TxStatusDryRun
+	}
+	// The totals are read after an interruption as well, so they use a fresh context.
+	totalsCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	records, err := txLogger.ListTransactions(totalsCtx, storage.TransactionFilter{
+		TaskName: types.TaskNameSweep,
+		Status:   status,
+		Since:    started,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка чтения журнала транзакций: %w", err)
+	}
+	return writeSweepTotals(totalsCtx, os.Stdout, *format, sweepCfg, networks, sweepTotals(records, e), e)
+}
+
+// sweepTotals sums the amounts recorded in the step outputs of the sweep records by network and
+// asset ("native" or the token address).
+func sweepTotals(records []storage.TransactionRecord, e *env) map[string]map[string]*sweepTotal {
+	totals := make(map[string]map[string]*sweepTotal)
+	for _, record := range records {
+		if record.Outputs == "" {
+			continue
+		}
+		var outputs map[string]runctx.Value
+		if err := json.Unmarshal([]byte(record.Outputs), &outputs); err != nil {
+			e.log.Warn("Не удалось разобрать выходные данные записи sweep", "wallet", record.WalletAddress, "error", err)
+			continue
+		}
+		if totals[record.Network] == nil {
+			totals[record.Network] = make(map[string]*sweepTotal)
+		}
+		for asset, value := range outputs {
+			amount, ok := new(big.Int).SetString(value.Value, 10)
+			if !ok {
+				continue
+			}
+			total := totals[record.Network][asset]
+			if total == nil {
+				total = &sweepTotal{amount: new(big.Int)}
+				totals[record.Network][asset] = total
+			}
+			total.amount.Add(total.amount, amount)
+			total.wallets++
+		}
+	}
+	return totals
+}
+
+// writeSweepTotals prints the collected amount of every asset in every network, formatted with
+// the token's decimals.
+func writeSweepTotals(ctx context.Context, w io.Writer, format string, cfg *config.Config, networks []string,
+	totals map[string]map[string]*sweepTotal, e *env) error {
+	pool := evm.NewClientPool(evm.NewRPCDialer(cfg.RPCNodes, app.NewNetworkGuard(cfg, e.log), e.log), e.log)
+	defer pool.Close()
+
+	var rows [][]string
+	for _, network := range networks {
+		if len(totals[network]) == 0 {
+			rows = append(rows, []string{network, "", "", "0", "0", "0"})
+			continue
+		}
+		var tokens []string
+		for _, token := range cfg.Sweep.Networks[network].Tokens {
+			tokens = append(tokens, token.Address)
+		}
+		var assets []snapshot.Asset
+		if client, err := pool.Get(ctx, evm.ClientKey{Network: network}); err != nil {
+			e.log.Warn("Не удалось подключиться к сети, суммы выводятся без форматирования", "network", network, "error", err)
+		} else {
+			assets = snapshot.NetworkAssets(ctx, client, tokens, e.log)
+		}
+
+		for _, asset := range assets {
+			key := sweep.OutputNative
+			if !asset.IsNative() {
+				key = asset.Token.Hex()
+			}
+			if total := totals[network][key]; total != nil {
+				rows = append(rows, []string{network, asset.Symbol, asset.TokenHex(), strconv.Itoa(total.wallets),
+					utils.FromUnits(total.amount, asset.Decimals), total.amount.String()})
+				delete(totals[network], key)
+			}
+		}
+		// Assets whose metadata could not be read are shown with raw amounts only.
+		for key, total := range totals[network] {
+			token := key
+			if key == sweep.OutputNative {
+				token = ""
+			}
+			rows = append(rows, []string{network, key, token, strconv.Itoa(total.wallets), total.amount.String(), total.amount.String()})
+		}
+	}
+
+	header := []string{"network", "asset", "token", "wallets", "amount", "amount_raw"}
+	if format == formatTable {
+		header = header[:len(header)-1]
+		for i, row := range rows {
+			row[1], row[2] = orDash(row[1]), orDash(row[2])
+			rows[i] = row[:len(row)-1]
+		}
+	}
+	return writeTable(w, format, header, rows)
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"retro/internal/bootstrap"
+	"retro/internal/logger"
+	"retro/internal/tasks"
+	"retro/internal/types"
+)
+
+// tasksListCommand prints the known tasks, whether the config enables them and their parameter schemas.
+func tasksListCommand(_ context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("tasks list")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+
+	// Built-in tasks plus anything registered in the task registry by other means.
+	constructors := bootstrap.KnownTasks()
+	for _, name := range tasks.ListTasks() {
+		if _, ok := constructors[name]; !ok {
+			constructors[name] = func(log logger.Logger) tasks.TaskRunner {
+				runner, _ := tasks.NewTask(name, log)
+				return runner
+			}
+		}
+	}
+
+	// Networks of the config entries per task; tasks only present in the config are listed too.
+	enabledNetworks := make(map[types.TaskName][]string)
+	configured := make(map[types.TaskName]bool)
+	for _, entry := range cfg.Tasks {
+		configured[entry.Name] = true
+		if entry.Enabled {
+			enabledNetworks[entry.Name] = append(enabledNetworks[entry.Name], entry.Network)
+		}
+	}
+
+	names := make([]types.TaskName, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	for name := range configured {
+		if _, ok := constructors[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	var rows [][]string
+	for _, name := range names {
+		status := "disabled"
+		switch {
+		case constructors[name] == nil:
+			status = "unknown"
+		case len(enabledNetworks[name]) > 0:
+			status = "enabled"
+		}
+		taskColumns := []string{string(name), status, strings.Join(enabledNetworks[name], ",")}
+
+		var schema []tasks.ParamSpec
+		if constructor := constructors[name]; constructor != nil {
+			schema = tasks.ParamSchemaOf(constructor(e.log))
+		}
+		if len(schema) == 0 {
+			rows = append(rows, append(taskColumns, "", "", "", ""))
+		}
+		for i, param := range schema {
+			columns := taskColumns
+			if i > 0 && *format == formatTable {
+				columns = []string{"", "", ""}
+			}
+			required := "no"
+			if param.Required {
+				required = "yes"
+			}
+			rows = append(rows, append(append([]string{}, columns...), param.Name, param.Type, required, param.Description))
+		}
+	}
+	if *format == formatTable {
+		for i, row := range rows {
+			if row[0] != "" {
+				rows[i][2] = orDash(row[2])
+				rows[i][3] = orDash(row[3])
+			}
+		}
+	}
+	return writeTable(os.Stdout, *format,
+		[]string{"task", "status", "networks", "param", "type", "required", "description"}, rows)
+}
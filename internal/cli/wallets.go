@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// walletsListCommand prints the wallets from the keys file with their labels and groups.
+func walletsListCommand(_ context.Context, e *env, args []string) error {
+	fs := e.newFlagSet("wallets list")
+	format := fs.String("format", formatTable, "Output format: table or csv")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg := e.config()
+	wallets := e.loadWallets()
+
+	rows := make([][]string, 0, len(wallets))
+	for i, wallet := range wallets {
+		groups := strings.Join(cfg.GroupsOf(wallet.Address.Hex(), i), ",")
+		if *format == formatTable {
+			rows = append(rows, []string{strconv.Itoa(i), wallet.Address.Hex(), orDash(wallet.Label), orDash(groups)})
+		} else {
+			rows = append(rows, []string{strconv.Itoa(i), wallet.Address.Hex(), wallet.Label, groups})
+		}
+	}
+	return writeTable(os.Stdout, *format, []string{"index", "address", "label", "groups"}, rows)
+}
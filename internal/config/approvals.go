@@ -0,0 +1,36 @@
+package config
+
+import "fmt"
+
+// ApprovalsConfig lists the token and spender pairs checked by the approvals command.
+type ApprovalsConfig struct {
+	// Pairs maps a network from rpc_nodes to its pairs.
+	Pairs map[string][]ApprovalPair `yaml:"pairs,omitempty"`
+}
+
+// ApprovalPair is a token and a spender (router, bridge, ...) that may hold an allowance.
+type ApprovalPair struct {
+	Token   string `yaml:"token"`
+	Spender string `yaml:"spender"`
+	// Name describes the spender in the output.
+	Name string `yaml:"name,omitempty"`
+}
+
+// validate checks the networks and the addresses of the pairs.
+func (a ApprovalsConfig) validate(c *Config) error {
+	for network, pairs := range a.Pairs {
+		path := "approvals.pairs." + network
+		if _, ok := c.RPCNodes[network]; !ok {
+			return fmt.Errorf("%s: network is not defined in rpc_nodes", path)
+		}
+		for i, pair := range pairs {
+			if !isAddress(pair.Token) {
+				return fmt.Errorf("%s[%d]: invalid token address '%s'", path, i, pair.Token)
+			}
+			if !isAddress(pair.Spender) {
+				return fmt.Errorf("%s[%d]: invalid spender address '%s'", path, i, pair.Spender)
+			}
+		}
+	}
+	return nil
+}
@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -29,6 +33,41 @@ type TaskConfigEntry struct {
 	Network string                 `yaml:"network"`
 	Enabled bool                   `yaml:"enabled"`
 	Params  map[string]interface{} `yaml:"params"`
+	// Weight is the relative probability of picking the task in random mode; nil means 1.
+	Weight *float64 `yaml:"weight,omitempty"`
+	// ID identifies the step for depends_on and run_if references (defaults to the task name).
+	ID        string       `yaml:"id,omitempty"`
+	DependsOn []string     `yaml:"depends_on,omitempty"`
+	RunIf     *RunIfConfig `yaml:"run_if,omitempty"`
+	// Cooldown is the minimal time between two successful executions of the task by one wallet.
+	Cooldown *Duration `yaml:"cooldown,omitempty"`
+	// MaxExecutions caps the lifetime number of successful executions per wallet (0 = no cap).
+	MaxExecutions int `yaml:"max_executions,omitempty"`
+}
+
+// StepID returns the identifier used to reference this task from other steps.
+func (t TaskConfigEntry) StepID() string {
+	if t.ID != "" {
+		return t.ID
+	}
+	return string(t.Name)
+}
+
+// RunIfConfig holds conditions that must all hold for a step to be executed.
+type RunIfConfig struct {
+	// MinNativeBalance is the minimal native balance (in ether units) on the task network.
+	MinNativeBalance string                 `yaml:"min_native_balance,omitempty"`
+	MinTokenBalance  *TokenBalanceCondition `yaml:"min_token_balance,omitempty"`
+	// StepStatus maps step IDs to the status they must have reached earlier in the run.
+	StepStatus map[string]types.StepStatus `yaml:"step_status,omitempty"`
+	// WalletGroups restricts the step to wallets belonging to at least one of the groups.
+	WalletGroups []string `yaml:"wallet_groups,omitempty"`
+}
+
+// TokenBalanceCondition requires a minimal ERC-20 balance, given in token units (decimals applied).
+type TokenBalanceCondition struct {
+	Token string `yaml:"token"`
+	Min   string `yaml:"min"`
 }
 
 // StateConfig holds configuration related to application state persistence.
@@ -38,15 +77,201 @@ type StateConfig struct {
 
 // Config corresponds to the structure of config.yml
 type Config struct {
-	LogFilePath string              `yaml:"log_file_path,omitempty"`
-	RPCNodes    map[string][]string `yaml:"rpc_nodes"`
-	Concurrency ConcurrencyConfig   `yaml:"concurrency"`
-	Wallets     WalletsConfig       `yaml:"wallets"`
-	Delay       DelayConfig         `yaml:"delay"`
-	Actions     ActionsConfig       `yaml:"actions"`
-	Tasks       []TaskConfigEntry   `yaml:"tasks"`
-	Database    DatabaseConfig      `yaml:"database"`
-	State       StateConfig         `yaml:"state"`
+	LogFilePath string                   `yaml:"log_file_path,omitempty"`
+	RPCNodes    map[string]NetworkConfig `yaml:"rpc_nodes"`
+	Concurrency ConcurrencyConfig        `yaml:"concurrency"`
+	Wallets     WalletsConfig            `yaml:"wallets"`
+	Delay       DelayConfig              `yaml:"delay"`
+	Actions     ActionsConfig            `yaml:"actions"`
+	Tasks       []TaskConfigEntry        `yaml:"tasks"`
+	Database    DatabaseConfig           `yaml:"database"`
+	State       StateConfig              `yaml:"state"`
+	RPC         RPCConfig                `yaml:"rpc,omitempty"`
+	// WalletGroups maps a group name to its members: addresses, wallet indexes ("3") or index ranges ("0-9").
+	WalletGroups map[string][]string `yaml:"wallet_groups,omitempty"`
+	Balances     BalancesConfig      `yaml:"balances,omitempty"`
+	Activity     ActivityConfig      `yaml:"activity,omitempty"`
+	Schedule     ScheduleConfig      `yaml:"schedule,omitempty"`
+	Daemon       DaemonConfig        `yaml:"daemon,omitempty"`
+	Metrics      MetricsConfig       `yaml:"metrics,omitempty"`
+	Control      ControlConfig       `yaml:"control,omitempty"`
+	// Notifications are sent about run events; none are sent without sinks.
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+	Sweep         SweepConfig         `yaml:"sweep,omitempty"`
+	Approvals     ApprovalsConfig     `yaml:"approvals,omitempty"`
+	// DryRun is set by the --dry-run flag: transactions are signed and logged but never sent.
+	DryRun bool `yaml:"-"`
+}
+
+// EnableDryRun switches the config to dry-run mode: all delays are skipped and resume state
+// is neither read nor written, so a dry run never affects a later real run.
+func (c *Config) EnableDryRun() {
+	c.DryRun = true
+	c.Delay.BetweenAccounts = DelayRange{Unit: c.Delay.BetweenAccounts.Unit}
+	c.Delay.BetweenActions = DelayRange{Unit: c.Delay.BetweenActions.Unit}
+	c.Delay.AfterError = DelayRange{Unit: c.Delay.AfterError.Unit}
+	c.Delay.BetweenRetries.Delay = DelayRange{Unit: c.Delay.BetweenRetries.Delay.Unit}
+	c.State.ResumeEnabled = false
+}
+
+// NetworkConfig describes a network from rpc_nodes. A plain list of URLs is accepted as well.
+type NetworkConfig struct {
+	URLs []string `yaml:"urls"`
+	// ChainID is the expected eth_chainId of every endpoint (0 = not checked).
+	ChainID      int64  `yaml:"chain_id,omitempty"`
+	NativeSymbol string `yaml:"native_symbol,omitempty"`
+	// ExplorerURL is the block explorer base URL used for transaction links in logs.
+	ExplorerURL string `yaml:"explorer_url,omitempty"`
+	// MaxGasPriceGwei caps the gas price (max fee per gas) of sent transactions (0 = no cap).
+	MaxGasPriceGwei float64 `yaml:"max_gas_price_gwei,omitempty"`
+	// MaxGasLimit caps the gas limit of sent transactions (0 = no cap).
+	MaxGasLimit uint64 `yaml:"max_gas_limit,omitempty"`
+	// LogsBlockRange is the largest block range of one eth_getLogs request (0 = the default).
+	LogsBlockRange uint64 `yaml:"logs_block_range,omitempty"`
+}
+
+// UnmarshalYAML accepts both the struct form and the legacy plain list of URLs.
+func (n *NetworkConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		return value.Decode(&n.URLs)
+	}
+	type plain NetworkConfig
+	return value.Decode((*plain)(n))
+}
+
+// BalancesConfig configures the `balances` and `snapshot` commands.
+type BalancesConfig struct {
+	// Tokens maps a network name from rpc_nodes to the ERC-20 token addresses to report.
+	Tokens map[string][]string `yaml:"tokens,omitempty"`
+}
+
+// ScheduleConfig spreads the wallets of a run over a time window: every wallet gets a random
+// start time within the allowed hours, and the plan is kept in the state storage across restarts.
+type ScheduleConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Window is how far ahead of the run start the wallets are spread.
+	Window Duration `yaml:"window"`
+	// DailyStart and DailyEnd limit start times to these hours ("08:00", "23:00"); empty = all day.
+	// An end before the start means a window crossing midnight.
+	DailyStart string `yaml:"daily_start,omitempty"`
+	DailyEnd   string `yaml:"daily_end,omitempty"`
+	// Timezone is an IANA time zone name for the daily hours (empty = local time).
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+// DailyHours returns the start and end of the allowed hours as offsets from midnight;
+// the end may be past midnight (more than 24h).
+func (s ScheduleConfig) DailyHours() (time.Duration, time.Duration, error) {
+	start, err := parseClock(s.DailyStart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("daily_start: %w", err)
+	}
+	end, err := parseClock(s.DailyEnd)
+	if err != nil {
+		return 0, 0, fmt.Errorf("daily_end: %w", err)
+	}
+	if s.DailyEnd == "" || end <= start {
+		// An empty end is the end of the day; an end before the start is on the next day.
+		end += 24 * time.Hour
+	}
+	return start, end, nil
+}
+
+// Location returns the time zone of the daily hours.
+func (s ScheduleConfig) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// parseClock parses a "HH:MM" time of day; empty is midnight.
+func parseClock(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s' (expected HH:MM)", value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// ActivityConfig configures the `activity` command.
+type ActivityConfig struct {
+	// Targets are the thresholds every wallet should reach in every network.
+	Targets ActivityTargets `yaml:"targets,omitempty"`
+	// NetworkTargets maps a network name from rpc_nodes to thresholds used there instead of Targets.
+	NetworkTargets map[string]ActivityTargets `yaml:"network_targets,omitempty"`
+}
+
+// ActivityTargets are minimum activity values of a wallet in a network (0 or empty = not checked).
+type ActivityTargets struct {
+	TxCount           int `yaml:"tx_count,omitempty"`
+	ActiveDays        int `yaml:"active_days,omitempty"`
+	ActiveWeeks       int `yaml:"active_weeks,omitempty"`
+	ActiveMonths      int `yaml:"active_months,omitempty"`
+	DistinctTasks     int `yaml:"distinct_tasks,omitempty"`
+	DistinctContracts int `yaml:"distinct_contracts,omitempty"`
+	// MinVolume is the native coin amount sent with transactions, e.g. "0.5".
+	MinVolume string `yaml:"min_volume,omitempty"`
+}
+
+// ActivityTargetsFor returns the activity thresholds of the network.
+func (c *Config) ActivityTargetsFor(network string) ActivityTargets {
+	if targets, ok := c.Activity.NetworkTargets[network]; ok {
+		return targets
+	}
+	return c.Activity.Targets
+}
+
+// validate checks that thresholds are not negative and the volume is a decimal number.
+func (t ActivityTargets) validate(path string) error {
+	thresholds := []struct {
+		name  string
+		value int
+	}{
+		{"tx_count", t.TxCount}, {"active_days", t.ActiveDays}, {"active_weeks", t.ActiveWeeks},
+		{"active_months", t.ActiveMonths}, {"distinct_tasks", t.DistinctTasks}, {"distinct_contracts", t.DistinctContracts},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value < 0 {
+			return fmt.Errorf("%s.%s must not be negative, got %d", path, threshold.name, threshold.value)
+		}
+	}
+	if t.MinVolume != "" {
+		if volume, err := strconv.ParseFloat(t.MinVolume, 64); err != nil || volume < 0 {
+			return fmt.Errorf("%s.min_volume must be a non-negative decimal amount, got '%s'", path, t.MinVolume)
+		}
+	}
+	return nil
+}
+
+// RPCConfig holds settings shared by all RPC clients.
+type RPCConfig struct {
+	RateLimit      RateLimitConfig      `yaml:"rate_limit,omitempty"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker,omitempty"`
+}
+
+// RateLimitConfig limits RPC requests per second across all wallets (0 = unlimited).
+type RateLimitConfig struct {
+	// DefaultRPS applies to every network without its own entry in Networks.
+	DefaultRPS float64 `yaml:"default_rps"`
+	// Networks maps a network name from rpc_nodes to its limit.
+	Networks map[string]float64 `yaml:"networks,omitempty"`
+	// Endpoints maps an RPC URL to its limit, applied in addition to the network limit.
+	Endpoints map[string]float64 `yaml:"endpoints,omitempty"`
+}
+
+// CircuitBreakerConfig pauses a network's tasks after repeated RPC failures.
+type CircuitBreakerConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// FailureThreshold is the number of consecutive failed requests (429, 5xx, network errors) that opens the circuit.
+	FailureThreshold int `yaml:"failure_threshold"`
+	// Cooldown is how long the network stays paused.
+	Cooldown Duration `yaml:"cooldown"`
+	// MaxWaits caps how many times one wallet waits for the same step's network before failing it (0 = default 3).
+	MaxWaits int `yaml:"max_waits,omitempty"`
 }
 
 // ConcurrencyConfig holds settings related to parallel execution
@@ -72,6 +297,35 @@ type ActionsConfig struct {
 	ActionsPerAccount    MinMax          `yaml:"actions_per_account"`
 	TaskOrder            types.TaskOrder `yaml:"task_order"`
 	ExplicitTaskSequence []string        `yaml:"explicit_task_sequence"`
+	// SelectionMode controls whether a task can be picked several times per wallet.
+	SelectionMode types.SelectionMode `yaml:"selection_mode,omitempty"`
+	// MaxRepeatsPerTask caps how many times one task entry is picked per wallet per run (0 = no cap).
+	MaxRepeatsPerTask int           `yaml:"max_repeats_per_task,omitempty"`
+	History           HistoryConfig `yaml:"history,omitempty"`
+}
+
+// HistoryConfig controls how the wallet's past transactions influence random task selection.
+type HistoryConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Lookback is the window after which a past execution no longer lowers the task's weight.
+	Lookback Duration `yaml:"lookback"`
+	// NoveltyBoost is the extra weight share given to tasks not executed within the lookback window.
+	NoveltyBoost float64 `yaml:"novelty_boost"`
+}
+
+// Duration represents a fixed time span with units.
+type Duration struct {
+	Value int            `yaml:"value"`
+	Unit  types.TimeUnit `yaml:"unit"`
+}
+
+// Duration converts the configured value to time.Duration.
+func (d Duration) Duration() (time.Duration, error) {
+	unit, err := d.Unit.Duration()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(d.Value) * unit, nil
 }
 
 // DelayRange represents a min/max delay with units
@@ -83,8 +337,19 @@ type DelayRange struct {
 
 // RetryDelay includes the delay range and number of attempts for retries
 type RetryDelay struct {
-	Delay    DelayRange `yaml:"delay"`
-	Attempts int        `yaml:"attempts"`
+	Delay    DelayRange    `yaml:"delay"`
+	Attempts int           `yaml:"attempts"`
+	Backoff  BackoffConfig `yaml:"backoff,omitempty"`
+}
+
+// BackoffConfig makes the retry delay grow exponentially with each attempt.
+type BackoffConfig struct {
+	// Multiplier is applied to the delay after every failed attempt (<= 1 disables growth).
+	Multiplier float64 `yaml:"multiplier"`
+	// MaxDelay caps the grown delay (zero value = no cap).
+	MaxDelay Duration `yaml:"max_delay"`
+	// Jitter randomizes the delay by up to this fraction in both directions (0..1).
+	Jitter float64 `yaml:"jitter"`
 }
 
 // MinMax represents a min/max integer range
@@ -122,13 +387,325 @@ func LoadConfig(path string) (*Config, error) {
 	if dbPoolMax := os.Getenv("DB_POOL_MAX_CONNS"); dbPoolMax != "" {
 		cfg.Database.PoolMaxConns = dbPoolMax
 	}
+	if controlToken := os.Getenv("CONTROL_API_TOKEN"); controlToken != "" {
+		cfg.Control.Token = controlToken
+	}
 
 	return &cfg, nil
 }
 
 // Validate performs basic validation on the loaded configuration.
 func (c *Config) Validate() error {
-	// ... (существующий код Validate)
-	// Добавить валидацию для State, если нужно
+	for i, task := range c.Tasks {
+		if task.Weight != nil && *task.Weight <= 0 {
+			return fmt.Errorf("tasks[%d] (%s): weight must be positive, got %v; disable the task with enabled: false instead",
+				i, task.Name, *task.Weight)
+		}
+		if task.MaxExecutions < 0 {
+			return fmt.Errorf("tasks[%d] (%s): max_executions must not be negative, got %d", i, task.Name, task.MaxExecutions)
+		}
+		if task.Cooldown != nil {
+			if _, err := task.Cooldown.Duration(); err != nil || task.Cooldown.Value < 0 {
+				return fmt.Errorf("tasks[%d] (%s): invalid cooldown %+v", i, task.Name, *task.Cooldown)
+			}
+		}
+	}
+
+	backoff := c.Delay.BetweenRetries.Backoff
+	if backoff.Jitter < 0 || backoff.Jitter > 1 {
+		return fmt.Errorf("delay.between_retries.backoff.jitter must be within [0, 1], got %v", backoff.Jitter)
+	}
+	if _, err := backoff.MaxDelay.Duration(); err != nil {
+		return fmt.Errorf("delay.between_retries.backoff.max_delay: %w", err)
+	}
+
+	if c.Actions.History.Enabled {
+		if _, err := c.Actions.History.Lookback.Duration(); err != nil || c.Actions.History.Lookback.Value <= 0 {
+			return fmt.Errorf("actions.history.lookback must be a positive duration, got %+v", c.Actions.History.Lookback)
+		}
+		if c.Actions.History.NoveltyBoost < 0 {
+			return fmt.Errorf("actions.history.novelty_boost must not be negative, got %v", c.Actions.History.NoveltyBoost)
+		}
+	}
+
+	if err := c.validateSteps(); err != nil {
+		return err
+	}
+
+	for name, network := range c.RPCNodes {
+		if len(network.URLs) == 0 {
+			return fmt.Errorf("rpc_nodes.%s: at least one URL is required", name)
+		}
+		if network.ChainID < 0 {
+			return fmt.Errorf("rpc_nodes.%s: chain_id must not be negative, got %d", name, network.ChainID)
+		}
+		if network.MaxGasPriceGwei < 0 {
+			return fmt.Errorf("rpc_nodes.%s: max_gas_price_gwei must not be negative, got %v", name, network.MaxGasPriceGwei)
+		}
+	}
+
+	if err := c.RPC.validate(); err != nil {
+		return err
+	}
+
+	for network, tokens := range c.Balances.Tokens {
+		if _, ok := c.RPCNodes[network]; !ok {
+			return fmt.Errorf("balances.tokens.%s: network is not defined in rpc_nodes", network)
+		}
+		for _, token := range tokens {
+			if !strings.HasPrefix(token, "0x") || len(token) != 42 {
+				return fmt.Errorf("balances.tokens.%s: invalid token address '%s'", network, token)
+			}
+		}
+	}
+
+	if c.Schedule.Enabled {
+		if window, err := c.Schedule.Window.Duration(); err != nil || window <= 0 {
+			return fmt.Errorf("schedule.window must be a positive duration, got %+v", c.Schedule.Window)
+		}
+		if _, _, err := c.Schedule.DailyHours(); err != nil {
+			return fmt.Errorf("schedule.%w", err)
+		}
+		if _, err := c.Schedule.Location(); err != nil {
+			return fmt.Errorf("schedule.timezone: %w", err)
+		}
+	}
+
+	if err := c.Daemon.validate(c); err != nil {
+		return err
+	}
+	if err := c.Metrics.validate(); err != nil {
+		return err
+	}
+	if err := c.Control.validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.validate(c); err != nil {
+		return err
+	}
+	if err := c.Sweep.validate(c); err != nil {
+		return err
+	}
+	if err := c.Approvals.validate(c); err != nil {
+		return err
+	}
+
+	if err := c.Activity.Targets.validate("activity.targets"); err != nil {
+		return err
+	}
+	for network, targets := range c.Activity.NetworkTargets {
+		if _, ok := c.RPCNodes[network]; !ok {
+			return fmt.Errorf("activity.network_targets.%s: network is not defined in rpc_nodes", network)
+		}
+		if err := targets.validate("activity.network_targets." + network); err != nil {
+			return err
+		}
+	}
+
+	switch c.Actions.SelectionMode {
+	case "", types.SelectionWithReplacement, types.SelectionWithoutReplacement:
+	default:
+		return fmt.Errorf("actions.selection_mode: unknown value '%s' (expected '%s' or '%s')",
+			c.Actions.SelectionMode, types.SelectionWithReplacement, types.SelectionWithoutReplacement)
+	}
+	if c.Actions.MaxRepeatsPerTask < 0 {
+		return fmt.Errorf("actions.max_repeats_per_task must not be negative, got %d", c.Actions.MaxRepeatsPerTask)
+	}
+
+	return nil
+}
+
+// validate checks rate limits and circuit breaker settings.
+func (r RPCConfig) validate() error {
+	if r.RateLimit.DefaultRPS < 0 {
+		return fmt.Errorf("rpc.rate_limit.default_rps must not be negative, got %v", r.RateLimit.DefaultRPS)
+	}
+	for network, rps := range r.RateLimit.Networks {
+		if rps < 0 {
+			return fmt.Errorf("rpc.rate_limit.networks.%s must not be negative, got %v", network, rps)
+		}
+	}
+	for endpoint, rps := range r.RateLimit.Endpoints {
+		if rps < 0 {
+			return fmt.Errorf("rpc.rate_limit.endpoints[%s] must not be negative, got %v", endpoint, rps)
+		}
+	}
+
+	breaker := r.CircuitBreaker
+	if !breaker.Enabled {
+		return nil
+	}
+	if breaker.FailureThreshold <= 0 {
+		return fmt.Errorf("rpc.circuit_breaker.failure_threshold must be positive, got %d", breaker.FailureThreshold)
+	}
+	if _, err := breaker.Cooldown.Duration(); err != nil || breaker.Cooldown.Value <= 0 {
+		return fmt.Errorf("rpc.circuit_breaker.cooldown must be a positive duration, got %+v", breaker.Cooldown)
+	}
+	if breaker.MaxWaits < 0 {
+		return fmt.Errorf("rpc.circuit_breaker.max_waits must not be negative, got %d", breaker.MaxWaits)
+	}
+	return nil
+}
+
+// validateSteps checks step IDs, dependency references and run_if conditions.
+func (c *Config) validateSteps() error {
+	stepCounts := make(map[string]int)
+	explicitIDs := make(map[string]bool)
+	for i, task := range c.Tasks {
+		if task.ID != "" {
+			if explicitIDs[task.ID] {
+				return fmt.Errorf("tasks[%d] (%s): duplicate step id '%s'", i, task.Name, task.ID)
+			}
+			explicitIDs[task.ID] = true
+		}
+		stepCounts[task.StepID()]++
+	}
+
+	checkRef := func(i int, task TaskConfigEntry, field, ref string) error {
+		switch stepCounts[ref] {
+		case 0:
+			return fmt.Errorf("tasks[%d] (%s): %s references unknown step '%s'", i, task.Name, field, ref)
+		case 1:
+			return nil
+		default:
+			return fmt.Errorf("tasks[%d] (%s): %s references ambiguous step '%s' (set a unique id)", i, task.Name, field, ref)
+		}
+	}
+
+	for i, task := range c.Tasks {
+		for _, dep := range task.DependsOn {
+			if dep == task.StepID() {
+				return fmt.Errorf("tasks[%d] (%s): step cannot depend on itself", i, task.Name)
+			}
+			if err := checkRef(i, task, "depends_on", dep); err != nil {
+				return err
+			}
+		}
+		if task.RunIf == nil {
+			continue
+		}
+		for step, status := range task.RunIf.StepStatus {
+			if err := checkRef(i, task, "run_if.step_status", step); err != nil {
+				return err
+			}
+			switch status {
+			case types.StepStatusSuccess, types.StepStatusFailed, types.StepStatusSkipped:
+			default:
+				return fmt.Errorf("tasks[%d] (%s): run_if.step_status: unknown status '%s' for step '%s'",
+					i, task.Name, status, step)
+			}
+		}
+		for _, group := range task.RunIf.WalletGroups {
+			if _, ok := c.WalletGroups[group]; !ok {
+				return fmt.Errorf("tasks[%d] (%s): run_if.wallet_groups references unknown group '%s'", i, task.Name, group)
+			}
+		}
+		if cond := task.RunIf.MinTokenBalance; cond != nil && (cond.Token == "" || cond.Min == "") {
+			return fmt.Errorf("tasks[%d] (%s): run_if.min_token_balance requires both token and min", i, task.Name)
+		}
+	}
+
+	for name, members := range c.WalletGroups {
+		for _, member := range members {
+			if strings.HasPrefix(member, "0x") {
+				if !isAddress(member) {
+					return fmt.Errorf("wallet_groups.%s: invalid address '%s'", name, member)
+				}
+				continue
+			}
+			if _, _, err := parseIndexRange(member); err != nil {
+				return fmt.Errorf("wallet_groups.%s: invalid member '%s' (expected address, index or range)", name, member)
+			}
+		}
+	}
+
+	return c.checkDependencyCycles()
+}
+
+// checkDependencyCycles reports an error if depends_on references form a cycle.
+func (c *Config) checkDependencyCycles() error {
+	deps := make(map[string][]string)
+	for _, task := range c.Tasks {
+		deps[task.StepID()] = append(deps[task.StepID()], task.DependsOn...)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var visit func(step string) error
+	visit = func(step string) error {
+		switch state[step] {
+		case visiting:
+			return fmt.Errorf("depends_on: dependency cycle detected at step '%s'", step)
+		case done:
+			return nil
+		}
+		state[step] = visiting
+		for _, dep := range deps[step] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[step] = done
+		return nil
+	}
+
+	for step := range deps {
+		if err := visit(step); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// WalletInGroup reports whether the wallet with the given address and original index belongs to the group.
+func (c *Config) WalletInGroup(group string, address string, index int) bool {
+	for _, member := range c.WalletGroups[group] {
+		if strings.HasPrefix(member, "0x") {
+			if strings.EqualFold(member, address) {
+				return true
+			}
+			continue
+		}
+		from, to, err := parseIndexRange(member)
+		if err == nil && index >= from && index <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupsOf returns the sorted names of the groups the wallet belongs to.
+func (c *Config) GroupsOf(address string, index int) []string {
+	var groups []string
+	for group := range c.WalletGroups {
+		if c.WalletInGroup(group, address, index) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// parseIndexRange parses a wallet index ("3") or an inclusive index range ("0-9").
+func parseIndexRange(value string) (int, int, error) {
+	fromStr, toStr, isRange := strings.Cut(strings.TrimSpace(value), "-")
+	from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(toStr))
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to || from < 0 {
+		return 0, 0, fmt.Errorf("invalid index range '%s'", value)
+	}
+	return from, to, nil
+}
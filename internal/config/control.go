@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+// ControlConfig configures the HTTP API controlling a running job: status, pause, resume,
+// cancellation and skipping of tasks.
+type ControlConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// ListenAddress is the host:port of the API (empty = "127.0.0.1:9092").
+	ListenAddress string `yaml:"listen_address,omitempty"`
+	// Token must be sent by clients as "Authorization: Bearer <token>".
+	// The CONTROL_API_TOKEN environment variable overrides it.
+	Token string `yaml:"token,omitempty"`
+}
+
+// DefaultControlListenAddress keeps the API local unless configured otherwise.
+const DefaultControlListenAddress = "127.0.0.1:9092"
+
+// Address returns the listen address of the control API.
+func (c ControlConfig) Address() string {
+	if c.ListenAddress == "" {
+		return DefaultControlListenAddress
+	}
+	return c.ListenAddress
+}
+
+// validate checks the listen address.
+func (c ControlConfig) validate() error {
+	if _, _, err := net.SplitHostPort(c.Address()); err != nil {
+		return fmt.Errorf("control.listen_address: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DaemonConfig configures the `daemon` command: recurring runs on cron schedules.
+type DaemonConfig struct {
+	// Timezone is an IANA time zone name for the cron expressions (empty = local time).
+	Timezone string `yaml:"timezone,omitempty"`
+	// LockTTL is how long the run lock stays valid without renewal, so the lock of a crashed
+	// process expires (zero value = 10 minutes). It is renewed while a run is in progress.
+	LockTTL   Duration         `yaml:"lock_ttl,omitempty"`
+	Schedules []DaemonSchedule `yaml:"schedules"`
+}
+
+// DaemonSchedule is a recurring run of a subset of tasks for a subset of wallets.
+type DaemonSchedule struct {
+	// Name identifies the schedule in logs and in the resume state.
+	Name string `yaml:"name"`
+	// Cron is a standard 5-field cron expression or a descriptor such as "@hourly".
+	Cron string `yaml:"cron"`
+	// Tasks limits the run to these steps (task IDs or names); empty = all enabled tasks.
+	Tasks []string `yaml:"tasks,omitempty"`
+	// WalletGroups limits the run to wallets of these groups; empty = all wallets.
+	WalletGroups []string `yaml:"wallet_groups,omitempty"`
+}
+
+// DefaultLockTTL is used when daemon.lock_ttl is not set.
+const DefaultLockTTL = 10 * time.Minute
+
+// RunLockTTL returns the validity of the run lock.
+func (d DaemonConfig) RunLockTTL() time.Duration {
+	ttl, err := d.LockTTL.Duration()
+	if err != nil || ttl <= 0 {
+		return DefaultLockTTL
+	}
+	return ttl
+}
+
+// Location returns the time zone of the cron expressions.
+func (d DaemonConfig) Location() (*time.Location, error) {
+	if d.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(d.Timezone)
+}
+
+// Schedule returns the schedule with the given name.
+func (d DaemonConfig) Schedule(name string) (DaemonSchedule, bool) {
+	for _, schedule := range d.Schedules {
+		if schedule.Name == name {
+			return schedule, true
+		}
+	}
+	return DaemonSchedule{}, false
+}
+
+// ForSchedule returns a copy of the config restricted to the tasks of the schedule:
+// other task entries are disabled and removed from the explicit task sequence.
+func (c *Config) ForSchedule(schedule DaemonSchedule) *Config {
+	scoped := *c
+	if len(schedule.Tasks) == 0 {
+		return &scoped
+	}
+	selected := make(map[string]bool, len(schedule.Tasks))
+	for _, step := range schedule.Tasks {
+		selected[step] = true
+	}
+
+	scoped.Tasks = make([]TaskConfigEntry, len(c.Tasks))
+	enabledNames := make(map[string]bool)
+	for i, task := range c.Tasks {
+		if !selected[task.StepID()] && !selected[string(task.Name)] {
+			task.Enabled = false
+		}
+		if task.Enabled {
+			enabledNames[string(task.Name)] = true
+		}
+		scoped.Tasks[i] = task
+	}
+	if len(c.Actions.ExplicitTaskSequence) > 0 {
+		scoped.Actions.ExplicitTaskSequence = nil
+		for _, name := range c.Actions.ExplicitTaskSequence {
+			if enabledNames[name] {
+				scoped.Actions.ExplicitTaskSequence = append(scoped.Actions.ExplicitTaskSequence, name)
+			}
+		}
+	}
+	return &scoped
+}
+
+// validate checks the cron expressions and the references of the schedules.
+func (d DaemonConfig) validate(c *Config) error {
+	if _, err := d.Location(); err != nil {
+		return fmt.Errorf("daemon.timezone: %w", err)
+	}
+	if d.LockTTL.Value < 0 {
+		return fmt.Errorf("daemon.lock_ttl must not be negative, got %+v", d.LockTTL)
+	}
+	if _, err := d.LockTTL.Duration(); err != nil {
+		return fmt.Errorf("daemon.lock_ttl: %w", err)
+	}
+
+	steps := make(map[string]bool)
+	for _, task := range c.Tasks {
+		steps[task.StepID()] = true
+		steps[string(task.Name)] = true
+	}
+	names := make(map[string]bool)
+	for i, schedule := range d.Schedules {
+		if schedule.Name == "" {
+			return fmt.Errorf("daemon.schedules[%d]: name is required", i)
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("daemon.schedules[%d]: duplicate name '%s'", i, schedule.Name)
+		}
+		names[schedule.Name] = true
+		if _, err := cron.ParseStandard(schedule.Cron); err != nil {
+			return fmt.Errorf("daemon.schedules[%d] (%s): invalid cron expression '%s': %w", i, schedule.Name, schedule.Cron, err)
+		}
+		for _, step := range schedule.Tasks {
+			if !steps[step] {
+				return fmt.Errorf("daemon.schedules[%d] (%s): unknown task '%s'", i, schedule.Name, step)
+			}
+		}
+		for _, group := range schedule.WalletGroups {
+			if _, ok := c.WalletGroups[group]; !ok {
+				return fmt.Errorf("daemon.schedules[%d] (%s): unknown wallet group '%s'", i, schedule.Name, group)
+			}
+		}
+	}
+	return nil
+}
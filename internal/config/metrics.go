@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MetricsConfig configures the opt-in HTTP listener exposing Prometheus metrics.
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// ListenAddress is the host:port of the listener (empty = ":9090").
+	ListenAddress string `yaml:"listen_address,omitempty"`
+	// Path is the HTTP path of the metrics (empty = "/metrics").
+	Path string `yaml:"path,omitempty"`
+}
+
+// Defaults of the metrics listener.
+const (
+	DefaultMetricsListenAddress = ":9090"
+	DefaultMetricsPath          = "/metrics"
+)
+
+// Address returns the listen address of the metrics listener.
+func (m MetricsConfig) Address() string {
+	if m.ListenAddress == "" {
+		return DefaultMetricsListenAddress
+	}
+	return m.ListenAddress
+}
+
+// HTTPPath returns the HTTP path the metrics are served on.
+func (m MetricsConfig) HTTPPath() string {
+	if m.Path == "" {
+		return DefaultMetricsPath
+	}
+	return m.Path
+}
+
+// validate checks the listen address and path.
+func (m MetricsConfig) validate() error {
+	if _, _, err := net.SplitHostPort(m.Address()); err != nil {
+		return fmt.Errorf("metrics.listen_address: %w", err)
+	}
+	if !strings.HasPrefix(m.HTTPPath(), "/") {
+		return fmt.Errorf("metrics.path must start with '/', got '%s'", m.Path)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"retro/internal/types"
+)
+
+// NotificationsConfig configures notifications about runs sent to webhooks and chat bots.
+type NotificationsConfig struct {
+	Sinks []NotifySinkConfig `yaml:"sinks"`
+	// Events lists the event types to send with their filters; types not listed are not sent.
+	Events map[types.NotifyEvent]NotifyEventConfig `yaml:"events"`
+}
+
+// NotifySinkConfig is a destination of notifications. URL, BotToken, ChatID and header values
+// may reference environment variables as ${NAME}.
+type NotifySinkConfig struct {
+	Type types.SinkType `yaml:"type"`
+	// Name identifies the sink in logs (empty = the type).
+	Name string `yaml:"name,omitempty"`
+	// URL is the endpoint of a webhook or Discord sink.
+	URL string `yaml:"url,omitempty"`
+	// Headers are added to the requests of a webhook sink, e.g. for authorization.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// BaseURL is the Bot API address of a Telegram sink (empty = https://api.telegram.org).
+	BaseURL  string `yaml:"base_url,omitempty"`
+	BotToken string `yaml:"bot_token,omitempty"`
+	ChatID   string `yaml:"chat_id,omitempty"`
+	// Events limits the sink to these event types (empty = all sent events).
+	Events []types.NotifyEvent `yaml:"events,omitempty"`
+}
+
+// NotifyEventConfig filters and rate-limits one event type.
+type NotifyEventConfig struct {
+	// Networks limits events tied to a network to these networks (empty = all).
+	Networks []string `yaml:"networks,omitempty"`
+	// RateLimit caps how many events of the type are sent (zero value = 10 per hour).
+	RateLimit NotifyRateLimit `yaml:"rate_limit,omitempty"`
+	// MinNativeBalance is the low_balance threshold in native coin units, e.g. "0.005".
+	MinNativeBalance string `yaml:"min_native_balance,omitempty"`
+	// NetworkMinBalance overrides MinNativeBalance for some networks.
+	NetworkMinBalance map[string]string `yaml:"network_min_balance,omitempty"`
+}
+
+// NotifyRateLimit allows at most Max events per Per.
+type NotifyRateLimit struct {
+	Max int      `yaml:"max"`
+	Per Duration `yaml:"per"`
+}
+
+// Defaults of notification rate limits.
+const (
+	DefaultNotifyRateMax = 10
+	DefaultNotifyRatePer = time.Hour
+)
+
+// Limit returns the number of events and the period of the rate limit.
+func (r NotifyRateLimit) Limit() (int, time.Duration) {
+	maxEvents, per := r.Max, DefaultNotifyRatePer
+	if maxEvents <= 0 {
+		maxEvents = DefaultNotifyRateMax
+	}
+	if r.Per.Value > 0 {
+		if d, err := r.Per.Duration(); err == nil {
+			per = d
+		}
+	}
+	return maxEvents, per
+}
+
+// AllowsNetwork reports whether events of the network pass the filter.
+func (e NotifyEventConfig) AllowsNetwork(network string) bool {
+	return len(e.Networks) == 0 || network == "" || slices.Contains(e.Networks, network)
+}
+
+// MinBalanceFor returns the low balance threshold of the network ("" = not checked).
+func (e NotifyEventConfig) MinBalanceFor(network string) string {
+	if threshold, ok := e.NetworkMinBalance[network]; ok {
+		return threshold
+	}
+	return e.MinNativeBalance
+}
+
+// validate checks sink settings, event names, rate limits and thresholds.
+func (n NotificationsConfig) validate(c *Config) error {
+	for i, sink := range n.Sinks {
+		switch sink.Type {
+		case types.SinkWebhook, types.SinkDiscord:
+			if sink.URL == "" {
+				return fmt.Errorf("notifications.sinks[%d] (%s): url is required", i, sink.Type)
+			}
+		case types.SinkTelegram:
+			if sink.BotToken == "" || sink.ChatID == "" {
+				return fmt.Errorf("notifications.sinks[%d] (%s): bot_token and chat_id are required", i, sink.Type)
+			}
+		default:
+			return fmt.Errorf("notifications.sinks[%d]: unknown type '%s' (expected '%s', '%s' or '%s')",
+				i, sink.Type, types.SinkWebhook, types.SinkTelegram, types.SinkDiscord)
+		}
+		for _, event := range sink.Events {
+			if !slices.Contains(types.NotifyEvents, event) {
+				return fmt.Errorf("notifications.sinks[%d]: unknown event '%s'", i, event)
+			}
+		}
+	}
+
+	for event, eventCfg := range n.Events {
+		path := "notifications.events." + string(event)
+		if !slices.Contains(types.NotifyEvents, event) {
+			return fmt.Errorf("notifications.events: unknown event '%s'", event)
+		}
+		for _, network := range eventCfg.Networks {
+			if _, ok := c.RPCNodes[network]; !ok {
+				return fmt.Errorf("%s.networks: network '%s' is not defined in rpc_nodes", path, network)
+			}
+		}
+		if eventCfg.RateLimit.Max < 0 || eventCfg.RateLimit.Per.Value < 0 {
+			return fmt.Errorf("%s.rate_limit must not be negative", path)
+		}
+		if _, err := eventCfg.RateLimit.Per.Duration(); err != nil {
+			return fmt.Errorf("%s.rate_limit.per: %w", path, err)
+		}
+		thresholds := map[string]string{"min_native_balance": eventCfg.MinNativeBalance}
+		for network, threshold := range eventCfg.NetworkMinBalance {
+			if _, ok := c.RPCNodes[network]; !ok {
+				return fmt.Errorf("%s.network_min_balance: network '%s' is not defined in rpc_nodes", path, network)
+			}
+			thresholds["network_min_balance."+network] = threshold
+		}
+		for name, threshold := range thresholds {
+			if threshold == "" {
+				continue
+			}
+			if !isWeiAmount(threshold) {
+				return fmt.Errorf("%s.%s must be a non-negative decimal amount, got '%s'", path, name, threshold)
+			}
+		}
+	}
+	return nil
+}
+
+// isWeiAmount reports whether s is a non-negative, finite decimal amount of the native coin,
+// parsed the same way as utils.ToWei parses it at run time.
+func isWeiAmount(s string) bool {
+	amount, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	return err == nil && !amount.IsInf() && amount.Sign() >= 0
+}
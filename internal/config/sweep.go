@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SweepConfig configures the sweep command, which collects the funds of the wallets at one address.
+type SweepConfig struct {
+	// To is the collector address; the --to flag overrides it.
+	To string `yaml:"to,omitempty"`
+	// MinNative is the native dust threshold: a smaller amount left after the fee is not sent.
+	MinNative string `yaml:"min_native,omitempty"`
+	// Networks lists the tokens swept in each network and its own native threshold.
+	Networks map[string]SweepNetworkConfig `yaml:"networks,omitempty"`
+}
+
+// SweepNetworkConfig holds the sweep settings of one network.
+type SweepNetworkConfig struct {
+	// MinNative replaces sweep.min_native in this network.
+	MinNative string `yaml:"min_native,omitempty"`
+	// Tokens are sent before the native coin.
+	Tokens []SweepToken `yaml:"tokens,omitempty"`
+}
+
+// SweepToken is a token to sweep with its dust threshold in token units.
+type SweepToken struct {
+	Address string `yaml:"address"`
+	Min     string `yaml:"min,omitempty"`
+}
+
+// DefaultNetworks returns the networks swept without --networks: those of sweep.networks,
+// or all networks of rpc_nodes when none are configured.
+func (s SweepConfig) DefaultNetworks(c *Config) []string {
+	var networks []string
+	if len(s.Networks) > 0 {
+		for network := range s.Networks {
+			networks = append(networks, network)
+		}
+	} else {
+		for network := range c.RPCNodes {
+			networks = append(networks, network)
+		}
+	}
+	sort.Strings(networks)
+	return networks
+}
+
+// ForSweep returns a copy of the config that runs only the sweep task, once in each of the
+// networks, in random order, with the configured concurrency and delays. Resume and the start
+// time schedule are disabled: a sweep always goes over all the wallets and is safe to repeat.
+func (c *Config) ForSweep(to string, networks []string) *Config {
+	scoped := *c
+	scoped.Tasks = make([]TaskConfigEntry, 0, len(networks))
+	for _, network := range networks {
+		networkCfg := c.Sweep.Networks[network]
+		minNative := networkCfg.MinNative
+		if minNative == "" {
+			minNative = c.Sweep.MinNative
+		}
+		tokens := make([]interface{}, 0, len(networkCfg.Tokens))
+		for _, token := range networkCfg.Tokens {
+			tokens = append(tokens, map[string]interface{}{"address": token.Address, "min": token.Min})
+		}
+		params := map[string]interface{}{"to": to, "tokens": tokens}
+		if minNative != "" {
+			params["min_native"] = minNative
+		}
+		scoped.Tasks = append(scoped.Tasks, TaskConfigEntry{
+			Name:    types.TaskNameSweep,
+			ID:      "sweep_" + network,
+			Network: network,
+			Enabled: true,
+			Params:  params,
+		})
+	}
+	scoped.Actions = ActionsConfig{
+		ActionsPerAccount: MinMax{Min: len(networks), Max: len(networks)},
+		TaskOrder:         types.TaskOrderRandom,
+		SelectionMode:     types.SelectionWithoutReplacement,
+	}
+	scoped.State.ResumeEnabled = false
+	scoped.Schedule.Enabled = false
+	return &scoped
+}
+
+// validate checks the collector address, the networks, the token addresses and the thresholds.
+func (s SweepConfig) validate(c *Config) error {
+	if s.To != "" && !isAddress(s.To) {
+		return fmt.Errorf("sweep.to: invalid address '%s'", s.To)
+	}
+	thresholds := map[string]string{"sweep.min_native": s.MinNative}
+	for network, networkCfg := range s.Networks {
+		path := "sweep.networks." + network
+		if _, ok := c.RPCNodes[network]; !ok {
+			return fmt.Errorf("%s: network is not defined in rpc_nodes", path)
+		}
+		thresholds[path+".min_native"] = networkCfg.MinNative
+		for i, token := range networkCfg.Tokens {
+			if !isAddress(token.Address) {
+				return fmt.Errorf("%s.tokens[%d]: invalid token address '%s'", path, i, token.Address)
+			}
+			thresholds[fmt.Sprintf("%s.tokens[%d].min", path, i)] = token.Min
+		}
+	}
+	for path, threshold := range thresholds {
+		if threshold == "" {
+			continue
+		}
+		if value, err := strconv.ParseFloat(threshold, 64); err != nil || value < 0 {
+			return fmt.Errorf("%s must be a non-negative decimal amount, got '%s'", path, threshold)
+		}
+	}
+	return nil
+}
+
+// isAddress reports whether s is a 0x-prefixed hex-encoded address.
+func isAddress(s string) bool {
+	return strings.HasPrefix(s, "0x") && common.IsHexAddress(s)
+}
@@ -0,0 +1,361 @@
+// Package control lets an operator steer a running job: inspect its progress, pause and
+// resume workers, cancel the run or a single wallet and skip the task a wallet is executing.
+// A nil *Controller is valid and does nothing, so the pause points cost nothing when the
+// control API is disabled.
+package control
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/logger"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrRunCancelled is the cancellation cause of a run stopped through the API.
+	ErrRunCancelled = errors.New("запуск отменен через API управления")
+	// ErrWalletCancelled is the cancellation cause of a wallet stopped through the API.
+	ErrWalletCancelled = errors.New("обработка кошелька отменена через API управления")
+	// ErrTaskSkipped is the cancellation cause of a task skipped through the API.
+	ErrTaskSkipped = errors.New("задача пропущена через API управления")
+
+	ErrNoActiveRun     = errors.New("нет активного запуска")
+	ErrWalletNotActive = errors.New("кошелек сейчас не обрабатывается")
+	ErrNoActiveTask    = errors.New("кошелек сейчас не выполняет задачу")
+	ErrWalletRequired  = errors.New("обрабатывается несколько кошельков, укажите wallet")
+)
+
+// Controller tracks the state of the current run and applies the operator's commands to it.
+type Controller struct {
+	log logger.Logger
+
+	mu        sync.Mutex
+	run       *runState
+	paused    bool
+	resumed   chan struct{} // closed when a pause ends
+	wallets   map[common.Address]*walletState
+	lastRunID string
+}
+
+type runState struct {
+	id        string
+	startedAt time.Time
+	total     int
+	completed int
+	failed    int
+	cancelled int
+	cancel    context.CancelCauseFunc
+}
+
+type walletState struct {
+	index     int
+	startedAt time.Time
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	task      *taskState
+}
+
+type taskState struct {
+	entry     config.TaskConfigEntry
+	startedAt time.Time
+	cancel    context.CancelCauseFunc
+}
+
+// New creates a controller.
+func New(log logger.Logger) *Controller {
+	return &Controller{log: log, wallets: make(map[common.Address]*walletState)}
+}
+
+// RunStarted registers a run of total wallets. The returned context is canceled when the run
+// is canceled through the API; finish must be called once all wallets are done.
+func (c *Controller) RunStarted(ctx context.Context, runID string, total int) (context.Context, func()) {
+	if c == nil {
+		return ctx, func() {}
+	}
+	runCtx, cancel := context.WithCancelCause(ctx)
+	c.mu.Lock()
+	c.run = &runState{id: runID, startedAt: time.Now(), total: total, cancel: cancel}
+	c.lastRunID = runID
+	c.mu.Unlock()
+
+	return runCtx, func() {
+		cancel(nil)
+		c.mu.Lock()
+		c.run = nil
+		c.mu.Unlock()
+	}
+}
+
+// WalletStarted registers the start of processing of a wallet and returns the context to
+// process it with; it is canceled when the wallet is canceled through the API.
+func (c *Controller) WalletStarted(ctx context.Context, address common.Address, index int) context.Context {
+	if c == nil {
+		return ctx
+	}
+	walletCtx, cancel := context.WithCancelCause(ctx)
+	c.mu.Lock()
+	c.wallets[address] = &walletState{index: index, startedAt: time.Now(), ctx: walletCtx, cancel: cancel}
+	c.mu.Unlock()
+	return walletCtx
+}
+
+// WalletFinished registers the end of processing of a wallet with its result.
+func (c *Controller) WalletFinished(address common.Address, err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wallet, ok := c.wallets[address]
+	if !ok {
+		return
+	}
+	delete(c.wallets, address)
+	cancelled := WalletCancelled(wallet.ctx)
+	wallet.cancel(nil)
+	if c.run == nil {
+		return
+	}
+	switch {
+	case cancelled:
+		c.run.cancelled++
+	case err != nil:
+		c.run.failed++
+	default:
+		c.run.completed++
+	}
+}
+
+// TaskStarted registers the task a wallet executes and returns the context to execute it
+// with; it is canceled when the task is skipped through the API. finish must be called when
+// the task is done.
+func (c *Controller) TaskStarted(ctx context.Context, address common.Address, entry config.TaskConfigEntry) (context.Context, func()) {
+	if c == nil {
+		return ctx, func() {}
+	}
+	taskCtx, cancel := context.WithCancelCause(ctx)
+	task := &taskState{entry: entry, startedAt: time.Now(), cancel: cancel}
+	c.mu.Lock()
+	if wallet, ok := c.wallets[address]; ok {
+		wallet.task = task
+	}
+	c.mu.Unlock()
+
+	return taskCtx, func() {
+		cancel(nil)
+		c.mu.Lock()
+		if wallet, ok := c.wallets[address]; ok && wallet.task == task {
+			wallet.task = nil
+		}
+		c.mu.Unlock()
+	}
+}
+
+// WaitIfPaused is a pause point: it blocks while the job is paused. It returns ctx.Err()
+// if ctx is canceled while waiting.
+func (c *Controller) WaitIfPaused(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	paused, resumed := c.paused, c.resumed
+	c.mu.Unlock()
+	if !paused {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pause makes workers wait at the next pause point. It reports false if already paused.
+func (c *Controller) Pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return false
+	}
+	c.paused = true
+	c.resumed = make(chan struct{})
+	c.log.Warn("Обработка приостановлена через API управления: воркеры завершат текущие задачи и будут ждать")
+	return true
+}
+
+// Resume releases the workers waiting at pause points. It reports false if not paused.
+func (c *Controller) Resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return false
+	}
+	c.paused = false
+	close(c.resumed)
+	c.log.Info("Обработка возобновлена через API управления")
+	return true
+}
+
+// CancelRun cancels the current run.
+func (c *Controller) CancelRun() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.run == nil {
+		return ErrNoActiveRun
+	}
+	c.log.Warn("Запуск отменяется через API управления", "run_id", c.run.id)
+	c.run.cancel(ErrRunCancelled)
+	return nil
+}
+
+// CancelWallet stops the processing of one wallet; the run continues with the others.
+func (c *Controller) CancelWallet(address common.Address) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wallet, ok := c.wallets[address]
+	if !ok {
+		return ErrWalletNotActive
+	}
+	c.log.Warn("Обработка кошелька отменяется через API управления", "addr", address.Hex())
+	wallet.cancel(ErrWalletCancelled)
+	return nil
+}
+
+// SkipTask cancels the task a wallet executes; the wallet continues with its next task.
+// A nil address selects the only active wallet.
+func (c *Controller) SkipTask(address *common.Address) (WalletStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		target common.Address
+		wallet *walletState
+		err    error
+	)
+	if address != nil {
+		target = *address
+		wallet = c.wallets[target]
+		if wallet == nil {
+			return WalletStatus{}, ErrWalletNotActive
+		}
+	} else if target, wallet, err = c.onlyWallet(); err != nil {
+		return WalletStatus{}, err
+	}
+	if wallet.task == nil {
+		return WalletStatus{}, ErrNoActiveTask
+	}
+	status := wallet.status(target)
+	c.log.Warn("Задача пропускается через API управления", "task", wallet.task.entry.Name,
+		"step", wallet.task.entry.StepID(), "addr", target.Hex())
+	wallet.task.cancel(ErrTaskSkipped)
+	return status, nil
+}
+
+// onlyWallet returns the single active wallet; it fails when no wallet or several are active.
+// The caller must hold c.mu.
+func (c *Controller) onlyWallet() (common.Address, *walletState, error) {
+	var (
+		target common.Address
+		wallet *walletState
+	)
+	for address, state := range c.wallets {
+		if wallet != nil {
+			return common.Address{}, nil, ErrWalletRequired
+		}
+		target, wallet = address, state
+	}
+	if wallet == nil {
+		return common.Address{}, nil, ErrWalletNotActive
+	}
+	return target, wallet, nil
+}
+
+// WalletCancelled reports whether ctx was canceled because its wallet was canceled through the API.
+func WalletCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrWalletCancelled)
+}
+
+// TaskSkipped reports whether ctx was canceled because its task was skipped through the API.
+func TaskSkipped(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrTaskSkipped)
+}
+
+// Status describes the current run.
+type Status struct {
+	Running       bool           `json:"running"`
+	RunID         string         `json:"run_id,omitempty"`
+	LastRunID     string         `json:"last_run_id,omitempty"`
+	StartedAt     *time.Time     `json:"started_at,omitempty"`
+	Paused        bool           `json:"paused"`
+	Progress      Progress       `json:"progress"`
+	ActiveWallets []WalletStatus `json:"active_wallets"`
+}
+
+// Progress counts the wallets of the run by outcome.
+type Progress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	Active    int `json:"active"`
+	Remaining int `json:"remaining"`
+}
+
+// WalletStatus describes a wallet being processed and its current task, if any.
+type WalletStatus struct {
+	Address       string         `json:"address"`
+	Index         int            `json:"index"`
+	StartedAt     time.Time      `json:"started_at"`
+	Task          types.TaskName `json:"task,omitempty"`
+	Step          string         `json:"step,omitempty"`
+	Network       string         `json:"network,omitempty"`
+	TaskStartedAt *time.Time     `json:"task_started_at,omitempty"`
+}
+
+func (w *walletState) status(address common.Address) WalletStatus {
+	status := WalletStatus{Address: address.Hex(), Index: w.index, StartedAt: w.startedAt}
+	if w.task != nil {
+		startedAt := w.task.startedAt
+		status.Task = w.task.entry.Name
+		status.Step = w.task.entry.StepID()
+		status.Network = w.task.entry.Network
+		status.TaskStartedAt = &startedAt
+	}
+	return status
+}
+
+// Status returns a snapshot of the current run.
+func (c *Controller) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := Status{Paused: c.paused, LastRunID: c.lastRunID, ActiveWallets: []WalletStatus{}}
+	for address, wallet := range c.wallets {
+		status.ActiveWallets = append(status.ActiveWallets, wallet.status(address))
+	}
+	sort.Slice(status.ActiveWallets, func(i, j int) bool {
+		return status.ActiveWallets[i].Index < status.ActiveWallets[j].Index
+	})
+	if c.run != nil {
+		startedAt := c.run.startedAt
+		status.Running = true
+		status.RunID = c.run.id
+		status.StartedAt = &startedAt
+		status.Progress = Progress{
+			Total:     c.run.total,
+			Completed: c.run.completed,
+			Failed:    c.run.failed,
+			Cancelled: c.run.cancelled,
+			Active:    len(c.wallets),
+		}
+		done := c.run.completed + c.run.failed + c.run.cancelled
+		status.Progress.Remaining = max(c.run.total-done-len(c.wallets), 0)
+	}
+	return status
+}
@@ -0,0 +1,152 @@
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Serve starts the control API if it is enabled in cfg. The server is stopped when ctx is
+// canceled. An error is returned if no token is configured or the address cannot be bound.
+func Serve(ctx context.Context, cfg config.ControlConfig, c *Controller, log logger.Logger) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Token == "" {
+		return errors.New("API управления включено, но токен не задан (control.token или CONTROL_API_TOKEN)")
+	}
+	listener, err := net.Listen("tcp", cfg.Address())
+	if err != nil {
+		return fmt.Errorf("не удалось запустить API управления на %s: %w", cfg.Address(), err)
+	}
+
+	server := &http.Server{
+		Handler:           requireToken(cfg.Token, c.routes()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("API управления остановлено с ошибкой", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warn("Ошибка остановки API управления", "error", err)
+		}
+	}()
+
+	log.Info("API управления запущено", "address", listener.Addr().String())
+	return nil
+}
+
+// routes returns the handler of the API endpoints.
+func (c *Controller) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, c.Status())
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		c.Pause()
+		writeJSON(w, http.StatusOK, c.Status())
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		c.Resume()
+		writeJSON(w, http.StatusOK, c.Status())
+	})
+	mux.HandleFunc("POST /cancel", func(w http.ResponseWriter, r *http.Request) {
+		address, err := walletParam(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if address == nil {
+			err = c.CancelRun()
+		} else {
+			err = c.CancelWallet(*address)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, c.Status())
+	})
+	mux.HandleFunc("POST /skip-task", func(w http.ResponseWriter, r *http.Request) {
+		address, err := walletParam(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		skipped, err := c.SkipTask(address)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, skipped)
+	})
+	return mux
+}
+
+// requireToken rejects requests without the bearer token.
+func requireToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "неверный или отсутствующий токен"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// walletParam reads the optional wallet address from the query or a form body.
+func walletParam(r *http.Request) (*common.Address, error) {
+	value := strings.TrimSpace(r.FormValue("wallet"))
+	if value == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(value) {
+		return nil, fmt.Errorf("%w: '%s'", errInvalidAddress, value)
+	}
+	address := common.HexToAddress(value)
+	return &address, nil
+}
+
+var errInvalidAddress = errors.New("неверный адрес кошелька")
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError maps controller errors to HTTP status codes.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errInvalidAddress), errors.Is(err, ErrWalletRequired):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrWalletNotActive):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrNoActiveRun), errors.Is(err, ErrNoActiveTask):
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,266 @@
+// Package daemon keeps the application running and starts runs on the cron schedules from the
+// config. Every schedule runs its own subset of tasks and wallets with separate resume state;
+// a lock in the database keeps runs from overlapping.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"retro/internal/app"
+	"retro/internal/bootstrap"
+	"retro/internal/config"
+	"retro/internal/keyloader"
+	"retro/internal/logger"
+	"retro/internal/storage"
+
+	"github.com/robfig/cron/v3"
+)
+
+// runStatusKey is the state key of a schedule marking whether its last run finished.
+const runStatusKey = "daemon_run_status"
+
+// Values of runStatusKey.
+const (
+	runStatusRunning   = "running"
+	runStatusCompleted = "completed"
+)
+
+// Daemon triggers runs on the configured cron schedules. Keys and storage are loaded once;
+// the config is reloaded before every run.
+type Daemon struct {
+	configPath   string
+	cfg          *config.Config
+	wallets      []*keyloader.LoadedKey
+	txLogger     storage.TransactionLogger
+	stateStorage storage.StateStorage
+	log          logger.Logger
+	opts         []app.Option
+
+	cron *cron.Cron
+	// entries maps a schedule name to its cron entry and expression.
+	entries map[string]cronEntry
+	cronMu  sync.Mutex
+	// runMu allows one run at a time within the process; the run lock guards across processes.
+	runMu    sync.Mutex
+	runCount int
+}
+
+type cronEntry struct {
+	id   cron.EntryID
+	spec string
+}
+
+// New creates a daemon. cfg is the config already loaded from configPath.
+func New(
+	configPath string,
+	cfg *config.Config,
+	wallets []*keyloader.LoadedKey,
+	txLogger storage.TransactionLogger,
+	stateStorage storage.StateStorage,
+	log logger.Logger,
+	opts ...app.Option,
+) *Daemon {
+	return &Daemon{
+		configPath:   configPath,
+		cfg:          cfg,
+		wallets:      wallets,
+		txLogger:     txLogger,
+		stateStorage: stateStorage,
+		log:          log,
+		opts:         opts,
+		entries:      make(map[string]cronEntry),
+	}
+}
+
+// Run schedules the runs and blocks until ctx is canceled; a run in progress is then
+// interrupted and awaited.
+func (d *Daemon) Run(ctx context.Context) error {
+	if len(d.cfg.Daemon.Schedules) == 0 {
+		return errors.New("в конфигурации нет расписаний (daemon.schedules)")
+	}
+	location, err := d.cfg.Daemon.Location()
+	if err != nil {
+		return fmt.Errorf("daemon.timezone: %w", err)
+	}
+
+	d.cron = cron.New(cron.WithLocation(location))
+	if err := d.syncSchedules(ctx, d.cfg); err != nil {
+		return err
+	}
+	d.cron.Start()
+	d.log.Info("Демон запущен", "schedules", len(d.cfg.Daemon.Schedules), "timezone", location.String())
+	d.logNextRuns()
+
+	<-ctx.Done()
+	d.log.Warn("Остановка демона: ожидание завершения текущего запуска...")
+	<-d.cron.Stop().Done()
+	d.log.Info("Демон остановлен.")
+	return nil
+}
+
+// syncSchedules adds, replaces and removes cron entries to match the schedules of cfg.
+func (d *Daemon) syncSchedules(ctx context.Context, cfg *config.Config) error {
+	d.cronMu.Lock()
+	defer d.cronMu.Unlock()
+
+	wanted := make(map[string]string, len(cfg.Daemon.Schedules))
+	for _, schedule := range cfg.Daemon.Schedules {
+		wanted[schedule.Name] = schedule.Cron
+	}
+	for name, entry := range d.entries {
+		if spec, ok := wanted[name]; !ok || spec != entry.spec {
+			d.cron.Remove(entry.id)
+			delete(d.entries, name)
+			d.log.Info("Расписание удалено из планировщика", "schedule", name)
+		}
+	}
+	for _, schedule := range cfg.Daemon.Schedules {
+		if _, ok := d.entries[schedule.Name]; ok {
+			continue
+		}
+		name := schedule.Name
+		id, err := d.cron.AddFunc(schedule.Cron, func() { d.runSchedule(ctx, name) })
+		if err != nil {
+			return fmt.Errorf("расписание '%s': неверное cron-выражение '%s': %w", name, schedule.Cron, err)
+		}
+		d.entries[name] = cronEntry{id: id, spec: schedule.Cron}
+		d.log.Info("Расписание добавлено", "schedule", name, "cron", schedule.Cron)
+	}
+	return nil
+}
+
+// logNextRuns logs the next start time of every schedule.
+func (d *Daemon) logNextRuns() {
+	d.cronMu.Lock()
+	defer d.cronMu.Unlock()
+	for name, entry := range d.entries {
+		if next := d.cron.Entry(entry.id).Next; !next.IsZero() {
+			d.log.Info("Следующий запуск", "schedule", name, "at", next.Local().Format(time.DateTime))
+		}
+	}
+}
+
+// reloadConfig reads the config file again; on failure the previous config is kept.
+func (d *Daemon) reloadConfig() *config.Config {
+	cfg, err := config.LoadConfig(d.configPath)
+	if err != nil {
+		d.log.Error("Ошибка перезагрузки конфигурации, используется предыдущая", "path", d.configPath, "error", err)
+		return d.cfg
+	}
+	d.cfg = cfg
+	return cfg
+}
+
+// runSchedule performs one run of the schedule.
+func (d *Daemon) runSchedule(ctx context.Context, name string) {
+	if ctx.Err() != nil {
+		return
+	}
+	if !d.runMu.TryLock() {
+		d.log.Warn("Запуск по расписанию пропущен: предыдущий запуск еще выполняется", "schedule", name)
+		return
+	}
+	defer d.runMu.Unlock()
+
+	cfg := d.reloadConfig()
+	if err := d.syncSchedules(ctx, cfg); err != nil {
+		d.log.Error("Ошибка обновления расписаний после перезагрузки конфигурации", "error", err)
+	}
+	schedule, ok := cfg.Daemon.Schedule(name)
+	if !ok {
+		d.log.Warn("Расписание удалено из конфигурации, запуск пропущен", "schedule", name)
+		return
+	}
+
+	lock, err := app.AcquireRunLock(ctx, d.stateStorage, cfg.Daemon.RunLockTTL(), d.log)
+	if err != nil {
+		if errors.Is(err, app.ErrRunLocked) {
+			d.log.Warn("Запуск по расписанию пропущен: другой запуск держит блокировку", "schedule", name)
+		} else {
+			d.log.Error("Запуск по расписанию пропущен", "schedule", name, "error", err)
+		}
+		return
+	}
+	defer func() {
+		if err := lock.Close(); err != nil {
+			d.log.Error("Ошибка освобождения блокировки запуска", "error", err)
+		}
+	}()
+
+	d.runCount++
+	started := time.Now()
+	d.log.Info("Запуск по расписанию", "schedule", name, "run", d.runCount)
+	completed := d.execute(ctx, cfg, schedule)
+	d.log.Info("Запуск по расписанию завершен", "schedule", name, "completed", completed,
+		"duration", time.Since(started).Round(time.Second))
+	d.logNextRuns()
+}
+
+// execute runs the application for the schedule and reports whether the run finished
+// without being interrupted.
+func (d *Daemon) execute(ctx context.Context, cfg *config.Config, schedule config.DaemonSchedule) bool {
+	state := storage.WithKeyPrefix(d.stateStorage, "daemon."+schedule.Name+".")
+	if err := d.startCycle(ctx, state, schedule.Name); err != nil {
+		d.log.Error("Ошибка подготовки состояния расписания", "schedule", schedule.Name, "error", err)
+		return false
+	}
+
+	bootstrap.RegisterTasksFromConfig(cfg, d.log)
+	runCfg := cfg.ForSchedule(schedule)
+	opts := d.opts
+	if len(schedule.WalletGroups) > 0 {
+		opts = append(append([]app.Option{}, d.opts...), app.WithWalletFilter(func(index int, key *keyloader.LoadedKey) bool {
+			for _, group := range schedule.WalletGroups {
+				if cfg.WalletInGroup(group, key.Address.Hex(), index) {
+					return true
+				}
+			}
+			return false
+		}))
+	}
+
+	var wg sync.WaitGroup
+	application := app.NewApplication(runCfg, d.wallets, &wg, d.txLogger, state, d.log, opts...)
+	application.Run(ctx)
+	wg.Wait()
+	if err := application.Close(); err != nil {
+		d.log.Error("Ошибка освобождения ресурсов приложения", "error", err)
+	}
+
+	if ctx.Err() != nil {
+		return false
+	}
+	statusCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := state.SetState(statusCtx, runStatusKey, runStatusCompleted); err != nil {
+		d.log.Error("Ошибка сохранения статуса запуска", "schedule", schedule.Name, "error", err)
+	}
+	return true
+}
+
+// startCycle clears the resume state of the schedule after a finished run, so every run
+// processes all wallets again; the state of an interrupted run is kept to resume it.
+func (d *Daemon) startCycle(ctx context.Context, state storage.StateStorage, name string) error {
+	status, err := state.GetState(ctx, runStatusKey)
+	if err != nil && !errors.Is(err, storage.ErrStateNotFound) {
+		return err
+	}
+	if status == runStatusRunning {
+		d.log.Info("Предыдущий запуск расписания был прерван, он будет продолжен", "schedule", name)
+	} else {
+		keys, err := state.ListState(ctx)
+		if err != nil {
+			return err
+		}
+		for key := range keys {
+			if err := state.DeleteState(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+	return state.SetState(ctx, runStatusKey, runStatusRunning)
+}
@@ -0,0 +1,118 @@
+package errclass
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Error wraps an error with an explicit class. Tasks return it to override the default classification.
+type Error struct {
+	Class types.ErrorClass
+	Err   error
+}
+
+// Error returns the message of the wrapped error.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New wraps err with the given class. It returns nil for a nil error.
+func New(class types.ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Class: class, Err: err}
+}
+
+// Retryable marks err as transient: the executor retries it.
+func Retryable(err error) error {
+	return New(types.ErrorClassRetryable, err)
+}
+
+// NonRetryable marks err as permanent: the executor gives up on the task immediately.
+func NonRetryable(err error) error {
+	return New(types.ErrorClassNonRetryable, err)
+}
+
+// Fatal marks err as fatal for the whole run.
+func Fatal(err error) error {
+	return New(types.ErrorClassFatal, err)
+}
+
+// IsFatal reports whether err is classified as fatal for the run.
+func IsFatal(err error) bool {
+	return Classify(err) == types.ErrorClassFatal
+}
+
+var (
+	fatalPatterns = []string{
+		"chain id mismatch",
+		"invalid chain id",
+	}
+	nonRetryablePatterns = []string{
+		"insufficient funds",
+		"execution reverted",
+		"invalid param",
+		"invalid argument",
+		"gas required exceeds allowance",
+		"exceeds block gas limit",
+		"intrinsic gas too low",
+		"invalid sender",
+		"transaction type not supported",
+	}
+)
+
+// Classify determines the class of err. Explicitly classified errors keep their class;
+// unknown errors are treated as retryable to preserve the original retry behavior.
+func Classify(err error) types.ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return types.ErrorClassRetryable
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.StatusCode == 429 || httpErr.StatusCode >= 500 {
+			return types.ErrorClassRetryable
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return types.ErrorClassRetryable
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, pattern := range fatalPatterns {
+		if strings.Contains(message, pattern) {
+			return types.ErrorClassFatal
+		}
+	}
+	for _, pattern := range nonRetryablePatterns {
+		if strings.Contains(message, pattern) {
+			return types.ErrorClassNonRetryable
+		}
+	}
+	// Network failures, timeouts, rate limits and nonce races all end up here.
+	return types.ErrorClassRetryable
+}
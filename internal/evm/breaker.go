@@ -0,0 +1,121 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"retro/internal/logger"
+)
+
+// ErrCircuitOpen is returned for requests to a network whose circuit breaker is open.
+var ErrCircuitOpen = errors.New("RPC circuit breaker is open")
+
+// CircuitBreaker pauses all RPC traffic to a network after repeated failures.
+// After the cooldown one more request is let through: a failure opens the circuit again,
+// a success closes it. A nil *CircuitBreaker never blocks.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	networks  map[string]*breakerState
+	log       logger.Logger
+	onOpen    func(network string, until time.Time)
+}
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+}
+
+// NewCircuitBreaker creates a breaker that opens after threshold consecutive failed requests.
+func NewCircuitBreaker(threshold int, cooldown time.Duration, log logger.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		networks:  make(map[string]*breakerState),
+		log:       log,
+	}
+}
+
+// OnOpen sets a callback invoked every time the circuit of a network opens.
+func (b *CircuitBreaker) OnOpen(fn func(network string, until time.Time)) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onOpen = fn
+}
+
+// Allow returns an error wrapping ErrCircuitOpen if requests to the network are paused.
+func (b *CircuitBreaker) Allow(network string) error {
+	if until, open := b.OpenUntil(network); open {
+		return fmt.Errorf("%w для сети %s до %s", ErrCircuitOpen, network, until.Format(time.TimeOnly))
+	}
+	return nil
+}
+
+// OpenUntil reports whether the circuit of the network is open and when it closes.
+func (b *CircuitBreaker) OpenUntil(network string) (time.Time, bool) {
+	if b == nil {
+		return time.Time{}, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.networks[network]
+	if !ok || !time.Now().Before(state.openUntil) {
+		return time.Time{}, false
+	}
+	return state.openUntil, true
+}
+
+// RecordSuccess resets the failure counter of the network.
+func (b *CircuitBreaker) RecordSuccess(network string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if state, ok := b.networks[network]; ok {
+		state.failures = 0
+	}
+}
+
+// RecordFailure counts a failed request and opens the circuit once the threshold is reached.
+func (b *CircuitBreaker) RecordFailure(network string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	var onOpen func()
+	defer func() {
+		b.mu.Unlock()
+		if onOpen != nil {
+			onOpen()
+		}
+	}()
+	state, ok := b.networks[network]
+	if !ok {
+		state = &breakerState{}
+		b.networks[network] = state
+	}
+	if time.Now().Before(state.openUntil) {
+		return
+	}
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openUntil = time.Now().Add(b.cooldown)
+		// One failure after the cooldown is enough to open the circuit again.
+		state.failures = b.threshold - 1
+		b.log.Warn("RPC сети недоступен, задачи этой сети приостановлены",
+			"net", network, "cooldown", b.cooldown, "until", state.openUntil.Format(time.TimeOnly))
+		if fn, until := b.onOpen, state.openUntil; fn != nil {
+			onOpen = func() { fn(network, until) }
+		}
+	}
+}
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"net/http"
+	"strings"
 	"time"
 
 	"retro/internal/logger"
+	"retro/internal/metrics"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var (
@@ -27,49 +31,153 @@ type EVMClient interface {
 	GetChainID() *big.Int
 	GetBalance(ctx context.Context, address common.Address) (*big.Int, error)
 	GetNonce(ctx context.Context, address common.Address) (uint64, error)
+	GetConfirmedNonce(ctx context.Context, address common.Address) (uint64, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	EstimateGasLimit(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
 	SendRawTransaction(ctx context.Context, tx *types.Transaction) error
 	WaitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
 	SimulateCall(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	GetTransactionState(ctx context.Context, txHash common.Hash) (TxState, *types.Receipt, error)
+	Network() NetworkInfo
 }
 
+// TxState describes where a previously sent transaction currently is.
+type TxState string
+
+const (
+	TxStatePending TxState = "pending"
+	TxStateMined   TxState = "mined"
+	// TxStateDropped means the node knows neither a receipt nor the transaction itself.
+	TxStateDropped TxState = "dropped"
+)
+
 // Client wraps the go-ethereum client and provides helper methods.
 type Client struct {
 	*ethclient.Client
 	chainID *big.Int
+	network NetworkInfo
 	log     logger.Logger
 }
 
 // Ensure Client implements EVMClient interface at compile time.
 var _ EVMClient = (*Client)(nil)
 
-// NewClient creates a new EVM client, trying multiple RPC URLs if provided.
-func NewClient(ctx context.Context, log logger.Logger, rpcUrls []string) (*Client, error) {
+// ClientOption configures optional behaviour of a Client.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	network NetworkInfo
+	guard   *NetworkGuard
+}
+
+// WithNetwork sets the network description: the expected chain ID is verified on connect
+// and the gas caps are applied to sent transactions.
+func WithNetwork(info NetworkInfo) ClientOption {
+	return func(o *clientOptions) {
+		o.network = info
+	}
+}
+
+// WithNetworkGuard routes all HTTP requests of the client through the shared rate limiter
+// and circuit breaker of the named network.
+func WithNetworkGuard(network string, guard *NetworkGuard) ClientOption {
+	return func(o *clientOptions) {
+		o.network.Name = network
+		o.guard = guard
+	}
+}
+
+// NewClient creates a new EVM client connected to one of the RPC URLs, tried in random order.
+// An endpoint that fails or serves another chain is skipped; the chain ID mismatch is returned
+// as a fatal error only when every endpoint serves another chain.
+func NewClient(ctx context.Context, log logger.Logger, rpcUrls []string, opts ...ClientOption) (*Client, error) {
 	if len(rpcUrls) == 0 {
 		return nil, ErrNoRpcUrlsProvided
 	}
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-	rpcUrl := rpcUrls[rand.Intn(len(rpcUrls))]
-	log.Debug("Подключение к EVM ноде...", "url", rpcUrl)
+	var mismatches []error
+	var lastErr error
+	for _, i := range rand.Perm(len(rpcUrls)) {
+		client, err := connect(ctx, log, rpcUrls[i], options)
+		if err == nil {
+			return client, nil
+		}
+		if errors.Is(err, ErrChainIDMismatch) {
+			mismatches = append(mismatches, err)
+		} else {
+			lastErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return nil, errors.Join(mismatches...)
+	}
+	return nil, fmt.Errorf("%w: %w", ErrEvmClientCreationFailed, lastErr)
+}
 
-	ethClient, err := ethclient.DialContext(ctx, rpcUrl)
+// connect dials one RPC URL and checks that it serves the expected chain.
+func connect(ctx context.Context, log logger.Logger, rpcUrl string, options clientOptions) (*Client, error) {
+	log.Debug("Подключение к EVM ноде...", "url", rpcUrl)
+	ethClient, err := dial(ctx, rpcUrl, options)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrEvmClientCreationFailed, err)
+		log.Warn("Не удалось подключиться к EVM ноде", "url", rpcUrl, "error", err)
+		return nil, err
 	}
 	log.Debug("Успешное подключение к EVM ноде", "url", rpcUrl)
 
 	chainCtx, chainCancel := context.WithTimeout(ctx, 5*time.Second)
 	chainID, err := ethClient.ChainID(chainCtx)
 	chainCancel()
-	if err == nil {
-		log.Success("Подключено к EVM узлу", "url", rpcUrl, "chain_id", chainID.String())
-		return &Client{Client: ethClient, chainID: chainID, log: log}, nil
+	if err != nil {
+		log.Warn("Подключено, но не удалось получить ChainID", "url", rpcUrl, "error", err)
+		ethClient.Close()
+		return nil, err
+	}
+	if err := options.network.verifyChainID(rpcUrl, chainID); err != nil {
+		log.Error("RPC узел относится к другой сети, подключение отклонено", "url", rpcUrl,
+			"chain_id", chainID.String(), "expected", options.network.ExpectedChainID.String())
+		ethClient.Close()
+		return nil, err
+	}
+	log.Success("Подключено к EVM узлу", "url", rpcUrl, "chain_id", chainID.String())
+	return &Client{Client: ethClient, chainID: chainID, network: options.network, log: log}, nil
+}
+
+// dial connects to the node. HTTP endpoints get the network guard transport when one is
+// configured; other transports (e.g. websocket) are dialed as is.
+func dial(ctx context.Context, rpcUrl string, options clientOptions) (*ethclient.Client, error) {
+	instrumented := metrics.Enabled()
+	if (options.guard == nil && !instrumented) || !strings.HasPrefix(rpcUrl, "http") {
+		return ethclient.DialContext(ctx, rpcUrl)
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if instrumented {
+		transport = &instrumentedTransport{base: transport, network: options.network.Name, endpoint: rpcUrl}
+	}
+	if options.guard != nil {
+		transport = &guardedTransport{
+			base:     transport,
+			network:  options.network.Name,
+			endpoint: rpcUrl,
+			guard:    options.guard,
+		}
 	}
-	log.Warn("Подключено, но не удалось получить ChainID", "url", rpcUrl, "error", err)
-	ethClient.Close()
-	return nil, fmt.Errorf("%w: %w", ErrEvmClientCreationFailed, err)
+
+	rpcClient, err := rpc.DialOptions(ctx, rpcUrl, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
 }
 
 // Close terminates the underlying RPC connection
@@ -83,6 +191,11 @@ func (c *Client) GetChainID() *big.Int {
 	return c.chainID
 }
 
+// Network returns the description of the network the client is connected to.
+func (c *Client) Network() NetworkInfo {
+	return c.network
+}
+
 // GetBalance retrieves the native token balance for a given address
 func (c *Client) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
 	c.log.Debug("Запрос баланса...", "address", address.Hex())
@@ -98,9 +211,23 @@ func (c *Client) GetNonce(ctx context.Context, address common.Address) (uint64,
 	return c.Client.PendingNonceAt(ctx, address)
 }
 
-// SuggestGasPrice suggests a gas price for legacy transactions
+// GetConfirmedNonce returns the next nonce of the account at the latest block, ignoring
+// transactions still in the mempool.
+func (c *Client) GetConfirmedNonce(ctx context.Context, address common.Address) (uint64, error) {
+	return c.Client.NonceAt(ctx, address, nil)
+}
+
+// SuggestGasPrice suggests a gas price for legacy transactions.
+// A suggestion above the network cap is returned as a retryable error.
 func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
-	return c.Client.SuggestGasPrice(ctx)
+	gasPrice, err := c.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.network.checkGasPrice(gasPrice); err != nil {
+		return nil, err
+	}
+	return gasPrice, nil
 }
 
 // SuggestGasTipCap suggests a gas tip cap for EIP-1559 transactions
@@ -108,20 +235,36 @@ func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return c.Client.SuggestGasTipCap(ctx)
 }
 
-// EstimateGasLimit estimates the gas needed for a transaction
+// EstimateGasLimit estimates the gas needed for a transaction.
+// An estimate above the network cap is returned as a non-retryable error.
 func (c *Client) EstimateGasLimit(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
-	return c.Client.EstimateGas(ctx, msg)
+	gasLimit, err := c.Client.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.network.checkGasLimit(gasLimit); err != nil {
+		return 0, err
+	}
+	return gasLimit, nil
 }
 
 // SendRawTransaction sends a signed transaction to the network
 func (c *Client) SendRawTransaction(ctx context.Context, tx *types.Transaction) error {
 	c.log.Debug("Отправка подписанной транзакции", "tx_hash", tx.Hash().Hex())
+	if err := c.network.checkTransaction(tx); err != nil {
+		c.log.Error("Транзакция превышает лимиты газа сети, отправка отменена", "tx_hash", tx.Hash().Hex(), "error", err)
+		return err
+	}
 	err := c.Client.SendTransaction(ctx, tx)
 	if err != nil {
 		c.log.Error("Не удалось отправить транзакцию", "tx_hash", tx.Hash().Hex(), "error", err)
 		return fmt.Errorf("sending transaction failed: %w", err)
 	}
-	c.log.Info("Транзакция успешно отправлена", "tx_hash", tx.Hash().Hex())
+	if txURL := c.network.TxURL(tx.Hash().Hex()); txURL != "" {
+		c.log.Info("Транзакция успешно отправлена", "tx_hash", tx.Hash().Hex(), "explorer", txURL)
+	} else {
+		c.log.Info("Транзакция успешно отправлена", "tx_hash", tx.Hash().Hex())
+	}
 	return nil
 }
 
@@ -169,3 +312,42 @@ func (c *Client) SimulateCall(ctx context.Context, msg ethereum.CallMsg) ([]byte
 	c.log.Debug("Симуляция вызова контракта успешно завершена", "result_len", len(result))
 	return result, nil
 }
+
+// BlockNumber returns the number of the latest block.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	number, err := c.Client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения номера блока: %w", err)
+	}
+	return number, nil
+}
+
+// FilterLogs returns the logs matching the query (eth_getLogs).
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.log.Debug("Запрос логов (eth_getLogs)...", "from_block", query.FromBlock, "to_block", query.ToBlock)
+	logs, err := c.Client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка eth_getLogs: %w", err)
+	}
+	return logs, nil
+}
+
+// GetTransactionState reports whether a transaction is mined (with its receipt), still pending or dropped.
+func (c *Client) GetTransactionState(ctx context.Context, txHash common.Hash) (TxState, *types.Receipt, error) {
+	receipt, err := c.Client.TransactionReceipt(ctx, txHash)
+	if err == nil && receipt != nil {
+		return TxStateMined, receipt, nil
+	}
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return "", nil, fmt.Errorf("error fetching receipt: %w", err)
+	}
+
+	_, _, err = c.Client.TransactionByHash(ctx, txHash)
+	if err == nil {
+		return TxStatePending, nil, nil
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return TxStateDropped, nil, nil
+	}
+	return "", nil, fmt.Errorf("error fetching transaction: %w", err)
+}
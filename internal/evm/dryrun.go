@@ -0,0 +1,147 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"retro/internal/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DryRunClient records signed transactions instead of broadcasting them.
+// Reads, gas estimation and eth_call go to the wrapped client as usual; receipts of recorded
+// transactions are synthetic and successful. It is safe for concurrent use.
+type DryRunClient struct {
+	EVMClient
+	mu       sync.Mutex
+	recorded map[common.Hash]*types.Transaction
+	order    []common.Hash
+	// nextNonce tracks nonces used by recorded transactions, so several transactions of
+	// one task get consecutive nonces even though none of them reaches the node.
+	nextNonce map[common.Address]uint64
+	log       logger.Logger
+}
+
+// Ensure DryRunClient implements EVMClient interface at compile time.
+var _ EVMClient = (*DryRunClient)(nil)
+
+// NewDryRunClient wraps client so that nothing is sent to the network.
+func NewDryRunClient(client EVMClient, log logger.Logger) *DryRunClient {
+	return &DryRunClient{
+		EVMClient: client,
+		recorded:  make(map[common.Hash]*types.Transaction),
+		nextNonce: make(map[common.Address]uint64),
+		log:       log,
+	}
+}
+
+// GetNonce returns the node nonce, advanced past the transactions recorded in this dry run.
+func (c *DryRunClient) GetNonce(ctx context.Context, address common.Address) (uint64, error) {
+	nonce, err := c.EVMClient.GetNonce(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if next, ok := c.nextNonce[address]; ok && next > nonce {
+		return next, nil
+	}
+	return nonce, nil
+}
+
+// SendRawTransaction logs the signed transaction and records it without broadcasting.
+// The network gas caps are checked as for a real send.
+func (c *DryRunClient) SendRawTransaction(ctx context.Context, tx *types.Transaction) error {
+	if c.EVMClient != nil {
+		if err := c.EVMClient.Network().checkTransaction(tx); err != nil {
+			return err
+		}
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("dry-run: не удалось определить отправителя транзакции: %w", err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("dry-run: не удалось сериализовать транзакцию: %w", err)
+	}
+
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	fields := []interface{}{
+		"tx_hash", tx.Hash().Hex(), "from", from.Hex(), "to", to, "value_wei", tx.Value().String(),
+		"nonce", tx.Nonce(), "gas", tx.Gas(), "chain_id", tx.ChainId().String(), "type", tx.Type(),
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		fields = append(fields, "gas_price_wei", tx.GasPrice().String())
+	} else {
+		fields = append(fields, "max_fee_wei", tx.GasFeeCap().String(), "max_priority_fee_wei", tx.GasTipCap().String())
+	}
+	fields = append(fields, "data", hexutil.Encode(tx.Data()))
+	c.log.Highlight("[DRY-RUN] Транзакция подписана, но не отправлена", fields...)
+	c.log.Debug("[DRY-RUN] Подписанная транзакция", "tx_hash", tx.Hash().Hex(), "raw", hexutil.Encode(raw))
+
+	c.mu.Lock()
+	if _, ok := c.recorded[tx.Hash()]; !ok {
+		c.order = append(c.order, tx.Hash())
+	}
+	c.recorded[tx.Hash()] = tx
+	if tx.Nonce()+1 > c.nextNonce[from] {
+		c.nextNonce[from] = tx.Nonce() + 1
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// WaitForReceipt returns a synthetic successful receipt for recorded transactions.
+func (c *DryRunClient) WaitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt := c.syntheticReceipt(txHash); receipt != nil {
+		return receipt, nil
+	}
+	return c.EVMClient.WaitForReceipt(ctx, txHash)
+}
+
+// GetTransactionState reports recorded transactions as mined.
+func (c *DryRunClient) GetTransactionState(ctx context.Context, txHash common.Hash) (TxState, *types.Receipt, error) {
+	if receipt := c.syntheticReceipt(txHash); receipt != nil {
+		return TxStateMined, receipt, nil
+	}
+	return c.EVMClient.GetTransactionState(ctx, txHash)
+}
+
+// Recorded returns the transactions recorded so far, in the order they were sent.
+func (c *DryRunClient) Recorded() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	txs := make([]*types.Transaction, 0, len(c.order))
+	for _, hash := range c.order {
+		txs = append(txs, c.recorded[hash])
+	}
+	return txs
+}
+
+// syntheticReceipt builds a successful receipt that pays the full gas limit at the max fee.
+func (c *DryRunClient) syntheticReceipt(txHash common.Hash) *types.Receipt {
+	c.mu.Lock()
+	tx, ok := c.recorded[txHash]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return &types.Receipt{
+		Type:              tx.Type(),
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            txHash,
+		GasUsed:           tx.Gas(),
+		CumulativeGasUsed: tx.Gas(),
+		EffectiveGasPrice: new(big.Int).Set(tx.GasFeeCap()),
+		Logs:              []*types.Log{},
+	}
+}
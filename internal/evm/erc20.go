@@ -0,0 +1,137 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"retro/internal/errclass"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc20ABIJSON = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},
+	{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"},
+	{"constant":true,"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":false,"inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+// ERC20ABI is the parsed minimal ERC-20 ABI used by the helpers in this file.
+var ERC20ABI = mustParseABI(erc20ABIJSON)
+
+// mustParseABI parses a static ABI definition or panics.
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid static ABI: %v", err))
+	}
+	return parsed
+}
+
+// ERC20BalanceOf returns the token balance of owner in the token's smallest units.
+func ERC20BalanceOf(ctx context.Context, client EVMClient, token, owner common.Address) (*big.Int, error) {
+	var balance *big.Int
+	if err := callERC20(ctx, client, token, &balance, "balanceOf", owner); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// ERC20Decimals returns the number of decimals of the token.
+func ERC20Decimals(ctx context.Context, client EVMClient, token common.Address) (uint8, error) {
+	var decimals uint8
+	if err := callERC20(ctx, client, token, &decimals, "decimals"); err != nil {
+		return 0, err
+	}
+	return decimals, nil
+}
+
+// ERC20Symbol returns the ticker symbol of the token.
+func ERC20Symbol(ctx context.Context, client EVMClient, token common.Address) (string, error) {
+	var symbol string
+	if err := callERC20(ctx, client, token, &symbol, "symbol"); err != nil {
+		return "", err
+	}
+	return symbol, nil
+}
+
+// ERC20Allowance returns the amount spender may transfer from owner, in the token's smallest units.
+func ERC20Allowance(ctx context.Context, client EVMClient, token, owner, spender common.Address) (*big.Int, error) {
+	var allowance *big.Int
+	if err := callERC20(ctx, client, token, &allowance, "allowance", owner, spender); err != nil {
+		return nil, err
+	}
+	return allowance, nil
+}
+
+// ERC20ApproveData returns the calldata of approve(spender, amount).
+func ERC20ApproveData(spender common.Address, amount *big.Int) ([]byte, error) {
+	data, err := ERC20ABI.Pack("approve", spender, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack approve call: %w", err)
+	}
+	return data, nil
+}
+
+// ERC20TransferData returns the calldata of transfer(to, amount).
+func ERC20TransferData(to common.Address, amount *big.Int) ([]byte, error) {
+	data, err := ERC20ABI.Pack("transfer", to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack transfer call: %w", err)
+	}
+	return data, nil
+}
+
+// callERC20 performs a read-only call of an ERC-20 method and unpacks its single return value into out.
+func callERC20(ctx context.Context, client EVMClient, token common.Address, out interface{}, method string, args ...interface{}) error {
+	data, err := ERC20ABI.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s call: %w", method, err)
+	}
+
+	result, err := client.SimulateCall(ctx, ethereum.CallMsg{To: &token, Data: data})
+	if err != nil {
+		return fmt.Errorf("%s call to token %s failed: %w", method, token.Hex(), err)
+	}
+
+	values, err := ERC20ABI.Unpack(method, result)
+	if err != nil {
+		// The result is not an ERC-20 response (e.g. the address is not a token): retrying will not help.
+		return errclass.NonRetryable(fmt.Errorf("failed to unpack %s result from token %s: %w", method, token.Hex(), err))
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("unexpected %s result length from token %s: %d", method, token.Hex(), len(values))
+	}
+
+	switch target := out.(type) {
+	case **big.Int:
+		value, ok := values[0].(*big.Int)
+		if !ok {
+			return fmt.Errorf("unexpected %s result type from token %s: %T", method, token.Hex(), values[0])
+		}
+		*target = value
+	case *uint8:
+		value, ok := values[0].(uint8)
+		if !ok {
+			return fmt.Errorf("unexpected %s result type from token %s: %T", method, token.Hex(), values[0])
+		}
+		*target = value
+	case *string:
+		value, ok := values[0].(string)
+		if !ok {
+			return fmt.Errorf("unexpected %s result type from token %s: %T", method, token.Hex(), values[0])
+		}
+		*target = value
+	default:
+		return fmt.Errorf("unsupported output type %T for %s", out, method)
+	}
+	return nil
+}
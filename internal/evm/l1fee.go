@@ -0,0 +1,51 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"retro/internal/errclass"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// l1FeeBufferPercent is added on top of the L1 data fee, which follows the L1 base fee and can
+// rise before the transaction is included.
+const l1FeeBufferPercent = 25
+
+// gasPriceOracle is the OP-stack predeploy that prices the L1 data of L2 transactions.
+var gasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+var gasPriceOracleABI = mustParseABI(`[
+	{"inputs":[{"name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`)
+
+// L1DataFee returns the L1 data fee an OP-stack network charges for tx on top of gas and value,
+// with a buffer for L1 price changes. Networks without the GasPriceOracle predeploy charge none,
+// so 0 is returned for them.
+func L1DataFee(ctx context.Context, client EVMClient, tx *types.Transaction) (*big.Int, error) {
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, errclass.NonRetryable(fmt.Errorf("ошибка кодирования транзакции: %w", err))
+	}
+	data, err := gasPriceOracleABI.Pack("getL1Fee", encoded)
+	if err != nil {
+		return nil, errclass.NonRetryable(err)
+	}
+	result, err := client.SimulateCall(ctx, ethereum.CallMsg{To: &gasPriceOracle, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения L1 комиссии: %w", err)
+	}
+	if len(result) == 0 {
+		return new(big.Int), nil
+	}
+	values, err := gasPriceOracleABI.Unpack("getL1Fee", result)
+	if err != nil {
+		return nil, errclass.NonRetryable(fmt.Errorf("ошибка декодирования L1 комиссии: %w", err))
+	}
+	fee := values[0].(*big.Int)
+	return fee.Add(fee, new(big.Int).Div(new(big.Int).Mul(fee, big.NewInt(l1FeeBufferPercent)), big.NewInt(100))), nil
+}
@@ -0,0 +1,212 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"retro/internal/errclass"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultLogsBlockRange is the block range of one eth_getLogs request when the network sets none.
+const DefaultLogsBlockRange = 2000
+
+// ErrEventNotFound means the receipt holds no log of the requested event.
+var ErrEventNotFound = errors.New("event not found in receipt")
+
+// logsRangePatterns match the RPC errors returned when an eth_getLogs request covers too many
+// blocks or results; the request is repeated with a smaller range.
+var logsRangePatterns = []string{
+	"block range",
+	"range limit",
+	"range is too large",
+	"query returned more than",
+	"log response size exceeded",
+}
+
+// rateLimitPatterns match throttling errors, which must not shrink the range. A bare "429" is
+// not one: it also matches block numbers in range errors; HTTP 429 is checked by status code.
+var rateLimitPatterns = []string{
+	"429 too many",
+	"too many requests",
+	"rate limit",
+}
+
+// FilterLogs returns the logs matching the query, splitting its block range into requests of at
+// most the network's LogsBlockRange blocks. A range rejected by the RPC is halved and retried.
+// A nil ToBlock means the latest block; queries by block hash are sent as is.
+func FilterLogs(ctx context.Context, client EVMClient, query ethereum.FilterQuery) ([]types.Log, error) {
+	if query.BlockHash != nil {
+		return client.FilterLogs(ctx, query)
+	}
+	var from, to uint64
+	if query.FromBlock != nil && query.FromBlock.Sign() > 0 {
+		from = query.FromBlock.Uint64()
+	}
+	if query.ToBlock != nil && query.ToBlock.Sign() >= 0 {
+		to = query.ToBlock.Uint64()
+	} else {
+		latest, err := client.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		to = latest
+	}
+	if from > to {
+		return nil, nil
+	}
+
+	step := client.Network().LogsBlockRange
+	if step == 0 {
+		step = DefaultLogsBlockRange
+	}
+	var logs []types.Log
+	for start := from; start <= to; {
+		end := to
+		if to-start >= step {
+			end = start + step - 1
+		}
+		chunk := query
+		chunk.FromBlock = new(big.Int).SetUint64(start)
+		chunk.ToBlock = new(big.Int).SetUint64(end)
+		found, err := client.FilterLogs(ctx, chunk)
+		if err != nil {
+			if ctx.Err() != nil || end == start || !isLogsRangeError(err) {
+				return nil, err
+			}
+			step = (end - start + 1) / 2
+			continue
+		}
+		logs = append(logs, found...)
+		if end == to {
+			break
+		}
+		start = end + 1
+	}
+	return logs, nil
+}
+
+// isLogsRangeError reports whether the RPC rejected an eth_getLogs request for its size.
+// Rate limit errors are not: a smaller range would only send more requests.
+func isLogsRangeError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, pattern := range rateLimitPatterns {
+		if strings.Contains(message, pattern) {
+			return false
+		}
+	}
+	for _, pattern := range logsRangePatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnpackLog decodes a log of the named event into out, a pointer to a struct whose fields match
+// the event arguments (indexed ones included) by name, as in abi.UnpackIntoInterface.
+func UnpackLog(contractABI abi.ABI, event string, log types.Log, out interface{}) error {
+	ev, err := logEvent(contractABI, event, log)
+	if err != nil {
+		return err
+	}
+	if len(log.Data) > 0 {
+		if err := contractABI.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return errclass.NonRetryable(fmt.Errorf("ошибка декодирования события %s: %w", event, err))
+		}
+	}
+	if err := abi.ParseTopics(out, indexedArguments(ev), log.Topics[1:]); err != nil {
+		return errclass.NonRetryable(fmt.Errorf("ошибка декодирования топиков события %s: %w", event, err))
+	}
+	return nil
+}
+
+// UnpackLogIntoMap decodes a log of the named event into a map from argument name to value.
+func UnpackLogIntoMap(contractABI abi.ABI, event string, log types.Log) (map[string]interface{}, error) {
+	ev, err := logEvent(contractABI, event, log)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if len(log.Data) > 0 {
+		if err := contractABI.UnpackIntoMap(values, event, log.Data); err != nil {
+			return nil, errclass.NonRetryable(fmt.Errorf("ошибка декодирования события %s: %w", event, err))
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexedArguments(ev), log.Topics[1:]); err != nil {
+		return nil, errclass.NonRetryable(fmt.Errorf("ошибка декодирования топиков события %s: %w", event, err))
+	}
+	return values, nil
+}
+
+// FindEvents returns the logs of the named event in the receipt, in log order. A non-nil
+// emitter keeps only the logs of that contract.
+func FindEvents(receipt *types.Receipt, contractABI abi.ABI, event string, emitter *common.Address) ([]types.Log, error) {
+	ev, ok := contractABI.Events[event]
+	if !ok {
+		return nil, errclass.NonRetryable(fmt.Errorf("событие %s отсутствует в ABI", event))
+	}
+	var logs []types.Log
+	for _, log := range receipt.Logs {
+		if len(log.Topics) == 0 || log.Topics[0] != ev.ID || len(log.Topics) != len(indexedArguments(ev))+1 {
+			continue
+		}
+		if emitter != nil && log.Address != *emitter {
+			continue
+		}
+		logs = append(logs, *log)
+	}
+	return logs, nil
+}
+
+// FindEvent decodes the first log of the named event in the receipt into out (see UnpackLog) and
+// returns ErrEventNotFound when there is none. A non-nil emitter keeps only the logs of that contract.
+func FindEvent(receipt *types.Receipt, contractABI abi.ABI, event string, emitter *common.Address, out interface{}) error {
+	logs, err := FindEvents(receipt, contractABI, event, emitter)
+	if err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		return errclass.NonRetryable(fmt.Errorf("%w: %s, tx %s", ErrEventNotFound, event, receipt.TxHash.Hex()))
+	}
+	return UnpackLog(contractABI, event, logs[0], out)
+}
+
+// logEvent returns the ABI event of the log and checks that the log is one.
+func logEvent(contractABI abi.ABI, event string, log types.Log) (abi.Event, error) {
+	ev, ok := contractABI.Events[event]
+	if !ok {
+		return abi.Event{}, errclass.NonRetryable(fmt.Errorf("событие %s отсутствует в ABI", event))
+	}
+	if len(log.Topics) == 0 || log.Topics[0] != ev.ID {
+		return abi.Event{}, errclass.NonRetryable(fmt.Errorf("лог не является событием %s", event))
+	}
+	if len(log.Topics) != len(indexedArguments(ev))+1 {
+		return abi.Event{}, errclass.NonRetryable(fmt.Errorf("число топиков лога не совпадает с событием %s", event))
+	}
+	return ev, nil
+}
+
+// indexedArguments returns the indexed arguments of the event, which are stored in the topics.
+func indexedArguments(ev abi.Event) abi.Arguments {
+	var indexed abi.Arguments
+	for _, arg := range ev.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return indexed
+}
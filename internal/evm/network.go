@@ -0,0 +1,104 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"retro/internal/config"
+	"retro/internal/errclass"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrChainIDMismatch means an endpoint serves another chain than the one configured for the network.
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+	// ErrGasPriceTooHigh means the gas price exceeds the network cap; it usually passes with time.
+	ErrGasPriceTooHigh = errors.New("gas price exceeds the network cap")
+	// ErrGasLimitTooHigh means the gas limit exceeds the network cap.
+	ErrGasLimitTooHigh = errors.New("gas limit exceeds the network cap")
+)
+
+// NetworkInfo describes the network a client is connected to.
+type NetworkInfo struct {
+	Name string
+	// ExpectedChainID is the chain ID every endpoint must report (nil = not checked).
+	ExpectedChainID *big.Int
+	NativeSymbol    string
+	ExplorerURL     string
+	// MaxGasPrice caps the gas price (max fee per gas) in wei (nil = no cap).
+	MaxGasPrice *big.Int
+	// MaxGasLimit caps the gas limit of a transaction (0 = no cap).
+	MaxGasLimit uint64
+	// LogsBlockRange is the largest block range of one eth_getLogs request (0 = DefaultLogsBlockRange).
+	LogsBlockRange uint64
+}
+
+// NewNetworkInfo builds the network description from its rpc_nodes entry.
+func NewNetworkInfo(name string, network config.NetworkConfig) NetworkInfo {
+	info := NetworkInfo{
+		Name:           name,
+		NativeSymbol:   network.NativeSymbol,
+		ExplorerURL:    network.ExplorerURL,
+		MaxGasLimit:    network.MaxGasLimit,
+		LogsBlockRange: network.LogsBlockRange,
+	}
+	if network.ChainID > 0 {
+		info.ExpectedChainID = big.NewInt(network.ChainID)
+	}
+	if network.MaxGasPriceGwei > 0 {
+		info.MaxGasPrice = GweiToWei(network.MaxGasPriceGwei)
+	}
+	return info
+}
+
+// TxURL returns the explorer link of the transaction, or "" if no explorer is configured.
+func (n NetworkInfo) TxURL(txHash string) string {
+	if n.ExplorerURL == "" {
+		return ""
+	}
+	return strings.TrimRight(n.ExplorerURL, "/") + "/tx/" + txHash
+}
+
+// GweiToWei converts a gas price in gwei to wei.
+func GweiToWei(gwei float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(1e9)).Int(nil)
+	return wei
+}
+
+// verifyChainID returns a fatal error if the endpoint reports an unexpected chain ID.
+func (n NetworkInfo) verifyChainID(rpcUrl string, chainID *big.Int) error {
+	if n.ExpectedChainID == nil || n.ExpectedChainID.Cmp(chainID) == 0 {
+		return nil
+	}
+	return errclass.Fatal(fmt.Errorf("%w: RPC %s сети %s вернул chain ID %s, ожидался %s",
+		ErrChainIDMismatch, rpcUrl, n.Name, chainID, n.ExpectedChainID))
+}
+
+// checkGasPrice returns a retryable error if the gas price exceeds the cap.
+func (n NetworkInfo) checkGasPrice(gasPrice *big.Int) error {
+	if n.MaxGasPrice == nil || gasPrice == nil || gasPrice.Cmp(n.MaxGasPrice) <= 0 {
+		return nil
+	}
+	return errclass.Retryable(fmt.Errorf("%w: %s wei > %s wei (сеть %s)",
+		ErrGasPriceTooHigh, gasPrice, n.MaxGasPrice, n.Name))
+}
+
+// checkGasLimit returns a non-retryable error if the gas limit exceeds the cap.
+func (n NetworkInfo) checkGasLimit(gasLimit uint64) error {
+	if n.MaxGasLimit == 0 || gasLimit <= n.MaxGasLimit {
+		return nil
+	}
+	return errclass.NonRetryable(fmt.Errorf("%w: %d > %d (сеть %s)",
+		ErrGasLimitTooHigh, gasLimit, n.MaxGasLimit, n.Name))
+}
+
+// checkTransaction applies the gas caps to a signed transaction before it is sent.
+func (n NetworkInfo) checkTransaction(tx *types.Transaction) error {
+	if err := n.checkGasLimit(tx.Gas()); err != nil {
+		return err
+	}
+	return n.checkGasPrice(tx.GasFeeCap())
+}
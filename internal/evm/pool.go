@@ -0,0 +1,172 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/logger"
+)
+
+// dialTimeout bounds a shared dial, which does not end with the context of the caller that started it.
+const dialTimeout = 30 * time.Second
+
+// ClientKey identifies a pooled client: one connection per network.
+type ClientKey struct {
+	Network string
+}
+
+// DialFunc creates a new client for the key. It is used by ClientPool and can be replaced in tests.
+type DialFunc func(ctx context.Context, key ClientKey) (EVMClient, error)
+
+// ClientPool shares EVM clients between tasks and wallets. Clients are dialed on first use,
+// so the chain ID is fetched once per connection, and are closed together by Close. A pooled
+// client stays on the RPC URL it was dialed with: the other URLs of the network are only tried
+// when that dial fails. It is safe for concurrent use.
+type ClientPool struct {
+	mu      sync.Mutex
+	dial    DialFunc
+	clients map[ClientKey]*poolEntry
+	closed  bool
+	log     logger.Logger
+}
+
+// poolEntry holds a client being dialed or ready; ready is closed once dialing finished.
+type poolEntry struct {
+	ready  chan struct{}
+	client EVMClient
+	err    error
+}
+
+// NewClientPool creates a pool that dials clients with dial.
+func NewClientPool(dial DialFunc, log logger.Logger) *ClientPool {
+	return &ClientPool{
+		dial:    dial,
+		clients: make(map[ClientKey]*poolEntry),
+		log:     log,
+	}
+}
+
+// NewRPCDialer returns a DialFunc connecting to the RPC nodes of the network, guarded by guard.
+func NewRPCDialer(rpcNodes map[string]config.NetworkConfig, guard *NetworkGuard, log logger.Logger) DialFunc {
+	return func(ctx context.Context, key ClientKey) (EVMClient, error) {
+		network, ok := rpcNodes[key.Network]
+		if !ok || len(network.URLs) == 0 {
+			return nil, fmt.Errorf("не найдены RPC URL для сети %s", key.Network)
+		}
+		client, err := NewClient(ctx, log, network.URLs,
+			WithNetwork(NewNetworkInfo(key.Network, network)),
+			WithNetworkGuard(key.Network, guard))
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+}
+
+// Get returns the shared client for the key, dialing it if needed. Concurrent callers for
+// the same key wait for a single dial, each until its own ctx ends. A failed dial is not cached.
+// The returned client must not be closed by the caller.
+func (p *ClientPool) Get(ctx context.Context, key ClientKey) (EVMClient, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("пул EVM клиентов закрыт")
+	}
+	entry, ok := p.clients[key]
+	if !ok {
+		entry = &poolEntry{ready: make(chan struct{})}
+		p.clients[key] = entry
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		// The dial is shared by every caller of the key, so it must not fail because the first
+		// caller's context ends; each caller stops waiting on its own context below.
+		dialCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dialTimeout)
+		go func() {
+			defer cancel()
+			p.dialEntry(dialCtx, key, entry)
+		}()
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if entry.err != nil {
+		return nil, fmt.Errorf("ошибка создания EVM клиента для сети %s: %w", key.Network, entry.err)
+	}
+	return entry.client, nil
+}
+
+// dialEntry dials the client of the key and publishes the result in entry. A failed dial is
+// removed from the pool, so the next Get dials again.
+func (p *ClientPool) dialEntry(ctx context.Context, key ClientKey, entry *poolEntry) {
+	p.log.Debug("Создание EVM клиента для пула", "net", key.Network)
+	client, err := p.dial(ctx, key)
+	if err == nil {
+		client = sharedClient{client}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil && p.closed {
+		client.(sharedClient).EVMClient.Close()
+		client, err = nil, fmt.Errorf("пул EVM клиентов закрыт")
+	}
+	if err != nil && p.clients[key] == entry {
+		delete(p.clients, key)
+	}
+	entry.client, entry.err = client, err
+	close(entry.ready)
+}
+
+// Close closes all pooled clients. Get fails after Close.
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for key, entry := range p.clients {
+		select {
+		case <-entry.ready:
+			if entry.err == nil {
+				entry.client.(sharedClient).EVMClient.Close()
+			}
+		default:
+			// Still dialing: the client is closed as soon as the dial returns.
+		}
+		delete(p.clients, key)
+	}
+	p.log.Debug("Пул EVM клиентов закрыт")
+	return nil
+}
+
+// sharedClient protects a pooled client from being closed by one of its users.
+type sharedClient struct {
+	EVMClient
+}
+
+// Close does nothing: pooled clients are closed by ClientPool.Close.
+func (sharedClient) Close() {}
+
+// ClientSource returns the client of a network; it lets a task reach networks other than its own.
+type ClientSource func(ctx context.Context, network string) (EVMClient, error)
+
+type clientSourceKey struct{}
+
+// WithClientSource returns a context carrying the source of clients for the tasks run with it.
+func WithClientSource(ctx context.Context, source ClientSource) context.Context {
+	return context.WithValue(ctx, clientSourceKey{}, source)
+}
+
+// ClientFromContext returns the client of the network from the source carried by ctx.
+func ClientFromContext(ctx context.Context, network string) (EVMClient, error) {
+	source, ok := ctx.Value(clientSourceKey{}).(ClientSource)
+	if !ok {
+		return nil, fmt.Errorf("клиент сети %s недоступен: источник клиентов не задан", network)
+	}
+	return source(ctx, network)
+}
@@ -0,0 +1,71 @@
+package evm
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter throttles RPC requests per network and per endpoint URL.
+// One limiter is shared by all clients, so parallel wallets draw from the same budget.
+// A nil *RateLimiter does not limit anything.
+type RateLimiter struct {
+	mu          sync.Mutex
+	defaultRPS  float64
+	networkRPS  map[string]float64
+	endpointRPS map[string]float64
+	limiters    map[string]*rate.Limiter
+}
+
+// NewRateLimiter creates a limiter. defaultRPS applies to networks without their own limit;
+// a limit of 0 means unlimited. Endpoint limits apply in addition to the network limit.
+func NewRateLimiter(defaultRPS float64, networkRPS, endpointRPS map[string]float64) *RateLimiter {
+	return &RateLimiter{
+		defaultRPS:  defaultRPS,
+		networkRPS:  networkRPS,
+		endpointRPS: endpointRPS,
+		limiters:    make(map[string]*rate.Limiter),
+	}
+}
+
+// Wait blocks until a request to the endpoint of the network is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, network, endpoint string) error {
+	if l == nil {
+		return nil
+	}
+
+	rps, ok := l.networkRPS[network]
+	if !ok {
+		rps = l.defaultRPS
+	}
+	if limiter := l.limiter("network:"+network, rps); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if limiter := l.limiter("endpoint:"+endpoint, l.endpointRPS[endpoint]); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limiter returns the token bucket for the key, creating it on first use. It returns nil for rps <= 0.
+func (l *RateLimiter) limiter(key string, rps float64) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		// Burst equals one second of requests, so short spikes are allowed but the average holds.
+		limiter = rate.NewLimiter(rate.Limit(rps), int(math.Max(1, math.Ceil(rps))))
+		l.limiters[key] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,110 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BroadcastHooks are notified by TrackingClient about the lifecycle of sent transactions.
+type BroadcastHooks struct {
+	// BeforeSend is called with the signed transaction before it is handed to the node.
+	// Returning an error aborts sending.
+	BeforeSend func(ctx context.Context, tx *types.Transaction) error
+	// OnReceipt is called for every receipt obtained through WaitForReceipt.
+	OnReceipt func(ctx context.Context, receipt *types.Receipt)
+}
+
+// TrackingClient wraps an EVMClient and records the transactions sent through it,
+// so an interrupted attempt can tell which transactions are still in flight.
+type TrackingClient struct {
+	EVMClient
+	hooks BroadcastHooks
+
+	mu       sync.Mutex
+	inFlight map[common.Hash]*types.Transaction
+	sent     []common.Hash
+}
+
+// NewTrackingClient wraps client with broadcast tracking.
+func NewTrackingClient(client EVMClient, hooks BroadcastHooks) *TrackingClient {
+	return &TrackingClient{
+		EVMClient: client,
+		hooks:     hooks,
+		inFlight:  make(map[common.Hash]*types.Transaction),
+	}
+}
+
+// SendRawTransaction notifies the hooks and sends the transaction. The transaction counts as
+// in flight even if sending reports an error, since the node may have accepted it anyway.
+func (t *TrackingClient) SendRawTransaction(ctx context.Context, tx *types.Transaction) error {
+	if t.hooks.BeforeSend != nil {
+		if err := t.hooks.BeforeSend(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	t.inFlight[tx.Hash()] = tx
+	t.sent = append(t.sent, tx.Hash())
+	t.mu.Unlock()
+
+	return t.EVMClient.SendRawTransaction(ctx, tx)
+}
+
+// WaitForReceipt waits for the receipt and marks the transaction as no longer in flight.
+func (t *TrackingClient) WaitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := t.EVMClient.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	delete(t.inFlight, txHash)
+	t.mu.Unlock()
+
+	if t.hooks.OnReceipt != nil {
+		t.hooks.OnReceipt(ctx, receipt)
+	}
+	return receipt, nil
+}
+
+// InFlight returns the transactions sent without an observed receipt.
+func (t *TrackingClient) InFlight() []*types.Transaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	txs := make([]*types.Transaction, 0, len(t.inFlight))
+	for _, hash := range t.sent {
+		if tx, ok := t.inFlight[hash]; ok {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// MarkResolved removes transactions whose final state was determined elsewhere from the in-flight set.
+func (t *TrackingClient) MarkResolved(hashes ...common.Hash) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, hash := range hashes {
+		delete(t.inFlight, hash)
+	}
+}
+
+// Sent returns the hashes of all transactions sent through the client, in order.
+func (t *TrackingClient) Sent() []common.Hash {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]common.Hash(nil), t.sent...)
+}
+
+// ReceiptFee returns the fee paid for a mined transaction.
+func ReceiptFee(receipt *types.Receipt) *big.Int {
+	if receipt == nil || receipt.EffectiveGasPrice == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+}
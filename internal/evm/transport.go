@@ -0,0 +1,79 @@
+package evm
+
+import (
+	"net/http"
+	"time"
+
+	"retro/internal/metrics"
+)
+
+// NetworkGuard bundles the shared RPC rate limiter and circuit breaker.
+// Either field may be nil to disable that protection.
+type NetworkGuard struct {
+	Limiter *RateLimiter
+	Breaker *CircuitBreaker
+}
+
+// Allow returns an error wrapping ErrCircuitOpen if the network is paused by the breaker.
+func (g *NetworkGuard) Allow(network string) error {
+	if g == nil {
+		return nil
+	}
+	return g.Breaker.Allow(network)
+}
+
+// guardedTransport applies the network guard to every HTTP request of an RPC client.
+type guardedTransport struct {
+	base     http.RoundTripper
+	network  string
+	endpoint string
+	guard    *NetworkGuard
+}
+
+// RoundTrip waits for the rate limiter, fails fast while the circuit is open and
+// reports the outcome of the request to the breaker.
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.Breaker.Allow(t.network); err != nil {
+		return nil, err
+	}
+	if err := t.guard.Limiter.Wait(req.Context(), t.network, t.endpoint); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		// A cancelled request says nothing about the health of the node.
+		if req.Context().Err() == nil {
+			t.guard.Breaker.RecordFailure(t.network)
+		}
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		t.guard.Breaker.RecordFailure(t.network)
+	} else {
+		t.guard.Breaker.RecordSuccess(t.network)
+	}
+	return resp, nil
+}
+
+// instrumentedTransport records the latency and failures of RPC requests in the metrics.
+type instrumentedTransport struct {
+	base     http.RoundTripper
+	network  string
+	endpoint string
+}
+
+// RoundTrip times the request; transport errors, HTTP 429 and 5xx count as failures.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		if req.Context().Err() == nil {
+			metrics.RPCRequest(t.network, t.endpoint, time.Since(start), true)
+		}
+		return nil, err
+	}
+	failed := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	metrics.RPCRequest(t.network, t.endpoint, time.Since(start), failed)
+	return resp, nil
+}
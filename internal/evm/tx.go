@@ -0,0 +1,159 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"retro/internal/errclass"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrTxReverted is returned when a sent transaction is mined with a failed status.
+var ErrTxReverted = errors.New("transaction reverted")
+
+// gasLimitBufferPercent is added on top of the estimated gas limit.
+const gasLimitBufferPercent = 20
+
+// BuildTx prepares an unsigned EIP-1559 transaction from the signer's address: nonce, gas limit
+// (estimate plus a buffer) and fees (tip cap plus twice the suggested base price) are filled in.
+func BuildTx(ctx context.Context, client EVMClient, from common.Address, to common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	nonce, err := client.GetNonce(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения nonce: %w", err)
+	}
+	gasLimit, err := client.EstimateGasLimit(ctx, ethereum.CallMsg{From: from, To: &to, Value: value, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка оценки газа: %w", err)
+	}
+	gasLimit += gasLimit * gasLimitBufferPercent / 100
+	tipCap, feeCap, err := suggestFees(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   client.GetChainID(),
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	}), nil
+}
+
+// BuildSweepTx prepares a transaction sending the whole native balance of from to to, less the
+// maximum fee: the exact gas estimate for the swept amount, without a buffer, times the max fee
+// per gas. Only the difference between the max fee and the effective gas price is left in the
+// wallet. On OP-stack networks the L1 data fee is subtracted as well. It returns nil when the
+// balance does not cover the fee.
+func BuildSweepTx(ctx context.Context, client EVMClient, from common.Address, to common.Address) (*types.Transaction, error) {
+	balance, err := client.GetBalance(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения баланса: %w", err)
+	}
+	nonce, err := client.GetNonce(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения nonce: %w", err)
+	}
+	tipCap, feeCap, err := suggestFees(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	sweepTx := func(gasLimit uint64, value *big.Int) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   client.GetChainID(),
+			Nonce:     nonce,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Gas:       gasLimit,
+			To:        &to,
+			Value:     value,
+		})
+	}
+	gasFee := func(gasLimit uint64) *big.Int {
+		return new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), feeCap)
+	}
+
+	// The estimate depends on the value and the value on the fee: a draft amount is taken from
+	// an estimate without value, then the gas is estimated again for that amount.
+	gasLimit, err := client.EstimateGasLimit(ctx, ethereum.CallMsg{From: from, To: &to, Value: big.NewInt(0)})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка оценки газа: %w", err)
+	}
+	draft := new(big.Int).Sub(balance, gasFee(gasLimit))
+	if draft.Sign() <= 0 {
+		return nil, nil
+	}
+	l1Fee, err := L1DataFee(ctx, client, sweepTx(gasLimit, draft))
+	if err != nil {
+		return nil, err
+	}
+	draft.Sub(draft, l1Fee)
+	if draft.Sign() <= 0 {
+		return nil, nil
+	}
+	if gasLimit, err = client.EstimateGasLimit(ctx, ethereum.CallMsg{From: from, To: &to, Value: draft}); err != nil {
+		return nil, fmt.Errorf("ошибка оценки газа: %w", err)
+	}
+
+	value := new(big.Int).Sub(balance, gasFee(gasLimit))
+	value.Sub(value, l1Fee)
+	if value.Sign() <= 0 {
+		return nil, nil
+	}
+	return sweepTx(gasLimit, value), nil
+}
+
+// suggestFees returns the tip cap and the max fee per gas: twice the suggested gas price plus the tip.
+func suggestFees(ctx context.Context, client EVMClient) (tipCap *big.Int, feeCap *big.Int, err error) {
+	tipCap, err = client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка получения priority fee: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка получения цены газа: %w", err)
+	}
+	return tipCap, new(big.Int).Add(new(big.Int).Mul(gasPrice, big.NewInt(2)), tipCap), nil
+}
+
+// SendTx builds, signs and sends a transaction, then waits for its receipt.
+// A reverted transaction is returned together with its receipt and ErrTxReverted.
+func SendTx(ctx context.Context, client EVMClient, signer *Signer, to common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	tx, err := BuildTx(ctx, client, signer.Address(), to, value, data)
+	if err != nil {
+		return nil, err
+	}
+	return SignAndSendTx(ctx, client, signer, tx)
+}
+
+// SignAndSendTx signs and sends a prepared transaction, then waits for its receipt.
+// A reverted transaction is returned together with its receipt and ErrTxReverted.
+func SignAndSendTx(ctx context.Context, client EVMClient, signer *Signer, tx *types.Transaction) (*types.Receipt, error) {
+	signedTx, err := signer.SignTx(tx, client.GetChainID())
+	if err != nil {
+		return nil, errclass.NonRetryable(err)
+	}
+	if err := client.SendRawTransaction(ctx, signedTx); err != nil {
+		return nil, err
+	}
+	receipt, err := client.WaitForReceipt(ctx, signedTx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, errclass.NonRetryable(fmt.Errorf("%w: %s", ErrTxReverted, signedTx.Hash().Hex()))
+	}
+	return receipt, nil
+}
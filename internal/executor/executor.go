@@ -2,38 +2,58 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"retro/internal/config"
+	"retro/internal/errclass"
 	"retro/internal/evm"
 	"retro/internal/logger"
+	"retro/internal/metrics"
+	"retro/internal/runctx"
+	"retro/internal/storage"
 	"retro/internal/tasks"
+	"retro/internal/types"
 	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // Executor is responsible for executing a single task with retries logic.
 type Executor struct {
-	cfg *config.Config
-	log logger.Logger
+	cfg      *config.Config
+	txLogger storage.TransactionLogger
+	log      logger.Logger
 }
 
 // NewExecutor creates a new Executor instance.
-func NewExecutor(cfg *config.Config, log logger.Logger) *Executor {
+func NewExecutor(cfg *config.Config, txLogger storage.TransactionLogger, log logger.Logger) *Executor {
 	return &Executor{
-		cfg: cfg,
-		log: log,
+		cfg:      cfg,
+		txLogger: txLogger,
+		log:      log,
 	}
 }
 
 // ExecuteTaskWithRetries executes a single task with retries logic.
+// Only retryable errors are retried; non-retryable and fatal errors end the task immediately.
+// Transactions sent by the task are tracked under idempotencyKey: before a retry, transactions
+// still in flight are waited for instead of running the task again. A nil client means the task
+// does not use the network.
 func (e *Executor) ExecuteTaskWithRetries(
 	ctx context.Context,
 	signer *evm.Signer,
 	client evm.EVMClient,
 	taskEntry config.TaskConfigEntry,
 	runner tasks.TaskRunner,
-) error {
-	var taskErr error
+	idempotencyKey string,
+) (ExecutionResult, error) {
+	var (
+		taskErr error
+		result  ExecutionResult
+		tracker *evm.TrackingClient
+		pending []common.Hash
+	)
 	success := false
 	maxAttempts := e.cfg.Delay.BetweenRetries.Attempts
 	if maxAttempts <= 0 {
@@ -41,19 +61,88 @@ func (e *Executor) ExecuteTaskWithRetries(
 	}
 	walletAddress := signer.Address()
 
+	// Dry-run transactions are never broadcast, so there is nothing to track or resume.
+	if client != nil && !e.cfg.DryRun {
+		tracker = e.newTrackingClient(client, walletAddress, taskEntry, idempotencyKey)
+		client = tracker
+
+		loaded, err := e.loadPendingBroadcasts(ctx, idempotencyKey)
+		if err != nil {
+			e.log.Error("Ошибка загрузки незавершенных транзакций задачи", "task", taskEntry.Name,
+				"err", err, "wallet", walletAddress.Hex())
+			return result, err
+		}
+		pending = loaded
+		for _, hash := range loaded {
+			result.TxHashes = append(result.TxHashes, hash.Hex())
+		}
+	}
+
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			metrics.TaskRetried(taskEntry.Name, taskEntry.Network)
+		}
 		e.log.Debug(
 			"Попытка выполнения задачи", "task", taskEntry.Name,
 			"attempt", attempt, "wallet", walletAddress.Hex())
-		taskErr = runner.Run(ctx, signer, client, taskEntry.Params)
+
+		if len(pending) > 0 {
+			// The task is rerun once its in-flight transactions settle: it reads the state they
+			// left and sends only what is still missing.
+			resolveErr := e.resolveInFlight(ctx, client, pending, taskEntry, walletAddress)
+			if resolveErr != nil {
+				taskErr = resolveErr
+			} else {
+				tracker.MarkResolved(pending...)
+				pending = nil
+			}
+		}
+
+		if len(pending) == 0 {
+			runctx.StepFromContext(ctx).Reset()
+			sentBefore := 0
+			if tracker != nil {
+				sentBefore = len(tracker.Sent())
+			}
+			taskErr = runner.Run(ctx, signer, client, taskEntry.Params)
+			if tracker != nil {
+				for _, hash := range tracker.Sent()[sentBefore:] {
+					result.TxHashes = append(result.TxHashes, hash.Hex())
+				}
+			}
+		}
 		if taskErr == nil {
 			success = true
 			e.log.SuccessWithBlankLine("Задача успешно выполнена", "task", taskEntry.Name,
 				"attempt", attempt, "wallet", walletAddress.Hex())
 			break
 		}
+		if tracker != nil && len(pending) == 0 {
+			for _, tx := range tracker.InFlight() {
+				pending = append(pending, tx.Hash())
+			}
+		}
+		if ctx.Err() != nil {
+			e.log.Warn("Выполнение задачи прервано (контекст отменен)", "task", taskEntry.Name,
+				"attempt", attempt, "err", taskErr, "wallet", walletAddress.Hex())
+			return result, taskErr
+		}
+		if errors.Is(taskErr, evm.ErrCircuitOpen) {
+			e.log.Warn("RPC сети приостановлен, повторные попытки отложены", "task", taskEntry.Name,
+				"attempt", attempt, "net", taskEntry.Network, "err", taskErr, "wallet", walletAddress.Hex())
+			return result, taskErr
+		}
+
+		errClass := errclass.Classify(taskErr)
+		if errClass != types.ErrorClassRetryable {
+			e.log.Warn("Ошибка задачи не подлежит повтору", "task", taskEntry.Name,
+				"attempt", attempt, "class", errClass,
+				"err", taskErr, "wallet", walletAddress.Hex())
+			break
+		}
+
 		e.log.Warn("Ошибка выполнения задачи, попытка повтора", "task", taskEntry.Name,
-			"attempt", attempt, "maxAttempts", maxAttempts,
+			"attempt", attempt, "maxAttempts", maxAttempts, "class", errClass,
 			"err", taskErr, "wallet", walletAddress.Hex())
 
 		if attempt < maxAttempts {
@@ -62,6 +151,7 @@ func (e *Executor) ExecuteTaskWithRetries(
 				e.log.Error("Ошибка получения времени задержки между попытками",
 					"err", delayErr, "wallet", walletAddress.Hex())
 			} else {
+				retryDelayDuration = utils.BackoffDuration(retryDelayDuration, attempt, e.cfg.Delay.BetweenRetries.Backoff)
 				e.log.Info("Пауза перед следующей попыткой",
 					"duration", retryDelayDuration, "wallet", walletAddress.Hex())
 				select {
@@ -69,7 +159,7 @@ func (e *Executor) ExecuteTaskWithRetries(
 				case <-ctx.Done():
 					e.log.Warn("Задержка между попытками прервана (контекст отменен)",
 						"task", taskEntry.Name, "wallet", walletAddress.Hex())
-					return taskErr
+					return result, taskErr
 				}
 			}
 		}
@@ -77,7 +167,10 @@ func (e *Executor) ExecuteTaskWithRetries(
 
 	if !success {
 		e.log.ErrorWithBlankLine("Задача не выполнена после всех попыток", "task", taskEntry.Name,
-			"err", taskErr, "wallet", walletAddress.Hex())
+			"class", errclass.Classify(taskErr), "err", taskErr, "wallet", walletAddress.Hex())
+		if errclass.IsFatal(taskErr) {
+			return result, taskErr
+		}
 		if e.cfg.Delay.AfterError.Min > 0 || e.cfg.Delay.AfterError.Max > 0 {
 			afterErrorDelay, delayErr := utils.RandomDuration(e.cfg.Delay.AfterError)
 			if delayErr != nil {
@@ -91,11 +184,11 @@ func (e *Executor) ExecuteTaskWithRetries(
 				case <-ctx.Done():
 					e.log.Warn("Задержка после ошибки прервана (контекст отменен)",
 						"task", taskEntry.Name, "wallet", walletAddress.Hex())
-					return taskErr
+					return result, taskErr
 				}
 			}
 		}
 	}
 
-	return taskErr
+	return result, taskErr
 }
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/metrics"
+	"retro/internal/storage"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// ExecutionResult describes the on-chain effects of an executed task.
+type ExecutionResult struct {
+	// TxHashes lists the transactions sent or resumed for the task, in order.
+	TxHashes []string
+}
+
+// LastTxHash returns the hash of the last transaction of the task, or "".
+func (r ExecutionResult) LastTxHash() string {
+	if len(r.TxHashes) == 0 {
+		return ""
+	}
+	return r.TxHashes[len(r.TxHashes)-1]
+}
+
+// newTrackingClient wraps the task client so every broadcast is written to the DB before sending
+// and its final status is recorded once a receipt is seen.
+func (e *Executor) newTrackingClient(
+	client evm.EVMClient,
+	walletAddress common.Address,
+	taskEntry config.TaskConfigEntry,
+	idempotencyKey string,
+) *evm.TrackingClient {
+	return evm.NewTrackingClient(client, evm.BroadcastHooks{
+		BeforeSend: func(ctx context.Context, tx *ethtypes.Transaction) error {
+			record := storage.BroadcastRecord{
+				TxHash:         tx.Hash().Hex(),
+				IdempotencyKey: idempotencyKey,
+				Timestamp:      time.Now().Truncate(time.Second),
+				WalletAddress:  walletAddress.Hex(),
+				Network:        taskEntry.Network,
+				Nonce:          tx.Nonce(),
+				ValueWei:       tx.Value().String(),
+				Status:         types.BroadcastPending,
+			}
+			if tx.To() != nil {
+				record.To = tx.To().Hex()
+			}
+			saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := e.txLogger.SaveBroadcast(saveCtx, record); err != nil {
+				return errclass.Retryable(fmt.Errorf("транзакция не отправлена: не удалось сохранить запись о ней: %w", err))
+			}
+			return nil
+		},
+		OnReceipt: func(ctx context.Context, receipt *ethtypes.Receipt) {
+			e.recordReceipt(taskEntry.Network, receipt)
+		},
+	})
+}
+
+// recordReceipt stores the final status and fee of a mined broadcast and adds the fee to the
+// gas spent on the network.
+func (e *Executor) recordReceipt(network string, receipt *ethtypes.Receipt) {
+	status := types.BroadcastMined
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		status = types.BroadcastReverted
+	}
+	fee := evm.ReceiptFee(receipt)
+	metrics.GasSpent(network, fee)
+	e.updateBroadcast(receipt.TxHash, status, fee.String())
+}
+
+// updateBroadcast writes a broadcast status change, logging failures.
+func (e *Executor) updateBroadcast(txHash common.Hash, status types.BroadcastStatus, feeWei string) {
+	updateCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := e.txLogger.UpdateBroadcastStatus(updateCtx, txHash.Hex(), status, feeWei); err != nil {
+		e.log.Error("Не удалось обновить статус транзакции в БД", "tx_hash", txHash.Hex(), "error", err)
+	}
+}
+
+// loadPendingBroadcasts returns transactions of the task step that an earlier process sent
+// without observing a receipt.
+func (e *Executor) loadPendingBroadcasts(ctx context.Context, idempotencyKey string) ([]common.Hash, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+	records, err := e.txLogger.ListBroadcasts(ctx, storage.BroadcastFilter{
+		IdempotencyKey: idempotencyKey,
+		Status:         types.BroadcastPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить незавершенные транзакции задачи: %w", err)
+	}
+	hashes := make([]common.Hash, 0, len(records))
+	for _, record := range records {
+		hashes = append(hashes, common.HexToHash(record.TxHash))
+	}
+	return hashes, nil
+}
+
+// resolveInFlight checks transactions left in flight by an earlier attempt: pending ones are
+// waited for instead of being sent again, so the task rerun afterwards sees their effects. A mined
+// transaction does not mean the task is complete: it may be one step of several.
+func (e *Executor) resolveInFlight(
+	ctx context.Context,
+	client evm.EVMClient,
+	hashes []common.Hash,
+	taskEntry config.TaskConfigEntry,
+	walletAddress common.Address,
+) error {
+	for _, hash := range hashes {
+		state, receipt, err := client.GetTransactionState(ctx, hash)
+		if err != nil {
+			return fmt.Errorf("не удалось проверить статус ранее отправленной транзакции %s: %w", hash.Hex(), err)
+		}
+
+		switch state {
+		case evm.TxStatePending:
+			e.log.Info("Ранее отправленная транзакция еще в мемпуле, ожидаем ее вместо повторной отправки",
+				"task", taskEntry.Name, "tx_hash", hash.Hex(), "wallet", walletAddress.Hex())
+			receipt, err = client.WaitForReceipt(ctx, hash)
+			if err != nil {
+				return fmt.Errorf("ожидание ранее отправленной транзакции %s: %w", hash.Hex(), err)
+			}
+			fallthrough
+		case evm.TxStateMined:
+			e.recordReceipt(taskEntry.Network, receipt)
+			if receipt.Status == ethtypes.ReceiptStatusSuccessful {
+				e.log.Info("Ранее отправленная транзакция подтверждена, повторная отправка не требуется",
+					"task", taskEntry.Name, "tx_hash", hash.Hex(), "wallet", walletAddress.Hex())
+			} else {
+				e.log.Warn("Ранее отправленная транзакция завершилась revert",
+					"task", taskEntry.Name, "tx_hash", hash.Hex(), "wallet", walletAddress.Hex())
+			}
+		case evm.TxStateDropped:
+			e.log.Warn("Ранее отправленная транзакция не найдена в сети (отброшена), задача будет выполнена заново",
+				"task", taskEntry.Name, "tx_hash", hash.Hex(), "wallet", walletAddress.Hex())
+			e.updateBroadcast(hash, types.BroadcastDropped, "")
+		}
+	}
+	return nil
+}
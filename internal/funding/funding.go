@@ -0,0 +1,291 @@
+// Package funding tops up farm wallets from a master wallet: every wallet receives only the
+// shortfall to a random target balance, in random order and with randomized amounts.
+package funding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/keyloader"
+	"retro/internal/logger"
+	"retro/internal/storage"
+	"retro/internal/types"
+	"retro/internal/utils"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// TaskName is the task name of funding transfers in the transaction log.
+const TaskName types.TaskName = "fund"
+
+// Precision bounds of the randomized amounts, in decimal places of the native coin.
+const (
+	minPrecision = 3
+	maxPrecision = 6
+)
+
+// rnd is the source of random targets, amounts and order.
+var rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// Target is the range of the balance a wallet is topped up to, in wei.
+type Target struct {
+	Min *big.Int
+	Max *big.Int
+}
+
+// Transfer is a planned top-up of one farm wallet.
+type Transfer struct {
+	// Index is the wallet's position in the keys file.
+	Index   int
+	Wallet  *keyloader.LoadedKey
+	Balance *big.Int
+	Target  *big.Int
+	Amount  *big.Int
+	// Fee is the maximum fee of the transfer (gas limit times max fee per gas).
+	Fee *big.Int
+}
+
+// Plan is the list of transfers in the order they are sent.
+type Plan struct {
+	Transfers []Transfer
+	// Skipped counts the wallets whose balance is already within or above the target range.
+	Skipped int
+}
+
+// Amount returns the total amount sent to the wallets.
+func (p *Plan) Amount() *big.Int {
+	total := new(big.Int)
+	for _, t := range p.Transfers {
+		total.Add(total, t.Amount)
+	}
+	return total
+}
+
+// Fees returns the maximum total fee of the transfers.
+func (p *Plan) Fees() *big.Int {
+	total := new(big.Int)
+	for _, t := range p.Transfers {
+		total.Add(total, t.Fee)
+	}
+	return total
+}
+
+// Cost returns the amount plus the fees, the most the master wallet can spend.
+func (p *Plan) Cost() *big.Int {
+	return new(big.Int).Add(p.Amount(), p.Fees())
+}
+
+// BuildPlan reads the balance of every wallet and plans a transfer of the shortfall to a random
+// balance within target for the wallets below target.Min, so running it again after a funding
+// sends nothing. indexes are the positions of wallets in the keys file. The wallets
+// are shuffled, so transfers are not sent in the order of the keys file.
+func BuildPlan(
+	ctx context.Context,
+	client evm.EVMClient,
+	master *evm.Signer,
+	wallets []*keyloader.LoadedKey,
+	indexes []int,
+	target Target,
+) (*Plan, error) {
+	if err := checkNoPendingTransactions(ctx, client, master); err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	for i, wallet := range wallets {
+		if wallet.Address == master.Address() {
+			continue
+		}
+		balance, err := client.GetBalance(ctx, wallet.Address)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения баланса %s: %w", wallet.Address.Hex(), err)
+		}
+		if balance.Cmp(target.Min) >= 0 {
+			plan.Skipped++
+			continue
+		}
+		walletTarget := randomTarget(target)
+		amount := randomizeAmount(new(big.Int).Sub(walletTarget, balance), balance, target)
+
+		// The gas of a plain transfer does not depend on the value, so the estimate does not
+		// fail before the master wallet is checked against the total cost.
+		tx, err := evm.BuildTx(ctx, client, master.Address(), wallet.Address, big.NewInt(0), nil)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка оценки комиссии перевода на %s: %w", wallet.Address.Hex(), err)
+		}
+		plan.Transfers = append(plan.Transfers, Transfer{
+			Index:   indexes[i],
+			Wallet:  wallet,
+			Balance: balance,
+			Target:  walletTarget,
+			Amount:  amount,
+			Fee:     new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap()),
+		})
+	}
+	rnd.Shuffle(len(plan.Transfers), func(i, j int) {
+		plan.Transfers[i], plan.Transfers[j] = plan.Transfers[j], plan.Transfers[i]
+	})
+	return plan, nil
+}
+
+// checkNoPendingTransactions refuses to plan while transactions of the master wallet are still in
+// the mempool: the balances do not include them yet, so their transfers would be planned again.
+func checkNoPendingTransactions(ctx context.Context, client evm.EVMClient, master *evm.Signer) error {
+	pending, err := client.GetNonce(ctx, master.Address())
+	if err != nil {
+		return fmt.Errorf("ошибка получения nonce мастер-кошелька: %w", err)
+	}
+	confirmed, err := client.GetConfirmedNonce(ctx, master.Address())
+	if err != nil {
+		return fmt.Errorf("ошибка получения nonce мастер-кошелька: %w", err)
+	}
+	if pending > confirmed {
+		return fmt.Errorf("у мастер-кошелька %d неподтвержденных транзакций, дождитесь их подтверждения перед новым пополнением",
+			pending-confirmed)
+	}
+	return nil
+}
+
+// randomTarget picks a random balance within the target range.
+func randomTarget(target Target) *big.Int {
+	spread := new(big.Int).Sub(target.Max, target.Min)
+	if spread.Sign() <= 0 {
+		return new(big.Int).Set(target.Min)
+	}
+	offset := new(big.Int).Rand(rnd, new(big.Int).Add(spread, big.NewInt(1)))
+	return offset.Add(offset, target.Min)
+}
+
+// randomizeAmount rounds the shortfall up to a random number of decimal places, so amounts
+// look like manual transfers; it is rounded down instead when rounding up would take the
+// balance above target.Max, and left as is when rounding down would leave the balance below
+// target.Min.
+func randomizeAmount(shortfall, balance *big.Int, target Target) *big.Int {
+	precision := minPrecision + rnd.Intn(maxPrecision-minPrecision+1)
+	step := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(18-precision)), nil)
+
+	rem := new(big.Int).Mod(shortfall, step)
+	if rem.Sign() == 0 {
+		return shortfall
+	}
+	up := new(big.Int).Add(new(big.Int).Sub(shortfall, rem), step)
+	if new(big.Int).Add(balance, up).Cmp(target.Max) <= 0 {
+		return up
+	}
+	if down := new(big.Int).Sub(shortfall, rem); down.Sign() > 0 && new(big.Int).Add(balance, down).Cmp(target.Min) >= 0 {
+		return down
+	}
+	return shortfall
+}
+
+// Sender executes a funding plan from the master wallet.
+type Sender struct {
+	Client   evm.EVMClient
+	Master   *evm.Signer
+	TxLogger storage.TransactionLogger
+	Log      logger.Logger
+	// Delay returns the pause before each transfer but the first.
+	Delay func() time.Duration
+}
+
+// Execute sends the transfers of the plan one by one and records each in the transaction log
+// under the master wallet. A failed transfer does not stop the others; the number of failed
+// transfers is returned. Transfers are not resumed: running the funding again sends only the
+// remaining shortfall once the pending transfers are mined.
+func (s *Sender) Execute(ctx context.Context, plan *Plan) (int, error) {
+	failed := 0
+	network := s.Client.Network()
+	for i, transfer := range plan.Transfers {
+		if i > 0 && s.Delay != nil {
+			delay := s.Delay()
+			s.Log.Info("Пауза перед следующим переводом", "duration", delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return failed, ctx.Err()
+			}
+		}
+
+		to := transfer.Wallet.Address
+		s.Log.Info("Отправка перевода", "network", network.Name, "to", to.Hex(),
+			"amount", utils.FromWei(transfer.Amount), "symbol", network.NativeSymbol, "n", i+1, "total", len(plan.Transfers))
+		txHash, status, err := s.send(ctx, transfer)
+		s.record(transfer, txHash, status, err)
+		if ctx.Err() != nil {
+			return failed, ctx.Err()
+		}
+		if status == types.TxStatusPending {
+			failed++
+			s.Log.Warn("Квитанция перевода не получена, перевод может быть еще выполнен", "to", to.Hex(),
+				"tx_hash", txHash, "error", err)
+			continue
+		}
+		if err != nil {
+			failed++
+			s.Log.Error("Перевод не выполнен", "to", to.Hex(), "tx_hash", txHash, "error", err)
+			continue
+		}
+		s.Log.Success("Перевод выполнен", "to", to.Hex(), "tx_hash", txHash)
+	}
+	return failed, nil
+}
+
+// send signs and sends one transfer and waits for its receipt; the hash is returned once the
+// transaction was handed to the node. The status is Pending when no receipt was seen, since the
+// transfer can still be mined.
+func (s *Sender) send(ctx context.Context, transfer Transfer) (string, types.TxStatus, error) {
+	tx, err := evm.BuildTx(ctx, s.Client, s.Master.Address(), transfer.Wallet.Address, transfer.Amount, nil)
+	if err != nil {
+		return "", types.TxStatusErrorBeforeSend, err
+	}
+	signedTx, err := s.Master.SignTx(tx, s.Client.GetChainID())
+	if err != nil {
+		return "", types.TxStatusErrorBeforeSend, errclass.NonRetryable(err)
+	}
+	if err := s.Client.SendRawTransaction(ctx, signedTx); err != nil {
+		return "", types.TxStatusErrorBeforeSend, err
+	}
+	txHash := signedTx.Hash().Hex()
+	receipt, err := s.Client.WaitForReceipt(ctx, signedTx.Hash())
+	if err != nil {
+		return txHash, types.TxStatusPending, err
+	}
+	if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		return txHash, types.TxStatusFailed, errclass.NonRetryable(fmt.Errorf("%w: %s", evm.ErrTxReverted, txHash))
+	}
+	return txHash, types.TxStatusSuccess, nil
+}
+
+// record writes the transfer to the transaction log; the recipient and the amount are stored
+// as the step outputs.
+func (s *Sender) record(transfer Transfer, txHash string, status types.TxStatus, sendErr error) {
+	outputs, _ := json.Marshal(map[string]string{
+		"to":         transfer.Wallet.Address.Hex(),
+		"amount_wei": transfer.Amount.String(),
+	})
+	record := storage.TransactionRecord{
+		Timestamp:     time.Now().Truncate(time.Second),
+		WalletAddress: s.Master.Address().Hex(),
+		TaskName:      TaskName,
+		Network:       s.Client.Network().Name,
+		TxHash:        txHash,
+		Status:        status,
+		Outputs:       string(outputs),
+	}
+	if sendErr != nil {
+		record.Error = sendErr.Error()
+		record.ErrorClass = errclass.Classify(sendErr)
+	}
+	// The transfer already happened, so the record is saved even when the command is interrupted.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.TxLogger.LogTransaction(ctx, record); err != nil {
+		s.Log.Error("Ошибка записи перевода в журнал транзакций", "to", transfer.Wallet.Address.Hex(),
+			"tx_hash", txHash, "error", err)
+	}
+}
@@ -0,0 +1,39 @@
+package funding
+
+import (
+	"math/big"
+	"testing"
+
+	"retro/internal/utils"
+)
+
+func TestRandomizeAmountKeepsBalanceWithinTarget(t *testing.T) {
+	ether := func(amount string) *big.Int {
+		wei, err := utils.ToWei(amount)
+		if err != nil {
+			t.Fatalf("ToWei(%s): %v", amount, err)
+		}
+		return wei
+	}
+	tests := []struct {
+		name    string
+		balance *big.Int
+		target  Target
+	}{
+		{"min equals max", ether("0.5"), Target{Min: ether("1.2345678912"), Max: ether("1.2345678912")}},
+		{"narrow range", ether("0.0012345"), Target{Min: ether("0.0101234567"), Max: ether("0.0101234569")}},
+		{"wide range", ether("0.2"), Target{Min: ether("1"), Max: ether("2")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				walletTarget := randomTarget(tt.target)
+				amount := randomizeAmount(new(big.Int).Sub(walletTarget, tt.balance), tt.balance, tt.target)
+				after := new(big.Int).Add(tt.balance, amount)
+				if after.Cmp(tt.target.Min) < 0 || after.Cmp(tt.target.Max) > 0 {
+					t.Fatalf("balance after funding %s is outside [%s, %s]", after, tt.target.Min, tt.target.Max)
+				}
+			}
+		})
+	}
+}
@@ -27,10 +27,13 @@ var (
 type LoadedKey struct {
 	PrivateKey *ecdsa.PrivateKey
 	Address    common.Address
+	// Label is the optional wallet name given as an inline comment after the key.
+	Label string
 }
 
 // LoadKeys reads private keys from a file and returns a slice of LoadedKey pointers.
-// It expects one private key per line, optionally prefixed with "0x".
+// It expects one private key per line, optionally prefixed with "0x" and followed by
+// an inline comment with the wallet label ("0xabc... # main-1").
 // Lines starting with '#' or empty lines are ignored.
 func LoadKeys(path string, log logger.Logger) ([]*LoadedKey, error) {
 	file, err := os.Open(path)
@@ -52,10 +55,12 @@ func LoadKeys(path string, log logger.Logger) ([]*LoadedKey, error) {
 			continue
 		}
 
-		privateKeyHex := strings.TrimPrefix(line, "0x")
+		keyPart, label, _ := strings.Cut(line, "#")
+		privateKeyHex := strings.TrimPrefix(strings.TrimSpace(keyPart), "0x")
 
 		keyData, _ := parsePrivateKeyToLoadedKey(privateKeyHex, lineNumber, path, log)
 		if keyData != nil {
+			keyData.Label = strings.TrimSpace(label)
 			loadedKeys = append(loadedKeys, keyData)
 		}
 	}
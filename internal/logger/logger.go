@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -44,12 +45,17 @@ var (
 
 // ColorLogger implements the Logger interface with colored console output.
 type ColorLogger struct {
-	// Пока нет полей конфигурации
+	out io.Writer
 }
 
-// NewColorLogger creates a new instance of ColorLogger.
+// NewColorLogger creates a new instance of ColorLogger writing to stdout.
 func NewColorLogger() Logger {
-	return &ColorLogger{}
+	return &ColorLogger{out: os.Stdout}
+}
+
+// NewColorLoggerTo creates a ColorLogger writing to w, e.g. stderr when stdout carries command output.
+func NewColorLoggerTo(w io.Writer) Logger {
+	return &ColorLogger{out: w}
 }
 
 // Info logs an informational message.
@@ -129,10 +135,10 @@ func (l *ColorLogger) printMessage(level, message string, addBlankLine bool, fie
 	formattedPrefix := l.formatMessage(level, message, fields...)
 	formattedFields := l.formatFields(fields...)
 
-	fmt.Println(formattedPrefix + formattedFields)
+	fmt.Fprintln(l.out, formattedPrefix+formattedFields)
 
 	if addBlankLine {
-		fmt.Println()
+		fmt.Fprintln(l.out)
 	}
 }
 
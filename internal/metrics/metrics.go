@@ -0,0 +1,165 @@
+// Package metrics collects Prometheus metrics of runs: task outcomes, retries, RPC latency,
+// worker usage, remaining wallets and gas spent. Metrics are always recorded; they are exposed
+// over HTTP only when the listener is enabled in the config.
+package metrics
+
+import (
+	"math/big"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"retro/internal/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "retro"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	// enabled is set once the listener is started; RPC requests are instrumented only then.
+	enabled atomic.Bool
+
+	tasksStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_started_total",
+		Help:      "Task executions started.",
+	}, []string{"task", "network"})
+	tasksSucceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_succeeded_total",
+		Help:      "Task executions that succeeded.",
+	}, []string{"task", "network"})
+	tasksFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_failed_total",
+		Help:      "Task executions that failed after all attempts, by error class.",
+	}, []string{"task", "network", "error_class"})
+	taskRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_retries_total",
+		Help:      "Repeated attempts of tasks after retryable errors.",
+	}, []string{"task", "network"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Duration of HTTP requests to RPC endpoints.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"network", "endpoint"})
+	rpcErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Failed HTTP requests to RPC endpoints: transport errors, HTTP 429 and 5xx.",
+	}, []string{"network", "endpoint"})
+
+	activeWorkers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_workers",
+		Help:      "Wallets being processed right now.",
+	})
+	maxWorkers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "max_parallel_wallets",
+		Help:      "Worker limit of the current run (concurrency.max_parallel_wallets, capped by the wallet count).",
+	})
+	walletsRemaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "wallets_remaining",
+		Help:      "Wallets of the current run not processed yet.",
+	})
+
+	gasSpent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gas_spent_native_total",
+		Help:      "Fees paid for mined and reverted transactions, in native currency units.",
+	}, []string{"network"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		tasksStarted, tasksSucceeded, tasksFailed, taskRetries,
+		rpcDuration, rpcErrors,
+		activeWorkers, maxWorkers, walletsRemaining,
+		gasSpent,
+	)
+}
+
+// Enabled reports whether the metrics listener is running.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// TaskStarted counts the start of a task execution.
+func TaskStarted(task types.TaskName, network string) {
+	tasksStarted.WithLabelValues(string(task), network).Inc()
+}
+
+// TaskSucceeded counts a successful task execution.
+func TaskSucceeded(task types.TaskName, network string) {
+	tasksSucceeded.WithLabelValues(string(task), network).Inc()
+}
+
+// TaskFailed counts a failed task execution.
+func TaskFailed(task types.TaskName, network string, class types.ErrorClass) {
+	tasksFailed.WithLabelValues(string(task), network, string(class)).Inc()
+}
+
+// TaskRetried counts a repeated attempt of a task.
+func TaskRetried(task types.TaskName, network string) {
+	taskRetries.WithLabelValues(string(task), network).Inc()
+}
+
+// RPCRequest records the duration and outcome of a request to an RPC endpoint.
+// The endpoint label holds only the scheme and host, so API keys in URLs are not exposed.
+func RPCRequest(network, endpoint string, duration time.Duration, failed bool) {
+	label := EndpointLabel(endpoint)
+	rpcDuration.WithLabelValues(network, label).Observe(duration.Seconds())
+	if failed {
+		rpcErrors.WithLabelValues(network, label).Inc()
+	}
+}
+
+// EndpointLabel strips the path, query and credentials from an RPC URL.
+func EndpointLabel(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "unknown"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// SetMaxWorkers records the worker limit of the run.
+func SetMaxWorkers(n int) {
+	maxWorkers.Set(float64(n))
+}
+
+// WorkerStarted marks the start of processing of a wallet.
+func WorkerStarted() {
+	activeWorkers.Inc()
+}
+
+// WorkerFinished marks the end of processing of a wallet.
+func WorkerFinished() {
+	activeWorkers.Dec()
+	walletsRemaining.Dec()
+}
+
+// SetWalletsRemaining records the number of wallets left in the run.
+func SetWalletsRemaining(n int) {
+	walletsRemaining.Set(float64(n))
+}
+
+// GasSpent adds the fee of a transaction, in wei, to the network total.
+func GasSpent(network string, feeWei *big.Int) {
+	if feeWei == nil || feeWei.Sign() <= 0 {
+		return
+	}
+	native, _ := new(big.Float).Quo(new(big.Float).SetInt(feeWei), big.NewFloat(1e18)).Float64()
+	gasSpent.WithLabelValues(network).Add(native)
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/logger"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Serve starts the metrics listener if it is enabled in cfg. The listener is stopped when
+// ctx is canceled. An error is returned only if the address cannot be bound.
+func Serve(ctx context.Context, cfg config.MetricsConfig, log logger.Logger) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	listener, err := net.Listen("tcp", cfg.Address())
+	if err != nil {
+		return fmt.Errorf("не удалось запустить сервер метрик на %s: %w", cfg.Address(), err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.HTTPPath(), promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	enabled.Store(true)
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Сервер метрик остановлен с ошибкой", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warn("Ошибка остановки сервера метрик", "error", err)
+		}
+	}()
+
+	log.Info("Сервер метрик Prometheus запущен", "address", listener.Addr().String(), "path", cfg.HTTPPath())
+	return nil
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"strconv"
+	"time"
+
+	"retro/internal/errclass"
+	"retro/internal/types"
+)
+
+// RunSummary counts the wallets of a finished run by outcome.
+type RunSummary struct {
+	RunID       string
+	Total       int
+	Succeeded   int
+	Failed      int
+	Interrupted bool
+	Duration    time.Duration
+}
+
+// RunStarted builds the event sent when a run starts processing wallets.
+func RunStarted(runID string, wallets, workers int) Event {
+	return Event{
+		Type:  types.NotifyRunStarted,
+		Title: "Запуск начат",
+		Fields: []Field{
+			{Name: "run_id", Value: runID},
+			{Name: "кошельков", Value: strconv.Itoa(wallets)},
+			{Name: "воркеров", Value: strconv.Itoa(workers)},
+		},
+	}
+}
+
+// RunFinished builds the summary event of a run.
+func RunFinished(summary RunSummary) Event {
+	title := "Запуск завершен"
+	if summary.Interrupted {
+		title = "Запуск прерван"
+	}
+	return Event{
+		Type:  types.NotifyRunFinished,
+		Title: title,
+		Fields: []Field{
+			{Name: "run_id", Value: summary.RunID},
+			{Name: "кошельков", Value: strconv.Itoa(summary.Total)},
+			{Name: "успешно", Value: strconv.Itoa(summary.Succeeded)},
+			{Name: "с ошибкой", Value: strconv.Itoa(summary.Failed)},
+			{Name: "не обработано", Value: strconv.Itoa(summary.Total - summary.Succeeded - summary.Failed)},
+			{Name: "длительность", Value: summary.Duration.Round(time.Second).String()},
+		},
+	}
+}
+
+// WalletFailed builds the event sent when a wallet fails after all retries.
+func WalletFailed(address string, index int, err error) Event {
+	return Event{
+		Type:  types.NotifyWalletFailed,
+		Title: "Кошелек обработан с ошибкой",
+		Fields: []Field{
+			{Name: "кошелек", Value: address},
+			{Name: "индекс", Value: strconv.Itoa(index)},
+			{Name: "класс", Value: string(errclass.Classify(err))},
+			{Name: "ошибка", Value: err.Error()},
+		},
+	}
+}
+
+// CircuitOpen builds the event sent when the RPC of a network is paused by the circuit breaker.
+func CircuitOpen(network string, until time.Time) Event {
+	return Event{
+		Type:    types.NotifyCircuitOpen,
+		Title:   "RPC сети недоступен, задачи сети приостановлены",
+		Network: network,
+		Fields: []Field{
+			{Name: "сеть", Value: network},
+			{Name: "до", Value: until.Format(time.DateTime)},
+		},
+	}
+}
+
+// LowBalance builds the event sent when a wallet's native balance is below the threshold.
+func LowBalance(address, network, balance, threshold, symbol string) Event {
+	return Event{
+		Type:    types.NotifyLowBalance,
+		Title:   "Низкий баланс кошелька",
+		Network: network,
+		Fields: []Field{
+			{Name: "кошелек", Value: address},
+			{Name: "сеть", Value: network},
+			{Name: "баланс", Value: balance + " " + symbol},
+			{Name: "порог", Value: threshold + " " + symbol},
+		},
+	}
+}
@@ -0,0 +1,195 @@
+// Package notify sends notifications about run events to webhooks and chat bots. Every event
+// type is filtered and rate-limited on its own; events are delivered in the background so a
+// slow or failing sink never delays a run. A nil *Notifier is valid and sends nothing.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/logger"
+	"retro/internal/types"
+
+	"golang.org/x/time/rate"
+)
+
+// sendTimeout bounds the delivery of one event to one sink.
+const sendTimeout = 10 * time.Second
+
+// queueSize is the number of events waiting for delivery; newer events are dropped when full.
+const queueSize = 100
+
+// Event is a notification about something that happened during a run.
+type Event struct {
+	Type    types.NotifyEvent `json:"type"`
+	Time    time.Time         `json:"time"`
+	Title   string            `json:"title"`
+	Network string            `json:"network,omitempty"`
+	Fields  []Field           `json:"fields,omitempty"`
+	// Suppressed counts the events of the type dropped by the rate limit since the last sent one.
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Field is a named value of an event, kept in order for chat messages.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Text renders the event as a plain-text chat message.
+func (e Event) Text() string {
+	var b strings.Builder
+	b.WriteString(e.Title)
+	for _, field := range e.Fields {
+		fmt.Fprintf(&b, "\n%s: %s", field.Name, field.Value)
+	}
+	if e.Suppressed > 0 {
+		fmt.Fprintf(&b, "\n(пропущено уведомлений этого типа из-за лимита: %d)", e.Suppressed)
+	}
+	return b.String()
+}
+
+// Sink delivers events to one destination.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+type routedSink struct {
+	sink   Sink
+	events map[types.NotifyEvent]bool // nil = all events
+}
+
+// Notifier filters, rate-limits and delivers events to the sinks.
+type Notifier struct {
+	events   map[types.NotifyEvent]config.NotifyEventConfig
+	sinks    []routedSink
+	limiters map[types.NotifyEvent]*rate.Limiter
+	log      logger.Logger
+
+	mu         sync.Mutex
+	suppressed map[types.NotifyEvent]int
+	closed     bool
+
+	queue chan Event
+	done  chan struct{}
+}
+
+// New creates a notifier from the config. It returns nil when there are no sinks or no events.
+func New(cfg config.NotificationsConfig, log logger.Logger) (*Notifier, error) {
+	if len(cfg.Sinks) == 0 || len(cfg.Events) == 0 {
+		return nil, nil
+	}
+	n := &Notifier{
+		events:     cfg.Events,
+		limiters:   make(map[types.NotifyEvent]*rate.Limiter, len(cfg.Events)),
+		log:        log,
+		suppressed: make(map[types.NotifyEvent]int),
+		queue:      make(chan Event, queueSize),
+		done:       make(chan struct{}),
+	}
+	for _, sinkCfg := range cfg.Sinks {
+		sink, err := NewSink(sinkCfg)
+		if err != nil {
+			return nil, err
+		}
+		routed := routedSink{sink: sink}
+		if len(sinkCfg.Events) > 0 {
+			routed.events = make(map[types.NotifyEvent]bool, len(sinkCfg.Events))
+			for _, event := range sinkCfg.Events {
+				routed.events[event] = true
+			}
+		}
+		n.sinks = append(n.sinks, routed)
+	}
+	for event, eventCfg := range cfg.Events {
+		maxEvents, per := eventCfg.RateLimit.Limit()
+		n.limiters[event] = rate.NewLimiter(rate.Every(per/time.Duration(maxEvents)), maxEvents)
+	}
+
+	go n.deliver()
+	log.Info("Уведомления включены", "sinks", len(n.sinks), "events", len(n.events))
+	return n, nil
+}
+
+// EventConfig returns the settings of an event type and whether the type is sent at all.
+func (n *Notifier) EventConfig(event types.NotifyEvent) (config.NotifyEventConfig, bool) {
+	if n == nil {
+		return config.NotifyEventConfig{}, false
+	}
+	eventCfg, ok := n.events[event]
+	return eventCfg, ok
+}
+
+// Notify queues the event for delivery if it passes the filter and the rate limit of its type.
+// It never blocks.
+func (n *Notifier) Notify(event Event) {
+	eventCfg, ok := n.EventConfig(event.Type)
+	if !ok || !eventCfg.AllowsNetwork(event.Network) {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	if !n.limiters[event.Type].Allow() {
+		n.suppressed[event.Type]++
+		n.log.Debug("Уведомление пропущено: превышен лимит", "event", event.Type)
+		return
+	}
+	event.Suppressed = n.suppressed[event.Type]
+	select {
+	case n.queue <- event:
+		n.suppressed[event.Type] = 0
+	default:
+		n.suppressed[event.Type]++
+		n.log.Warn("Очередь уведомлений переполнена, уведомление пропущено", "event", event.Type)
+	}
+}
+
+// deliver sends queued events to the sinks until the queue is closed.
+func (n *Notifier) deliver() {
+	defer close(n.done)
+	for event := range n.queue {
+		for _, routed := range n.sinks {
+			if routed.events != nil && !routed.events[event.Type] {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			if err := routed.sink.Send(ctx, event); err != nil {
+				n.log.Warn("Не удалось отправить уведомление", "sink", routed.sink.Name(), "event", event.Type, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Close stops accepting events and waits up to timeout for queued ones to be delivered.
+func (n *Notifier) Close(timeout time.Duration) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return
+	}
+	n.closed = true
+	close(n.queue)
+	n.mu.Unlock()
+
+	select {
+	case <-n.done:
+	case <-time.After(timeout):
+		n.log.Warn("Не все уведомления отправлены до завершения", "timeout", timeout)
+	}
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"retro/internal/config"
+	"retro/internal/types"
+)
+
+// DefaultTelegramBaseURL is the Bot API address used when base_url is not set.
+const DefaultTelegramBaseURL = "https://api.telegram.org"
+
+// Message length limits of the chat APIs.
+const (
+	telegramMaxText  = 4096
+	discordMaxText   = 2000
+	errorBodySnippet = 200
+)
+
+// NewSink creates the sink described by cfg, expanding ${NAME} environment references.
+func NewSink(cfg config.NotifySinkConfig) (Sink, error) {
+	name := cfg.Name
+	if name == "" {
+		name = string(cfg.Type)
+	}
+	client := &http.Client{}
+	switch cfg.Type {
+	case types.SinkWebhook:
+		headers := make(map[string]string, len(cfg.Headers))
+		for key, value := range cfg.Headers {
+			headers[key] = os.ExpandEnv(value)
+		}
+		return &webhookSink{name: name, url: os.ExpandEnv(cfg.URL), headers: headers, client: client}, nil
+	case types.SinkTelegram:
+		baseURL := strings.TrimRight(os.ExpandEnv(cfg.BaseURL), "/")
+		if baseURL == "" {
+			baseURL = DefaultTelegramBaseURL
+		}
+		return &telegramSink{
+			name:   name,
+			url:    baseURL + "/bot" + os.ExpandEnv(cfg.BotToken) + "/sendMessage",
+			chatID: os.ExpandEnv(cfg.ChatID),
+			client: client,
+		}, nil
+	case types.SinkDiscord:
+		return &discordSink{name: name, url: os.ExpandEnv(cfg.URL), client: client}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип получателя уведомлений: %s", cfg.Type)
+	}
+}
+
+// webhookSink posts the event as JSON.
+type webhookSink struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *webhookSink) Name() string { return s.name }
+
+func (s *webhookSink) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, s.client, s.url, s.headers, event)
+}
+
+// telegramSink sends the event as a text message through the Bot API sendMessage method.
+type telegramSink struct {
+	name   string
+	url    string
+	chatID string
+	client *http.Client
+}
+
+func (s *telegramSink) Name() string { return s.name }
+
+func (s *telegramSink) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, s.client, s.url, nil, map[string]any{
+		"chat_id":                  s.chatID,
+		"text":                     truncate(event.Text(), telegramMaxText),
+		"disable_web_page_preview": true,
+	})
+}
+
+// discordSink sends the event as the content of a Discord webhook message.
+type discordSink struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func (s *discordSink) Name() string { return s.name }
+
+func (s *discordSink) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, s.client, s.url, nil, map[string]any{
+		"content": truncate(event.Text(), discordMaxText),
+	})
+}
+
+// postJSON posts body as JSON and fails on non-2xx responses. Errors never include the URL,
+// which may contain a token.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования уведомления: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.New("неверный адрес получателя уведомлений")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("ошибка запроса к получателю уведомлений: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodySnippet))
+		return fmt.Errorf("получатель ответил HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// truncate shortens text to at most limit runes.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -6,12 +6,17 @@ import (
 	"fmt"
 
 	"retro/internal/config"
+	"retro/internal/control"
+	"retro/internal/errclass"
 	"retro/internal/evm"
 	"retro/internal/executor"
 	"retro/internal/keyloader"
 	"retro/internal/logger"
+	"retro/internal/notify"
+	"retro/internal/runctx"
 	"retro/internal/selector"
 	"retro/internal/storage"
+	"retro/internal/types"
 )
 
 // Processor encapsulates the logic for processing a single wallet.
@@ -21,10 +26,21 @@ type Processor struct {
 	walletIndex      int
 	currentWalletNum int
 	totalWalletsNum  int
-	taskSelector     *selector.Selector
-	taskExecutor     *executor.Executor
-	txLogger         storage.TransactionLogger
-	log              logger.Logger
+	runID            string
+	networkGuard     *evm.NetworkGuard
+	clientPool       *evm.ClientPool
+	control          *control.Controller
+	notifier         *notify.Notifier
+	// balanceChecked marks the networks whose balance was checked for low_balance notifications.
+	balanceChecked map[string]bool
+	// dryRunClients keeps one dry-run client per network for the whole wallet, so the nonces of
+	// transactions recorded by earlier tasks are not reused.
+	dryRunClients map[string]*evm.DryRunClient
+	taskSelector  *selector.Selector
+	taskExecutor  *executor.Executor
+	txLogger      storage.TransactionLogger
+	runContext    *runctx.Context
+	log           logger.Logger
 }
 
 // NewProcessor creates a new Processor instance.
@@ -34,11 +50,16 @@ func NewProcessor(
 	originalIndex int,
 	currentNum int,
 	totalNum int,
+	runID string,
+	networkGuard *evm.NetworkGuard,
+	clientPool *evm.ClientPool,
+	controller *control.Controller,
+	notifier *notify.Notifier,
 	txLogger storage.TransactionLogger,
 	log logger.Logger,
 ) *Processor {
-	taskSelector := selector.NewSelector(cfg, log)
-	taskExecutor := executor.NewExecutor(cfg, log)
+	taskSelector := selector.NewSelector(cfg, txLogger, log)
+	taskExecutor := executor.NewExecutor(cfg, txLogger, log)
 	signer := evm.NewSigner(key.PrivateKey)
 
 	return &Processor{
@@ -47,9 +68,17 @@ func NewProcessor(
 		walletIndex:      originalIndex,
 		currentWalletNum: currentNum,
 		totalWalletsNum:  totalNum,
+		runID:            runID,
+		networkGuard:     networkGuard,
+		clientPool:       clientPool,
+		control:          controller,
+		notifier:         notifier,
+		balanceChecked:   make(map[string]bool),
+		dryRunClients:    make(map[string]*evm.DryRunClient),
 		taskSelector:     taskSelector,
 		taskExecutor:     taskExecutor,
 		txLogger:         txLogger,
+		runContext:       runctx.New(),
 		log:              log,
 	}
 }
@@ -61,7 +90,7 @@ func (p *Processor) Process(ctx context.Context) error {
 	p.log.InfoWithBlankLine("-------------------- Начало обработки кошелька --------------------",
 		"wallet", walletProgress, "origIdx", p.walletIndex, "addr", walletAddress.Hex())
 
-	selectedTasks, err := p.taskSelector.SelectTasks()
+	selectedTasks, err := p.taskSelector.SelectTasks(ctx, walletAddress)
 	if err != nil {
 		if errors.Is(err, selector.ErrNoValidTasksSelected) {
 			p.log.Warn("Для кошелька не выбрано ни одной валидной задачи, пропускаем.",
@@ -106,12 +135,55 @@ func (p *Processor) Process(ctx context.Context) error {
 }
 
 // processTaskLoop iterates through the selected tasks and processes each one.
+// Steps on a network paused by the circuit breaker (and steps waiting for them) are postponed
+// until the other steps are done; then the loop waits for the network to come back.
 func (p *Processor) processTaskLoop(ctx context.Context, selectedTasks []config.TaskConfigEntry, walletProgress string) error {
 	walletAddress := p.signer.Address()
 	totalTasks := len(selectedTasks)
 	var firstError error
+	stepStatuses := make(map[string]types.StepStatus)
+	idempotencyKeys := p.idempotencyKeys(selectedTasks)
 
-	for taskIndex, taskEntry := range selectedTasks {
+	queue := make([]int, totalTasks)
+	for i := range queue {
+		queue[i] = i
+	}
+	var deferred []int
+	networkWaits := make(map[int]int)
+
+	// postpone defers a step because of a paused network; it reports false once the step
+	// has waited too often, in which case the step fails with networkErr.
+	postpone := func(taskIndex int, networkErr error, taskProgress string) bool {
+		taskEntry := selectedTasks[taskIndex]
+		if networkWaits[taskIndex] >= p.maxNetworkWaits() {
+			if firstError == nil {
+				firstError = networkErr
+			}
+			stepStatuses[taskEntry.StepID()] = types.StepStatusFailed
+			p.logTaskRecord(taskEntry, failedRecord(networkErr), walletProgress)
+			p.log.Error("Сеть задачи так и не восстановилась, задача не выполнена", "taskNum", taskProgress,
+				"task", taskEntry.Name, "net", taskEntry.Network, "err", networkErr,
+				"wallet", walletProgress, "addr", walletAddress.Hex())
+			return false
+		}
+		networkWaits[taskIndex]++
+		deferred = append(deferred, taskIndex)
+		p.log.Warn("Задача отложена: RPC сети временно приостановлен", "taskNum", taskProgress,
+			"task", taskEntry.Name, "net", taskEntry.Network, "err", networkErr,
+			"wallet", walletProgress, "addr", walletAddress.Hex())
+		return true
+	}
+
+	for len(queue) > 0 || len(deferred) > 0 {
+		if len(queue) == 0 {
+			if err := p.waitForNetworks(ctx, selectedTasks, deferred, walletProgress); err != nil {
+				return err
+			}
+			queue, deferred = deferred, nil
+		}
+		taskIndex := queue[0]
+		queue = queue[1:]
+		taskEntry := selectedTasks[taskIndex]
 		taskProgress := fmt.Sprintf("%d/%d", taskIndex+1, totalTasks)
 
 		select {
@@ -122,19 +194,87 @@ func (p *Processor) processTaskLoop(ctx context.Context, selectedTasks []config.
 			return ctx.Err()
 		default:
 		}
+		if err := p.control.WaitIfPaused(ctx); err != nil {
+			p.log.Warn("Обработка прервана (контекст отменен во время паузы)",
+				"task", taskEntry.Name, "taskNum", taskProgress,
+				"wallet", walletProgress, "addr", walletAddress.Hex())
+			return err
+		}
+
+		if waitsForDeferredStep(taskEntry, selectedTasks, deferred) {
+			deferred = append(deferred, taskIndex)
+			p.log.Info("Задача отложена вслед за шагом, от которого она зависит", "taskNum", taskProgress,
+				"task", taskEntry.Name, "wallet", walletProgress, "addr", walletAddress.Hex())
+			continue
+		}
+		if networkErr := p.networkGuard.Allow(taskEntry.Network); networkErr != nil {
+			postpone(taskIndex, networkErr, taskProgress)
+			continue
+		}
 
 		p.log.InfoWithBlankLine("------ Начало задачи ------", "taskNum", taskProgress,
-			"task", taskEntry.Name, "net", taskEntry.Network,
+			"task", taskEntry.Name, "step", taskEntry.StepID(), "net", taskEntry.Network,
 			"wallet", walletProgress, "addr", walletAddress.Hex())
 
+		idempotencyKey := idempotencyKeys[taskIndex]
+
+		completed, completedErr := p.restoreCompletedStep(ctx, taskEntry, idempotencyKey)
+		if completedErr != nil {
+			if errors.Is(completedErr, context.Canceled) || errors.Is(completedErr, context.DeadlineExceeded) {
+				return completedErr
+			}
+			p.log.Warn("Не удалось проверить, выполнялся ли шаг ранее", "task", taskEntry.Name,
+				"taskNum", taskProgress, "err", completedErr, "wallet", walletProgress, "addr", walletAddress.Hex())
+		}
+		if completed {
+			stepStatuses[taskEntry.StepID()] = types.StepStatusSuccess
+			p.log.Info("Шаг уже был успешно выполнен в этом запуске, повтор не требуется",
+				"task", taskEntry.Name, "step", taskEntry.StepID(), "taskNum", taskProgress,
+				"wallet", walletProgress, "addr", walletAddress.Hex())
+			p.log.InfoWithBlankLine("------ Конец задачи (уже выполнена) ------", "taskNum", taskProgress,
+				"task", taskEntry.Name, "wallet", walletProgress, "addr", walletAddress.Hex())
+			continue
+		}
+
+		if reason := p.checkDependencies(taskEntry, stepStatuses); reason != "" {
+			p.skipTask(taskEntry, reason, taskProgress, walletProgress, stepStatuses)
+			continue
+		}
+		if reason := p.checkStaticConditions(taskEntry, stepStatuses); reason != "" {
+			p.skipTask(taskEntry, reason, taskProgress, walletProgress, stepStatuses)
+			continue
+		}
+
+		resolvedParams, resolveErr := p.runContext.ResolveParams(taskEntry.Params)
+		if resolveErr != nil {
+			if firstError == nil {
+				firstError = resolveErr
+			}
+			stepStatuses[taskEntry.StepID()] = types.StepStatusFailed
+			p.logTaskRecord(taskEntry, failedRecord(resolveErr), walletProgress)
+			p.log.Error("Не удалось подставить выходные данные предыдущих шагов в параметры задачи",
+				"task", taskEntry.Name, "taskNum", taskProgress, "err", resolveErr,
+				"wallet", walletProgress, "addr", walletAddress.Hex())
+			p.log.InfoWithBlankLine("------ Конец задачи (пропущена) ------", "taskNum", taskProgress,
+				"task", taskEntry.Name, "wallet", walletProgress, "addr", walletAddress.Hex())
+			continue
+		}
+		taskEntry.Params = resolvedParams
+
 		client, runner, prepareErr := p.prepareTask(ctx, taskEntry, walletProgress)
 		if prepareErr != nil {
-			if errors.Is(prepareErr, context.Canceled) || errors.Is(prepareErr, context.DeadlineExceeded) {
+			if errors.Is(prepareErr, evm.ErrCircuitOpen) {
+				postpone(taskIndex, prepareErr, taskProgress)
+				continue
+			}
+			if errors.Is(prepareErr, context.Canceled) || errors.Is(prepareErr, context.DeadlineExceeded) ||
+				errclass.IsFatal(prepareErr) {
 				return prepareErr
 			}
 			if firstError == nil {
 				firstError = prepareErr
 			}
+			stepStatuses[taskEntry.StepID()] = types.StepStatusFailed
 			p.log.Warn("Задача пропущена из-за ошибки подготовки.", "taskNum", taskProgress,
 				"task", taskEntry.Name, "err", prepareErr, "wallet", walletProgress, "addr", walletAddress.Hex())
 			p.log.InfoWithBlankLine("------ Конец задачи (пропущена) ------", "taskNum", taskProgress,
@@ -142,18 +282,60 @@ func (p *Processor) processTaskLoop(ctx context.Context, selectedTasks []config.
 			continue
 		}
 
-		executionErr := p.executeAndLogTask(ctx, taskEntry, runner, client, walletProgress)
-		if executionErr != nil {
+		p.checkLowBalance(ctx, taskEntry.Network, client)
+
+		reason, conditionErr := p.checkBalanceConditions(ctx, taskEntry, client)
+		if conditionErr != nil || reason != "" {
+			if conditionErr != nil {
+				if errors.Is(conditionErr, context.Canceled) || errors.Is(conditionErr, context.DeadlineExceeded) {
+					return conditionErr
+				}
+				if firstError == nil {
+					firstError = conditionErr
+				}
+				stepStatuses[taskEntry.StepID()] = types.StepStatusFailed
+				p.logTaskRecord(taskEntry, failedRecord(conditionErr), walletProgress)
+				p.log.Error("Не удалось проверить условия run_if, задача не выполнена",
+					"task", taskEntry.Name, "taskNum", taskProgress, "err", conditionErr,
+					"wallet", walletProgress, "addr", walletAddress.Hex())
+				p.log.InfoWithBlankLine("------ Конец задачи (пропущена) ------", "taskNum", taskProgress,
+					"task", taskEntry.Name, "wallet", walletProgress, "addr", walletAddress.Hex())
+				continue
+			}
+			p.skipTask(taskEntry, reason, taskProgress, walletProgress, stepStatuses)
+			continue
+		}
+
+		taskCtx, finishTask := p.control.TaskStarted(ctx, walletAddress, taskEntry)
+		executionErr := p.executeAndLogTask(taskCtx, taskEntry, runner, client, idempotencyKey, walletProgress)
+		finishTask()
+		if errors.Is(executionErr, control.ErrTaskSkipped) {
+			stepStatuses[taskEntry.StepID()] = types.StepStatusSkipped
+			p.log.Warn("Задача пропущена оператором", "task", taskEntry.Name, "taskNum", taskProgress,
+				"wallet", walletProgress, "addr", walletAddress.Hex())
+		} else if errors.Is(executionErr, evm.ErrCircuitOpen) {
+			if postpone(taskIndex, executionErr, taskProgress) {
+				continue
+			}
+		} else if executionErr != nil {
+			stepStatuses[taskEntry.StepID()] = types.StepStatusFailed
 			p.log.Error("Ошибка выполнения задачи",
 				"task", taskEntry.Name, "taskNum", taskProgress, "err", executionErr,
 				"wallet", walletProgress, "addr", walletAddress.Hex())
 			if errors.Is(executionErr, context.Canceled) || errors.Is(executionErr, context.DeadlineExceeded) {
 				return executionErr
 			}
+			if errclass.IsFatal(executionErr) {
+				p.log.Error("Фатальная ошибка задачи, обработка кошелька остановлена",
+					"task", taskEntry.Name, "taskNum", taskProgress, "err", executionErr,
+					"wallet", walletProgress, "addr", walletAddress.Hex())
+				return executionErr
+			}
 			if firstError == nil {
 				firstError = executionErr
 			}
 		} else {
+			stepStatuses[taskEntry.StepID()] = types.StepStatusSuccess
 			p.log.Success("Задача успешно выполнена", "task", taskEntry.Name, "taskNum", taskProgress,
 				"wallet", walletProgress, "addr", walletAddress.Hex())
 		}
@@ -161,7 +343,7 @@ func (p *Processor) processTaskLoop(ctx context.Context, selectedTasks []config.
 		p.log.InfoWithBlankLine("------ Конец задачи ------", "taskNum", taskProgress,
 			"task", taskEntry.Name, "wallet", walletProgress, "addr", walletAddress.Hex())
 
-		if taskIndex < totalTasks-1 {
+		if len(queue) > 0 || len(deferred) > 0 {
 			if delayErr := p.performInterTaskDelay(ctx, walletProgress); delayErr != nil {
 				return delayErr
 			}
@@ -0,0 +1,154 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/evm"
+	"retro/internal/notify"
+	"retro/internal/types"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// checkDependencies returns a skip reason if any step the task depends on did not succeed earlier in this run.
+func (p *Processor) checkDependencies(taskEntry config.TaskConfigEntry, stepStatuses map[string]types.StepStatus) string {
+	for _, dep := range taskEntry.DependsOn {
+		status, ran := stepStatuses[dep]
+		if !ran {
+			return fmt.Sprintf("зависимость '%s' не выполнялась в этом запуске", dep)
+		}
+		if status != types.StepStatusSuccess {
+			return fmt.Sprintf("зависимость '%s' завершилась со статусом '%s'", dep, status)
+		}
+	}
+	return ""
+}
+
+// checkStaticConditions evaluates run_if conditions that do not need the network: wallet groups and step statuses.
+func (p *Processor) checkStaticConditions(taskEntry config.TaskConfigEntry, stepStatuses map[string]types.StepStatus) string {
+	runIf := taskEntry.RunIf
+	if runIf == nil {
+		return ""
+	}
+
+	if len(runIf.WalletGroups) > 0 {
+		inGroup := false
+		for _, group := range runIf.WalletGroups {
+			if p.cfg.WalletInGroup(group, p.signer.Address().Hex(), p.walletIndex) {
+				inGroup = true
+				break
+			}
+		}
+		if !inGroup {
+			return fmt.Sprintf("кошелек не входит ни в одну из групп [%s]", strings.Join(runIf.WalletGroups, ", "))
+		}
+	}
+
+	for step, expected := range runIf.StepStatus {
+		actual, ran := stepStatuses[step]
+		if !ran {
+			return fmt.Sprintf("шаг '%s' не выполнялся в этом запуске (ожидался статус '%s')", step, expected)
+		}
+		if actual != expected {
+			return fmt.Sprintf("шаг '%s' имеет статус '%s' (ожидался '%s')", step, actual, expected)
+		}
+	}
+
+	return ""
+}
+
+// checkBalanceConditions evaluates run_if balance conditions on the task network.
+// It returns a skip reason when a condition is not met, or an error if a balance could not be read.
+func (p *Processor) checkBalanceConditions(ctx context.Context, taskEntry config.TaskConfigEntry, client evm.EVMClient) (string, error) {
+	runIf := taskEntry.RunIf
+	if runIf == nil || (runIf.MinNativeBalance == "" && runIf.MinTokenBalance == nil) {
+		return "", nil
+	}
+	if client == nil {
+		return "", fmt.Errorf("условия баланса run_if требуют сеть, а задача настроена на сеть '%s'", taskEntry.Network)
+	}
+
+	walletAddress := p.signer.Address()
+	callCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if runIf.MinNativeBalance != "" {
+		minWei, err := utils.ToWei(runIf.MinNativeBalance)
+		if err != nil {
+			return "", fmt.Errorf("run_if.min_native_balance: %w", err)
+		}
+		balance, err := client.GetBalance(callCtx, walletAddress)
+		if err != nil {
+			return "", fmt.Errorf("проверка run_if.min_native_balance: %w", err)
+		}
+		if balance.Cmp(minWei) < 0 {
+			return fmt.Sprintf("нативный баланс %s меньше требуемого %s",
+				utils.FromWei(balance), runIf.MinNativeBalance), nil
+		}
+	}
+
+	if cond := runIf.MinTokenBalance; cond != nil {
+		token := common.HexToAddress(cond.Token)
+		decimals, err := evm.ERC20Decimals(callCtx, client, token)
+		if err != nil {
+			return "", fmt.Errorf("проверка run_if.min_token_balance: %w", err)
+		}
+		minUnits, err := utils.ToUnits(cond.Min, decimals)
+		if err != nil {
+			return "", fmt.Errorf("run_if.min_token_balance.min: %w", err)
+		}
+		balance, err := evm.ERC20BalanceOf(callCtx, client, token, walletAddress)
+		if err != nil {
+			return "", fmt.Errorf("проверка run_if.min_token_balance: %w", err)
+		}
+		if balance.Cmp(minUnits) < 0 {
+			return fmt.Sprintf("баланс токена %s равен %s, меньше требуемого %s",
+				token.Hex(), utils.FromUnits(balance, decimals), cond.Min), nil
+		}
+	}
+
+	return "", nil
+}
+
+// checkLowBalance sends a low_balance notification if the wallet's native balance on the
+// network is below the configured threshold. Every network is checked once per wallet;
+// failures only affect the notification, never the task.
+func (p *Processor) checkLowBalance(ctx context.Context, network string, client evm.EVMClient) {
+	if client == nil || p.balanceChecked[network] {
+		return
+	}
+	eventCfg, ok := p.notifier.EventConfig(types.NotifyLowBalance)
+	if !ok || !eventCfg.AllowsNetwork(network) {
+		return
+	}
+	p.balanceChecked[network] = true
+	threshold := eventCfg.MinBalanceFor(network)
+	if threshold == "" {
+		return
+	}
+	minWei, err := utils.ToWei(threshold)
+	if err != nil {
+		p.log.Warn("Неверный порог низкого баланса", "net", network, "threshold", threshold, "err", err)
+		return
+	}
+
+	walletAddress := p.signer.Address()
+	callCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	balance, err := client.GetBalance(callCtx, walletAddress)
+	if err != nil {
+		p.log.Debug("Не удалось проверить баланс для уведомления", "net", network, "err", err)
+		return
+	}
+	if balance.Cmp(minWei) < 0 {
+		symbol := p.cfg.RPCNodes[network].NativeSymbol
+		p.log.Warn("Нативный баланс кошелька ниже порога", "net", network,
+			"balance", utils.FromWei(balance), "threshold", threshold, "addr", walletAddress.Hex())
+		p.notifier.Notify(notify.LowBalance(walletAddress.Hex(), network, utils.FromWei(balance), threshold, symbol))
+	}
+}
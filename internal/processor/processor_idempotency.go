@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/runctx"
+	"retro/internal/storage"
+	"retro/internal/types"
+)
+
+// idempotencyKeys returns the idempotency key of every selected step, by position.
+func (p *Processor) idempotencyKeys(selectedTasks []config.TaskConfigEntry) []string {
+	keys := make([]string, len(selectedTasks))
+	occurrences := make(map[string]int)
+	for i, taskEntry := range selectedTasks {
+		occurrences[taskEntry.StepID()]++
+		keys[i] = p.idempotencyKey(taskEntry, occurrences[taskEntry.StepID()])
+	}
+	return keys
+}
+
+// idempotencyKey builds a stable key for the n-th occurrence of a step of this wallet in the current run.
+// The same key is produced after a process restart as long as the run ID is restored.
+func (p *Processor) idempotencyKey(taskEntry config.TaskConfigEntry, occurrence int) string {
+	raw := strings.Join([]string{
+		p.runID,
+		p.signer.Address().Hex(),
+		taskEntry.StepID(),
+		fmt.Sprint(occurrence),
+	}, "|")
+	sum := sha256.Sum256([]byte(raw))
+	return "0x" + hex.EncodeToString(sum[:])
+}
+
+// restoreCompletedStep reports whether the step with the given key already succeeded in this run.
+// Outputs of a completed step are loaded back into the run context so later steps can use them.
+func (p *Processor) restoreCompletedStep(ctx context.Context, taskEntry config.TaskConfigEntry, idempotencyKey string) (bool, error) {
+	if p.runID == "" {
+		return false, nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	records, err := p.txLogger.ListTransactions(queryCtx, storage.TransactionFilter{
+		IdempotencyKey: idempotencyKey,
+		Status:         types.TxStatusSuccess,
+		Limit:          1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("ошибка поиска выполненного шага: %w", err)
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	if records[0].Outputs != "" {
+		var outputs map[string]runctx.Value
+		if err := json.Unmarshal([]byte(records[0].Outputs), &outputs); err != nil {
+			return false, fmt.Errorf("не удалось разобрать сохраненные выходные данные шага: %w", err)
+		}
+		for key, value := range outputs {
+			p.runContext.Set(taskEntry.StepID(), key, value)
+		}
+	}
+	return true, nil
+}
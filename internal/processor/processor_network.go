@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"retro/internal/config"
+)
+
+// defaultMaxNetworkWaits is used when rpc.circuit_breaker.max_waits is not set.
+const defaultMaxNetworkWaits = 3
+
+// maxNetworkWaits returns how many times a step may be postponed because its network is paused.
+func (p *Processor) maxNetworkWaits() int {
+	if p.cfg.RPC.CircuitBreaker.MaxWaits > 0 {
+		return p.cfg.RPC.CircuitBreaker.MaxWaits
+	}
+	return defaultMaxNetworkWaits
+}
+
+// waitsForDeferredStep reports whether the step references (via depends_on or run_if.step_status)
+// a step that is currently postponed.
+func waitsForDeferredStep(taskEntry config.TaskConfigEntry, selectedTasks []config.TaskConfigEntry, deferred []int) bool {
+	for _, index := range deferred {
+		stepID := selectedTasks[index].StepID()
+		for _, dep := range taskEntry.DependsOn {
+			if dep == stepID {
+				return true
+			}
+		}
+		if taskEntry.RunIf != nil {
+			if _, ok := taskEntry.RunIf.StepStatus[stepID]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// waitForNetworks sleeps until the first paused network of the postponed steps is available again.
+func (p *Processor) waitForNetworks(ctx context.Context, selectedTasks []config.TaskConfigEntry, deferred []int, walletProgress string) error {
+	var resumeAt time.Time
+	for _, index := range deferred {
+		if p.networkGuard == nil {
+			break
+		}
+		until, open := p.networkGuard.Breaker.OpenUntil(selectedTasks[index].Network)
+		if open && (resumeAt.IsZero() || until.Before(resumeAt)) {
+			resumeAt = until
+		}
+	}
+
+	wait := time.Until(resumeAt)
+	if resumeAt.IsZero() || wait <= 0 {
+		return nil
+	}
+
+	p.log.Info("Остались только задачи приостановленных сетей, ожидание восстановления RPC",
+		"duration", wait.Round(time.Second), "tasks", len(deferred),
+		"wallet", walletProgress, "addr", p.signer.Address().Hex())
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		p.log.Warn("Ожидание восстановления RPC прервано (контекст отменен)",
+			"wallet", walletProgress, "addr", p.signer.Address().Hex())
+		return ctx.Err()
+	}
+}
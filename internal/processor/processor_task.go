@@ -2,42 +2,46 @@ package processor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
 	"retro/internal/config"
+	"retro/internal/control"
+	"retro/internal/errclass"
 	"retro/internal/evm"
+	"retro/internal/metrics"
+	"retro/internal/runctx"
 	"retro/internal/storage"
 	"retro/internal/tasks"
 	"retro/internal/types"
 	"retro/internal/utils"
 )
 
-// getEvmClientForTask creates an EVM client for the specified network if needed.
-func (p *Processor) getEvmClientForTask(ctx context.Context, network string) (*evm.Client, error) {
+// getEvmClientForTask returns the shared EVM client for the specified network if needed.
+func (p *Processor) getEvmClientForTask(ctx context.Context, network string) (evm.EVMClient, error) {
 	if network == "any" {
-		p.log.Debug("Пропуск создания EVM клиента для сети 'any'")
+		p.log.Debug("Пропуск получения EVM клиента для сети 'any'")
 		return nil, nil
 	}
 
-	rpcUrls, ok := p.cfg.RPCNodes[network]
-	if !ok || len(rpcUrls) == 0 {
-		return nil, fmt.Errorf("не найдены RPC URL для сети %s", network)
-	}
-
-	p.log.Debug("Создание EVM клиента", "net", network)
-	client, err := evm.NewClient(ctx, p.log, rpcUrls)
+	client, err := p.clientPool.Get(ctx, evm.ClientKey{Network: network})
 	if err != nil {
-		// Возвращаем исходную ошибку, чтобы внешний код мог ее правильно обработать (включая ошибки контекста)
-		return nil, fmt.Errorf("ошибка создания EVM клиента для сети %s: %w", network, err)
+		return nil, err
+	}
+	if p.cfg.DryRun {
+		dryRunClient, ok := p.dryRunClients[network]
+		if !ok {
+			dryRunClient = evm.NewDryRunClient(client, p.log)
+			p.dryRunClients[network] = dryRunClient
+		}
+		return dryRunClient, nil
 	}
-
 	return client, nil
 }
 
 // prepareTask handles the setup required before executing a task.
-func (p *Processor) prepareTask(ctx context.Context, taskEntry config.TaskConfigEntry, walletProgress string) (*evm.Client, tasks.TaskRunner, error) {
+func (p *Processor) prepareTask(ctx context.Context, taskEntry config.TaskConfigEntry, walletProgress string) (evm.EVMClient, tasks.TaskRunner, error) {
 	walletAddress := p.signer.Address()
 	runner, err := tasks.NewTask(taskEntry.Name, p.log)
 	if err != nil {
@@ -69,26 +73,91 @@ func (p *Processor) prepareTask(ctx context.Context, taskEntry config.TaskConfig
 	return client, runner, nil
 }
 
-// executeAndLogTask executes the task using the executor, closes the client, and logs the transaction.
-func (p *Processor) executeAndLogTask(ctx context.Context, taskEntry config.TaskConfigEntry, runner tasks.TaskRunner, client *evm.Client, walletProgress string) error {
-	executionErr := p.taskExecutor.ExecuteTaskWithRetries(ctx, p.signer, client, taskEntry, runner)
-
-	if client != nil {
-		client.Close()
-		p.log.Debug("EVM клиент закрыт", "task", taskEntry.Name, "net", taskEntry.Network, "wallet", walletProgress)
+// executeAndLogTask executes the task using the executor and logs the transaction.
+func (p *Processor) executeAndLogTask(
+	ctx context.Context,
+	taskEntry config.TaskConfigEntry,
+	runner tasks.TaskRunner,
+	client evm.EVMClient,
+	idempotencyKey string,
+	walletProgress string,
+) error {
+	stepCtx := runctx.WithStep(ctx, p.runContext, taskEntry.StepID())
+	stepCtx = evm.WithClientSource(stepCtx, p.getEvmClientForTask)
+	metrics.TaskStarted(taskEntry.Name, taskEntry.Network)
+	result, executionErr := p.taskExecutor.ExecuteTaskWithRetries(stepCtx, p.signer, client, taskEntry, runner, idempotencyKey)
+
+	if errors.Is(executionErr, evm.ErrCircuitOpen) {
+		// The step is postponed by the task loop and recorded once it finally runs.
+		return executionErr
+	}
+	if control.TaskSkipped(stepCtx) {
+		p.logTaskRecord(taskEntry, storage.TransactionRecord{
+			Status:         types.TxStatusSkipped,
+			Error:          control.ErrTaskSkipped.Error(),
+			TxHash:         result.LastTxHash(),
+			IdempotencyKey: idempotencyKey,
+		}, walletProgress)
+		return control.ErrTaskSkipped
 	}
 
-	record := storage.TransactionRecord{
-		Timestamp:     time.Now().Truncate(time.Second),
-		WalletAddress: p.signer.Address().Hex(),
-		TaskName:      taskEntry.Name,
-		Network:       taskEntry.Network,
+	record := storage.TransactionRecord{Status: types.TxStatusSuccess}
+	if p.cfg.DryRun {
+		record.Status = types.TxStatusDryRun
 	}
 	if executionErr != nil {
-		record.Status = types.TxStatusFailed
-		record.Error = executionErr.Error()
+		record = failedRecord(executionErr)
+		metrics.TaskFailed(taskEntry.Name, taskEntry.Network, record.ErrorClass)
 	} else {
-		record.Status = types.TxStatusSuccess
+		metrics.TaskSucceeded(taskEntry.Name, taskEntry.Network)
+	}
+	record.TxHash = result.LastTxHash()
+	if dryRunClient, ok := client.(*evm.DryRunClient); ok {
+		if recorded := dryRunClient.Recorded(); len(recorded) > 0 {
+			record.TxHash = recorded[len(recorded)-1].Hash().Hex()
+		}
+	}
+	record.IdempotencyKey = idempotencyKey
+	p.logTaskRecord(taskEntry, record, walletProgress)
+
+	return executionErr
+}
+
+// skipTask marks a step as skipped, logs the reason and records it in the transaction log.
+func (p *Processor) skipTask(taskEntry config.TaskConfigEntry, reason, taskProgress, walletProgress string, stepStatuses map[string]types.StepStatus) {
+	stepStatuses[taskEntry.StepID()] = types.StepStatusSkipped
+	p.log.Warn("Задача пропущена: условия выполнения не выполнены", "taskNum", taskProgress,
+		"task", taskEntry.Name, "step", taskEntry.StepID(), "reason", reason,
+		"wallet", walletProgress, "addr", p.signer.Address().Hex())
+	p.logTaskRecord(taskEntry, storage.TransactionRecord{Status: types.TxStatusSkipped, Error: reason}, walletProgress)
+	p.log.InfoWithBlankLine("------ Конец задачи (пропущена) ------", "taskNum", taskProgress,
+		"task", taskEntry.Name, "wallet", walletProgress, "addr", p.signer.Address().Hex())
+}
+
+// failedRecord builds the outcome part of a transaction record for a failed step.
+func failedRecord(err error) storage.TransactionRecord {
+	return storage.TransactionRecord{
+		Status:     types.TxStatusFailed,
+		Error:      err.Error(),
+		ErrorClass: errclass.Classify(err),
+	}
+}
+
+// logTaskRecord completes the outcome record of a task step and writes it to the transaction log.
+func (p *Processor) logTaskRecord(taskEntry config.TaskConfigEntry, record storage.TransactionRecord, walletProgress string) {
+	record.Timestamp = time.Now().Truncate(time.Second)
+	record.WalletAddress = p.signer.Address().Hex()
+	record.TaskName = taskEntry.Name
+	record.Network = taskEntry.Network
+	if record.Status != types.TxStatusSkipped {
+		if outputs := p.runContext.StepOutputs(taskEntry.StepID()); len(outputs) > 0 {
+			encoded, err := json.Marshal(outputs)
+			if err != nil {
+				p.log.Warn("Не удалось сериализовать выходные данные шага", "task", taskEntry.Name, "err", err)
+			} else {
+				record.Outputs = string(encoded)
+			}
+		}
 	}
 
 	logTxCtx, logTxCancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -98,8 +167,6 @@ func (p *Processor) executeAndLogTask(ctx context.Context, taskEntry config.Task
 			"wallet", walletProgress, "addr", p.signer.Address().Hex())
 	}
 	logTxCancel()
-
-	return executionErr
 }
 
 // performInterTaskDelay handles the delay between tasks.
@@ -0,0 +1,153 @@
+package runctx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValueType describes how a stored output value should be interpreted.
+type ValueType string
+
+const (
+	TypeAmount  ValueType = "amount"
+	TypeTxHash  ValueType = "tx_hash"
+	TypeAddress ValueType = "address"
+	TypeString  ValueType = "string"
+)
+
+// Value is a typed output value produced by a task step.
+type Value struct {
+	Type  ValueType `json:"type"`
+	Value string    `json:"value"`
+}
+
+// String returns the textual form of the value used in parameter templates.
+func (v Value) String() string {
+	return v.Value
+}
+
+// Context holds the outputs produced by task steps during one wallet run.
+// It is safe for concurrent use.
+type Context struct {
+	mu    sync.RWMutex
+	steps map[string]map[string]Value
+}
+
+// New creates an empty run context.
+func New() *Context {
+	return &Context{steps: make(map[string]map[string]Value)}
+}
+
+// Set stores an output value for the given step.
+func (c *Context) Set(step, key string, value Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.steps[step] == nil {
+		c.steps[step] = make(map[string]Value)
+	}
+	c.steps[step][key] = value
+}
+
+// Get returns an output value of the given step.
+func (c *Context) Get(step, key string) (Value, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.steps[step][key]
+	return value, ok
+}
+
+// StepOutputs returns a copy of all outputs of the given step.
+func (c *Context) StepOutputs(step string) map[string]Value {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	outputs := make(map[string]Value, len(c.steps[step]))
+	for key, value := range c.steps[step] {
+		outputs[key] = value
+	}
+	return outputs
+}
+
+// ClearStep removes all outputs of the given step.
+func (c *Context) ClearStep(step string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.steps, step)
+}
+
+// Step is the view of the run context given to a running task: it writes outputs
+// under the current step and can read outputs of any earlier step.
+type Step struct {
+	run *Context
+	id  string
+}
+
+// ID returns the identifier of the current step.
+func (s *Step) ID() string {
+	return s.id
+}
+
+// Reset removes the outputs written by the current step, e.g. before a retry attempt.
+func (s *Step) Reset() {
+	s.run.ClearStep(s.id)
+}
+
+// SetAmount stores an integer amount (wei or token units) produced by the step.
+func (s *Step) SetAmount(key string, amount *big.Int) {
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	s.run.Set(s.id, key, Value{Type: TypeAmount, Value: amount.String()})
+}
+
+// SetTxHash stores a transaction hash produced by the step.
+func (s *Step) SetTxHash(key string, hash common.Hash) {
+	s.run.Set(s.id, key, Value{Type: TypeTxHash, Value: hash.Hex()})
+}
+
+// SetAddress stores an address produced by the step.
+func (s *Step) SetAddress(key string, address common.Address) {
+	s.run.Set(s.id, key, Value{Type: TypeAddress, Value: address.Hex()})
+}
+
+// SetString stores an arbitrary string produced by the step.
+func (s *Step) SetString(key, value string) {
+	s.run.Set(s.id, key, Value{Type: TypeString, Value: value})
+}
+
+// Get returns an output of any step of the current run.
+func (s *Step) Get(step, key string) (Value, bool) {
+	return s.run.Get(step, key)
+}
+
+// Amount returns an amount output of any step of the current run.
+func (s *Step) Amount(step, key string) (*big.Int, error) {
+	value, ok := s.run.Get(step, key)
+	if !ok {
+		return nil, fmt.Errorf("output '%s.%s' not found", step, key)
+	}
+	amount, ok := new(big.Int).SetString(value.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("output '%s.%s' is not an integer amount: %s", step, key, value.Value)
+	}
+	return amount, nil
+}
+
+type stepContextKey struct{}
+
+// WithStep returns a context carrying the step view of the run context for a task.
+func WithStep(ctx context.Context, run *Context, stepID string) context.Context {
+	return context.WithValue(ctx, stepContextKey{}, &Step{run: run, id: stepID})
+}
+
+// StepFromContext returns the step view carried by ctx. When the task is run outside of a
+// processor, a detached step is returned so tasks never have to check for nil.
+func StepFromContext(ctx context.Context) *Step {
+	if step, ok := ctx.Value(stepContextKey{}).(*Step); ok {
+		return step
+	}
+	return &Step{run: New(), id: "detached"}
+}
@@ -0,0 +1,72 @@
+package runctx
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// templatePattern matches references like {{ steps.swap.amount_out }}.
+var templatePattern = regexp.MustCompile(`\{\{\s*steps\.([A-Za-z0-9_\-]+)\.([A-Za-z0-9_\-]+)\s*\}\}`)
+
+// ResolveParams returns a copy of params with all step output references substituted.
+// A reference to an output that has not been produced yet is an error.
+func (c *Context) ResolveParams(params map[string]interface{}) (map[string]interface{}, error) {
+	if params == nil {
+		return nil, nil
+	}
+	resolved, err := c.resolveValue(params)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.(map[string]interface{}), nil
+}
+
+// resolveValue walks maps and slices and resolves templates in string leaves.
+func (c *Context) resolveValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return c.resolveString(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := c.resolveValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := c.resolveValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveString substitutes every step output reference found in s.
+func (c *Context) resolveString(s string) (string, error) {
+	var resolveErr error
+	result := templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := templatePattern.FindStringSubmatch(match)
+		value, ok := c.Get(groups[1], groups[2])
+		if !ok {
+			if resolveErr == nil {
+				resolveErr = fmt.Errorf("output 'steps.%s.%s' is not available", groups[1], groups[2])
+			}
+			return match
+		}
+		return value.String()
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return result, nil
+}
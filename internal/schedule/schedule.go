@@ -0,0 +1,172 @@
+// Package schedule spreads the wallets of a run over a time window: each wallet gets a random
+// start time within the allowed daily hours, and the plan is persisted so a restart keeps it.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/storage"
+)
+
+// StateKey is the state storage key holding the current plan.
+const StateKey = "schedule_plan"
+
+// Slot is the planned start time of one wallet.
+type Slot struct {
+	Wallet  string    `json:"wallet"`
+	StartAt time.Time `json:"start_at"`
+	// Done is set once the wallet has been processed successfully.
+	Done bool `json:"done,omitempty"`
+}
+
+// Plan is the schedule of a run, ordered by start time.
+type Plan struct {
+	CreatedAt time.Time `json:"created_at"`
+	Slots     []Slot    `json:"slots"`
+}
+
+// Pending returns the slots not processed yet, in start order.
+func (p *Plan) Pending() []Slot {
+	var pending []Slot
+	for _, slot := range p.Slots {
+		if !slot.Done {
+			pending = append(pending, slot)
+		}
+	}
+	return pending
+}
+
+// MarkDone marks the wallet's slot as processed; it reports whether the wallet is in the plan.
+func (p *Plan) MarkDone(wallet string) bool {
+	for i := range p.Slots {
+		if p.Slots[i].Wallet == wallet {
+			p.Slots[i].Done = true
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether the plan has a slot for exactly the given wallets.
+func (p *Plan) Covers(wallets []string) bool {
+	if len(p.Slots) != len(wallets) {
+		return false
+	}
+	planned := make(map[string]bool, len(p.Slots))
+	for _, slot := range p.Slots {
+		planned[slot.Wallet] = true
+	}
+	for _, wallet := range wallets {
+		if !planned[wallet] {
+			return false
+		}
+	}
+	return true
+}
+
+// interval is a span of allowed start times.
+type interval struct {
+	from, to time.Time
+}
+
+// allowedIntervals returns the parts of [now, now+window) that fall into the daily hours.
+func allowedIntervals(cfg config.ScheduleConfig, now time.Time) ([]interval, error) {
+	window, err := cfg.Window.Duration()
+	if err != nil {
+		return nil, err
+	}
+	dailyStart, dailyEnd, err := cfg.DailyHours()
+	if err != nil {
+		return nil, err
+	}
+	location, err := cfg.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	end := now.Add(window)
+	local := now.In(location)
+	// Start a day early: yesterday's hours may run past midnight into today.
+	day := time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, location)
+	var intervals []interval
+	for !day.After(end) {
+		from := day.Add(dailyStart)
+		to := day.Add(dailyEnd)
+		if from.Before(now) {
+			from = now
+		}
+		if to.After(end) {
+			to = end
+		}
+		if from.Before(to) {
+			intervals = append(intervals, interval{from: from, to: to})
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, location)
+	}
+	return intervals, nil
+}
+
+// NewPlan assigns every wallet a random start time in the window starting at now, within the daily hours.
+func NewPlan(cfg config.ScheduleConfig, wallets []string, now time.Time, rnd *rand.Rand) (*Plan, error) {
+	intervals, err := allowedIntervals(cfg, now)
+	if err != nil {
+		return nil, err
+	}
+	var total time.Duration
+	for _, iv := range intervals {
+		total += iv.to.Sub(iv.from)
+	}
+	if total <= 0 {
+		return nil, errors.New("в окне расписания нет разрешенного времени (проверьте window и daily_start/daily_end)")
+	}
+
+	plan := &Plan{CreatedAt: now}
+	for _, wallet := range wallets {
+		offset := time.Duration(rnd.Int63n(int64(total)))
+		for _, iv := range intervals {
+			length := iv.to.Sub(iv.from)
+			if offset < length {
+				plan.Slots = append(plan.Slots, Slot{Wallet: wallet, StartAt: iv.from.Add(offset).Truncate(time.Second)})
+				break
+			}
+			offset -= length
+		}
+	}
+	sort.SliceStable(plan.Slots, func(i, j int) bool { return plan.Slots[i].StartAt.Before(plan.Slots[j].StartAt) })
+	return plan, nil
+}
+
+// Load reads the saved plan; it returns nil without an error if there is none.
+func Load(ctx context.Context, stateStorage storage.StateStorage) (*Plan, error) {
+	value, err := stateStorage.GetState(ctx, StateKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrStateNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if value == "" {
+		return nil, nil
+	}
+	var plan Plan
+	if err := json.Unmarshal([]byte(value), &plan); err != nil {
+		return nil, fmt.Errorf("ошибка разбора сохраненного расписания: %w", err)
+	}
+	return &plan, nil
+}
+
+// Save writes the plan to the state storage.
+func Save(ctx context.Context, stateStorage storage.StateStorage, plan *Plan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации расписания: %w", err)
+	}
+	return stateStorage.SetState(ctx, StateKey, string(data))
+}
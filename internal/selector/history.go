@@ -0,0 +1,126 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"retro/internal/config"
+	"retro/internal/storage"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// taskHistory summarizes the successful past executions of one task entry by one wallet.
+type taskHistory struct {
+	count   int
+	lastRun time.Time
+}
+
+// historyKey identifies a task entry in the transaction log.
+func historyKey(name types.TaskName, network string) string {
+	return string(name) + "@" + network
+}
+
+// loadHistory reads the wallet's successful executions from the transaction log.
+// It returns nil when no configured feature needs history.
+func (s *Selector) loadHistory(ctx context.Context, walletAddress common.Address) (map[string]taskHistory, error) {
+	needsLifetime := false
+	window := time.Duration(0)
+	if s.cfg.Actions.History.Enabled {
+		lookback, err := s.cfg.Actions.History.Lookback.Duration()
+		if err != nil {
+			return nil, fmt.Errorf("actions.history.lookback: %w", err)
+		}
+		window = lookback
+	}
+	for _, taskCfg := range s.cfg.Tasks {
+		if !taskCfg.Enabled {
+			continue
+		}
+		if taskCfg.MaxExecutions > 0 {
+			needsLifetime = true
+		}
+		if taskCfg.Cooldown != nil {
+			cooldown, err := taskCfg.Cooldown.Duration()
+			if err != nil {
+				return nil, fmt.Errorf("tasks.%s.cooldown: %w", taskCfg.Name, err)
+			}
+			if cooldown > window {
+				window = cooldown
+			}
+		}
+	}
+	if !needsLifetime && window == 0 {
+		return nil, nil
+	}
+
+	filter := storage.TransactionFilter{
+		WalletAddress: walletAddress.Hex(),
+		Status:        types.TxStatusSuccess,
+	}
+	if !needsLifetime {
+		filter.Since = time.Now().Add(-window)
+	}
+
+	records, err := s.txLogger.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории транзакций: %w", err)
+	}
+
+	history := make(map[string]taskHistory)
+	for _, record := range records {
+		key := historyKey(record.TaskName, record.Network)
+		entry := history[key]
+		entry.count++
+		if record.Timestamp.After(entry.lastRun) {
+			entry.lastRun = record.Timestamp
+		}
+		history[key] = entry
+	}
+	s.log.Debug("История задач кошелька загружена", "addr", walletAddress.Hex(),
+		"records", len(records), "tasks", len(history))
+	return history, nil
+}
+
+// blockedByHistory returns a reason if the task cannot run now because of its cooldown or lifetime cap.
+func blockedByHistory(taskCfg config.TaskConfigEntry, history map[string]taskHistory, now time.Time) string {
+	entry, ok := history[historyKey(taskCfg.Name, taskCfg.Network)]
+	if !ok {
+		return ""
+	}
+	if taskCfg.MaxExecutions > 0 && entry.count >= taskCfg.MaxExecutions {
+		return fmt.Sprintf("достигнут лимит выполнений (%d)", taskCfg.MaxExecutions)
+	}
+	if taskCfg.Cooldown != nil {
+		cooldown, err := taskCfg.Cooldown.Duration()
+		if err == nil && now.Sub(entry.lastRun) < cooldown {
+			return fmt.Sprintf("cooldown до %s", entry.lastRun.Add(cooldown).Format(time.DateTime))
+		}
+	}
+	return ""
+}
+
+// noveltyFactor scales a task weight up the longer the wallet has not executed the task,
+// reaching 1+novelty_boost for tasks not executed within the lookback window.
+func (s *Selector) noveltyFactor(taskCfg config.TaskConfigEntry, history map[string]taskHistory, now time.Time) float64 {
+	historyCfg := s.cfg.Actions.History
+	if !historyCfg.Enabled || historyCfg.NoveltyBoost == 0 {
+		return 1
+	}
+	lookback, err := historyCfg.Lookback.Duration()
+	if err != nil || lookback <= 0 {
+		return 1
+	}
+
+	entry, ok := history[historyKey(taskCfg.Name, taskCfg.Network)]
+	if !ok {
+		return 1 + historyCfg.NoveltyBoost
+	}
+	age := now.Sub(entry.lastRun)
+	if age >= lookback {
+		return 1 + historyCfg.NoveltyBoost
+	}
+	return 1 + historyCfg.NoveltyBoost*float64(age)/float64(lookback)
+}
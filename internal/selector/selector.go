@@ -1,34 +1,48 @@
 package selector
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"sort"
+	"time"
 
 	"retro/internal/config"
 	"retro/internal/logger"
+	"retro/internal/storage"
 	"retro/internal/types"
 	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 var ErrNoValidTasksSelected = errors.New("selector: no valid and active tasks selected")
 
 // Selector is responsible for the logic of selecting tasks to execute.
 type Selector struct {
-	cfg *config.Config
-	log logger.Logger
+	cfg      *config.Config
+	txLogger storage.TransactionLogger
+	log      logger.Logger
 }
 
 // NewSelector creates a new Selector instance.
-func NewSelector(cfg *config.Config, log logger.Logger) *Selector {
+func NewSelector(cfg *config.Config, txLogger storage.TransactionLogger, log logger.Logger) *Selector {
 	return &Selector{
-		cfg: cfg,
-		log: log,
+		cfg:      cfg,
+		txLogger: txLogger,
+		log:      log,
 	}
 }
 
-// SelectTasks selects tasks to run based on configuration.
-func (s *Selector) SelectTasks() ([]config.TaskConfigEntry, error) {
+// SelectTasks selects tasks to run for the wallet based on configuration and the wallet's history.
+func (s *Selector) SelectTasks(ctx context.Context, walletAddress common.Address) ([]config.TaskConfigEntry, error) {
+	history, err := s.loadHistory(ctx, walletAddress)
+	if err != nil {
+		s.log.Error("Не удалось загрузить историю задач кошелька, выбор без учета истории",
+			"addr", walletAddress.Hex(), "error", err)
+	}
+	now := time.Now()
+
 	if len(s.cfg.Actions.ExplicitTaskSequence) > 0 {
 		s.log.Debug("Используется режим явной последовательности задач")
 		var selected []config.TaskConfigEntry
@@ -46,22 +60,34 @@ func (s *Selector) SelectTasks() ([]config.TaskConfigEntry, error) {
 				s.log.Warn("Задача из явной последовательности не найдена/отключена", "task", taskName)
 				continue
 			}
+			if reason := blockedByHistory(taskCfg, history, now); reason != "" {
+				s.log.Info("Задача из явной последовательности пропущена по истории", "task", taskName, "reason", reason)
+				continue
+			}
 			selected = append(selected, taskCfg)
 		}
 		if len(selected) == 0 {
 			return nil, ErrNoValidTasksSelected
 		}
 		s.log.Info("Выбраны задачи из явной последовательности", "count", len(selected))
-		return selected, nil
+		return s.orderByDependencies(selected), nil
 	}
 
 	s.log.Debug("Используется режим случайного выбора задач")
 
 	availableTasks := make([]config.TaskConfigEntry, 0)
+	weights := make([]float64, 0)
 	for _, taskCfg := range s.cfg.Tasks {
-		if taskCfg.Enabled {
-			availableTasks = append(availableTasks, taskCfg)
+		if !taskCfg.Enabled {
+			continue
+		}
+		if reason := blockedByHistory(taskCfg, history, now); reason != "" {
+			s.log.Debug("Задача исключена из выбора по истории", "task", taskCfg.Name,
+				"net", taskCfg.Network, "reason", reason)
+			continue
 		}
+		availableTasks = append(availableTasks, taskCfg)
+		weights = append(weights, taskWeight(taskCfg)*s.noveltyFactor(taskCfg, history, now))
 	}
 
 	if len(availableTasks) == 0 {
@@ -97,10 +123,14 @@ func (s *Selector) SelectTasks() ([]config.TaskConfigEntry, error) {
 		return []config.TaskConfigEntry{}, nil
 	}
 
-	selectedTasks := make([]config.TaskConfigEntry, 0, numTasksToSelect)
-	for i := 0; i < numTasksToSelect; i++ {
-		randomIndex := rand.Intn(len(availableTasks))
-		selectedTasks = append(selectedTasks, availableTasks[randomIndex])
+	selectedTasks := s.pickWeighted(availableTasks, weights, numTasksToSelect)
+	if len(selectedTasks) < numTasksToSelect {
+		s.log.Warn("Выбрано меньше задач, чем запрошено (исчерпан пул с учетом режима выбора и лимита повторов)",
+			"requested", numTasksToSelect, "selected", len(selectedTasks),
+			"mode", s.selectionMode(), "max_repeats_per_task", s.cfg.Actions.MaxRepeatsPerTask)
+	}
+	if len(selectedTasks) == 0 {
+		return nil, ErrNoValidTasksSelected
 	}
 
 	if s.cfg.Actions.TaskOrder == types.TaskOrderSequential {
@@ -113,8 +143,104 @@ func (s *Selector) SelectTasks() ([]config.TaskConfigEntry, error) {
 			return originalIndex[selectedTasks[i].Name] < originalIndex[selectedTasks[j].Name]
 		})
 	} else {
-		s.log.Debug("Порядок выполнения задач - случайный (выбраны случайно с повторениями)")
+		s.log.Debug("Порядок выполнения задач - случайный", "mode", s.selectionMode())
+	}
+
+	return s.orderByDependencies(selectedTasks), nil
+}
+
+// orderByDependencies stably reorders tasks so that every step runs after the selected steps it depends on.
+// Steps whose dependencies were not selected keep their place; the processor skips them at run time.
+func (s *Selector) orderByDependencies(selected []config.TaskConfigEntry) []config.TaskConfigEntry {
+	remaining := append([]config.TaskConfigEntry(nil), selected...)
+	ordered := make([]config.TaskConfigEntry, 0, len(selected))
+
+	for len(remaining) > 0 {
+		next := 0
+		for i, candidate := range remaining {
+			if !dependsOnAny(candidate, remaining, i) {
+				next = i
+				break
+			}
+		}
+		if next != 0 {
+			s.log.Debug("Задача перемещена после своих зависимостей", "task", remaining[next].Name,
+				"step", remaining[next].StepID())
+		}
+		ordered = append(ordered, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
 	}
 
-	return selectedTasks, nil
+	return ordered
+}
+
+// dependsOnAny reports whether the candidate depends on any other step still waiting in remaining.
+func dependsOnAny(candidate config.TaskConfigEntry, remaining []config.TaskConfigEntry, candidateIdx int) bool {
+	for _, dep := range candidate.DependsOn {
+		for j, other := range remaining {
+			if j != candidateIdx && other.StepID() == dep {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectionMode returns the configured selection mode, defaulting to picking with replacement.
+func (s *Selector) selectionMode() types.SelectionMode {
+	if s.cfg.Actions.SelectionMode == "" {
+		return types.SelectionWithReplacement
+	}
+	return s.cfg.Actions.SelectionMode
+}
+
+// pickWeighted draws up to count tasks using the given weights, honoring the selection mode
+// and the per-task repeat cap. It returns fewer tasks when the pool is exhausted.
+func (s *Selector) pickWeighted(availableTasks []config.TaskConfigEntry, weights []float64, count int) []config.TaskConfigEntry {
+	maxRepeats := s.cfg.Actions.MaxRepeatsPerTask
+	if s.selectionMode() == types.SelectionWithoutReplacement {
+		maxRepeats = 1
+	}
+
+	picks := make([]int, len(availableTasks))
+
+	selected := make([]config.TaskConfigEntry, 0, count)
+	for len(selected) < count {
+		totalWeight := 0.0
+		for i, weight := range weights {
+			if maxRepeats > 0 && picks[i] >= maxRepeats {
+				continue
+			}
+			totalWeight += weight
+		}
+		if totalWeight <= 0 {
+			break
+		}
+
+		target := rand.Float64() * totalWeight
+		chosen := -1
+		for i, weight := range weights {
+			if maxRepeats > 0 && picks[i] >= maxRepeats {
+				continue
+			}
+			chosen = i
+			target -= weight
+			if target < 0 {
+				break
+			}
+		}
+
+		picks[chosen]++
+		selected = append(selected, availableTasks[chosen])
+	}
+
+	return selected
+}
+
+// taskWeight returns the weight of a task entry; an unset weight counts as 1.
+func taskWeight(taskCfg config.TaskConfigEntry) float64 {
+	if taskCfg.Weight == nil {
+		return 1
+	}
+	return *taskCfg.Weight
 }
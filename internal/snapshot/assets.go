@@ -0,0 +1,65 @@
+// Package snapshot collects cross-network portfolio snapshots of the wallets (native and token
+// balances plus nonces) and compares them.
+package snapshot
+
+import (
+	"context"
+	"math/big"
+
+	"retro/internal/evm"
+	"retro/internal/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Asset is a network's native coin (zero token address) or a configured ERC-20 token.
+type Asset struct {
+	Token    common.Address
+	Symbol   string
+	Decimals uint8
+}
+
+// IsNative reports whether the asset is the native coin of the network.
+func (a Asset) IsNative() bool {
+	return a.Token == (common.Address{})
+}
+
+// TokenHex returns the token address, or "" for the native coin.
+func (a Asset) TokenHex() string {
+	if a.IsNative() {
+		return ""
+	}
+	return a.Token.Hex()
+}
+
+// BalanceOf returns the owner's balance of the asset in its smallest units.
+func (a Asset) BalanceOf(ctx context.Context, client evm.EVMClient, owner common.Address) (*big.Int, error) {
+	if a.IsNative() {
+		return client.GetBalance(ctx, owner)
+	}
+	return evm.ERC20BalanceOf(ctx, client, a.Token, owner)
+}
+
+// NetworkAssets returns the native coin followed by the configured tokens whose metadata could be read.
+func NetworkAssets(ctx context.Context, client evm.EVMClient, tokens []string, log logger.Logger) []Asset {
+	nativeSymbol := client.Network().NativeSymbol
+	if nativeSymbol == "" {
+		nativeSymbol = "native"
+	}
+	assets := []Asset{{Symbol: nativeSymbol, Decimals: 18}}
+	for _, tokenHex := range tokens {
+		token := common.HexToAddress(tokenHex)
+		decimals, err := evm.ERC20Decimals(ctx, client, token)
+		if err != nil {
+			log.Warn("Не удалось получить decimals токена, токен пропущен",
+				"network", client.Network().Name, "token", token.Hex(), "error", err)
+			continue
+		}
+		symbol, err := evm.ERC20Symbol(ctx, client, token)
+		if err != nil || symbol == "" {
+			symbol = token.Hex()
+		}
+		assets = append(assets, Asset{Token: token, Symbol: symbol, Decimals: decimals})
+	}
+	return assets
+}
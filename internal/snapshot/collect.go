@@ -0,0 +1,118 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/storage"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Collector reads the balances and nonces of wallets through a shared client pool.
+type Collector struct {
+	pool   *evm.ClientPool
+	tokens map[string][]string
+	log    logger.Logger
+}
+
+// NewCollector creates a collector; tokens maps a network to the token addresses to include
+// (config balances.tokens).
+func NewCollector(pool *evm.ClientPool, tokens map[string][]string, log logger.Logger) *Collector {
+	return &Collector{pool: pool, tokens: tokens, log: log}
+}
+
+// Collect returns the snapshot records of every wallet in every network. Networks are queried
+// in parallel; a network that cannot be reached and balances that cannot be read are logged and
+// left out of the snapshot.
+func (c *Collector) Collect(ctx context.Context, snapshotID string, at time.Time, wallets []common.Address, networks []string) ([]storage.SnapshotRecord, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		records []storage.SnapshotRecord
+	)
+	for _, network := range networks {
+		wg.Add(1)
+		go func(network string) {
+			defer wg.Done()
+			networkRecords := c.collectNetwork(ctx, snapshotID, at, wallets, network)
+			mu.Lock()
+			records = append(records, networkRecords...)
+			mu.Unlock()
+		}(network)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.WalletAddress != b.WalletAddress {
+			return a.WalletAddress < b.WalletAddress
+		}
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return a.TokenAddress < b.TokenAddress
+	})
+	return records, nil
+}
+
+// collectNetwork reads the assets of all wallets in one network.
+func (c *Collector) collectNetwork(ctx context.Context, snapshotID string, at time.Time, wallets []common.Address, network string) []storage.SnapshotRecord {
+	client, err := c.pool.Get(ctx, evm.ClientKey{Network: network})
+	if err != nil {
+		c.log.Error("Не удалось подключиться к сети, сеть пропущена", "network", network, "error", err)
+		return nil
+	}
+	assets := NetworkAssets(ctx, client, c.tokens[network], c.log)
+
+	var records []storage.SnapshotRecord
+	for _, wallet := range wallets {
+		if ctx.Err() != nil {
+			return nil
+		}
+		nonce, err := client.GetNonce(ctx, wallet)
+		if err != nil {
+			c.log.Warn("Не удалось получить nonce, кошелек пропущен в сети",
+				"network", network, "wallet", wallet.Hex(), "error", err)
+			continue
+		}
+		for _, asset := range assets {
+			balance, err := asset.BalanceOf(ctx, client, wallet)
+			if err != nil {
+				c.log.Warn("Не удалось получить баланс", "network", network, "asset", asset.Symbol,
+					"wallet", wallet.Hex(), "error", err)
+				continue
+			}
+			records = append(records, storage.SnapshotRecord{
+				SnapshotID:    snapshotID,
+				Timestamp:     at,
+				WalletAddress: wallet.Hex(),
+				Network:       network,
+				Asset:         asset.Symbol,
+				TokenAddress:  asset.TokenHex(),
+				Decimals:      asset.Decimals,
+				Balance:       balance.String(),
+				Nonce:         nonce,
+			})
+		}
+	}
+	return records
+}
+
+// NewID generates a sortable, unique snapshot ID for the given time.
+func NewID(at time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return at.UTC().Format("20060102T150405.000000000")
+	}
+	return at.UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,155 @@
+package snapshot
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"retro/internal/storage"
+)
+
+// Change is the difference of one wallet's asset in one network between two snapshots.
+// From or To is nil when the asset is missing from that snapshot.
+type Change struct {
+	WalletAddress string
+	Network       string
+	Asset         string
+	TokenAddress  string
+	Decimals      uint8
+	From, To      *big.Int
+	// FromNonce and ToNonce are the wallet's nonces in the network; ok flags tell whether they are known.
+	FromNonce, ToNonce       uint64
+	HasFromNonce, HasToNonce bool
+}
+
+// Delta returns To - From, treating a missing side as zero.
+func (c Change) Delta() *big.Int {
+	delta := new(big.Int)
+	if c.To != nil {
+		delta.Set(c.To)
+	}
+	if c.From != nil {
+		delta.Sub(delta, c.From)
+	}
+	return delta
+}
+
+// Sent returns the number of transactions the wallet sent in the network between the snapshots.
+func (c Change) Sent() (uint64, bool) {
+	if !c.HasFromNonce || !c.HasToNonce || c.ToNonce < c.FromNonce {
+		return 0, false
+	}
+	return c.ToNonce - c.FromNonce, true
+}
+
+// IsNative reports whether the change is about the native coin.
+func (c Change) IsNative() bool {
+	return c.TokenAddress == ""
+}
+
+// Diff compares two snapshots record by record, sorted by wallet, network and asset (native first).
+func Diff(from, to []storage.SnapshotRecord) []Change {
+	type key struct{ wallet, network, token string }
+	type walletNetwork struct{ wallet, network string }
+
+	changes := make(map[key]*Change)
+	fromNonces := make(map[walletNetwork]uint64)
+	toNonces := make(map[walletNetwork]uint64)
+	changeOf := func(record storage.SnapshotRecord) *Change {
+		k := key{record.WalletAddress, record.Network, record.TokenAddress}
+		change, ok := changes[k]
+		if !ok {
+			change = &Change{
+				WalletAddress: record.WalletAddress,
+				Network:       record.Network,
+				Asset:         record.Asset,
+				TokenAddress:  record.TokenAddress,
+				Decimals:      record.Decimals,
+			}
+			changes[k] = change
+		}
+		return change
+	}
+	for _, record := range from {
+		changeOf(record).From = parseAmount(record.Balance)
+		fromNonces[walletNetwork{record.WalletAddress, record.Network}] = record.Nonce
+	}
+	for _, record := range to {
+		change := changeOf(record)
+		change.To = parseAmount(record.Balance)
+		change.Asset = record.Asset
+		toNonces[walletNetwork{record.WalletAddress, record.Network}] = record.Nonce
+	}
+
+	result := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		wn := walletNetwork{change.WalletAddress, change.Network}
+		change.FromNonce, change.HasFromNonce = fromNonces[wn]
+		change.ToNonce, change.HasToNonce = toNonces[wn]
+		result = append(result, *change)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.WalletAddress != b.WalletAddress {
+			return a.WalletAddress < b.WalletAddress
+		}
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return a.TokenAddress < b.TokenAddress
+	})
+	return result
+}
+
+// GasEstimate is the native coin spent on fees by a wallet in a network between two snapshots.
+type GasEstimate struct {
+	// Fees is the estimate in wei; nil when nothing is known about the fees.
+	Fees *big.Int
+	// Tracked is the number of transactions with a known fee in the broadcasts table.
+	Tracked int
+	// Extrapolated is set when the nonce shows more transactions than were tracked
+	// (e.g. sent manually) and Fees was scaled up from the tracked average.
+	Extrapolated bool
+}
+
+// EstimateGas sums the fees of the wallet's broadcasts in the network recorded between the
+// snapshot times. Transactions counted by the nonce but not tracked are priced at the tracked average.
+func EstimateGas(change Change, broadcasts []storage.BroadcastRecord, since, until time.Time) GasEstimate {
+	fees := new(big.Int)
+	tracked := 0
+	for _, broadcast := range broadcasts {
+		if broadcast.WalletAddress != change.WalletAddress || broadcast.Network != change.Network {
+			continue
+		}
+		if broadcast.Timestamp.Before(since) || broadcast.Timestamp.After(until) {
+			continue
+		}
+		fee := parseAmount(broadcast.FeeWei)
+		if fee == nil {
+			continue
+		}
+		fees.Add(fees, fee)
+		tracked++
+	}
+
+	sent, ok := change.Sent()
+	switch {
+	case !ok || sent <= uint64(tracked):
+		return GasEstimate{Fees: fees, Tracked: tracked}
+	case tracked == 0:
+		return GasEstimate{}
+	default:
+		fees.Mul(fees, new(big.Int).SetUint64(sent))
+		fees.Div(fees, big.NewInt(int64(tracked)))
+		return GasEstimate{Fees: fees, Tracked: tracked, Extrapolated: true}
+	}
+}
+
+// parseAmount parses a decimal amount; nil for an empty or invalid string.
+func parseAmount(value string) *big.Int {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil
+	}
+	return amount
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildListTransactionsQuery builds the SELECT query for ListTransactions.
+// placeholder renders the n-th (1-based) bind parameter in the dialect of the store.
+func BuildListTransactionsQuery(filter TransactionFilter, placeholder func(n int) string) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, placeholder(len(args))))
+	}
+
+	if filter.WalletAddress != "" {
+		add("LOWER(wallet_address) = LOWER(%s)", filter.WalletAddress)
+	}
+	if filter.TaskName != "" {
+		add("task_name = %s", string(filter.TaskName))
+	}
+	if filter.Network != "" {
+		add("network = %s", filter.Network)
+	}
+	if filter.Status != "" {
+		add("status = %s", string(filter.Status))
+	}
+	if !filter.Since.IsZero() {
+		add("timestamp >= %s", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("timestamp < %s", filter.Until)
+	}
+	if filter.IdempotencyKey != "" {
+		add("idempotency_key = %s", filter.IdempotencyKey)
+	}
+
+	query := `SELECT timestamp, wallet_address, task_name, network, tx_hash, status, error_message, error_class, outputs, idempotency_key
+	           FROM transactions`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY timestamp DESC, id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	return query, args
+}
+
+// BuildListBroadcastsQuery builds the SELECT query for ListBroadcasts.
+// placeholder renders the n-th (1-based) bind parameter in the dialect of the store.
+func BuildListBroadcastsQuery(filter BroadcastFilter, placeholder func(n int) string) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, placeholder(len(args))))
+	}
+
+	if filter.IdempotencyKey != "" {
+		add("idempotency_key = %s", filter.IdempotencyKey)
+	}
+	if filter.WalletAddress != "" {
+		add("LOWER(wallet_address) = LOWER(%s)", filter.WalletAddress)
+	}
+	if filter.Network != "" {
+		add("network = %s", filter.Network)
+	}
+	if filter.Status != "" {
+		add("status = %s", string(filter.Status))
+	}
+	if !filter.Since.IsZero() {
+		add("timestamp >= %s", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("timestamp < %s", filter.Until)
+	}
+
+	query := `SELECT tx_hash, idempotency_key, timestamp, wallet_address, network, nonce, to_address, value_wei, status, fee_wei
+	           FROM broadcasts`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY timestamp ASC, nonce ASC"
+	return query, args
+}
@@ -2,8 +2,10 @@
 
 import (
 	"context"
+	"time"
 
 	"retro/internal/storage"
+	"retro/internal/types"
 )
 
 // NoOpStorage is an implementation of TransactionLogger that does nothing.
@@ -24,6 +26,41 @@ func (s *noOpStorage) LogTransaction(ctx context.Context, record storage.Transac
 	return nil
 }
 
+// ListTransactions always returns an empty history for the NoOp store.
+func (s *noOpStorage) ListTransactions(ctx context.Context, filter storage.TransactionFilter) ([]storage.TransactionRecord, error) {
+	return nil, nil
+}
+
+// SaveBroadcast does nothing.
+func (s *noOpStorage) SaveBroadcast(ctx context.Context, record storage.BroadcastRecord) error {
+	return nil
+}
+
+// UpdateBroadcastStatus does nothing.
+func (s *noOpStorage) UpdateBroadcastStatus(ctx context.Context, txHash string, status types.BroadcastStatus, feeWei string) error {
+	return nil
+}
+
+// ListBroadcasts always returns no broadcasts for the NoOp store.
+func (s *noOpStorage) ListBroadcasts(ctx context.Context, filter storage.BroadcastFilter) ([]storage.BroadcastRecord, error) {
+	return nil, nil
+}
+
+// SaveSnapshot does nothing.
+func (s *noOpStorage) SaveSnapshot(ctx context.Context, records []storage.SnapshotRecord) error {
+	return nil
+}
+
+// ListSnapshots always returns no snapshots for the NoOp store.
+func (s *noOpStorage) ListSnapshots(ctx context.Context) ([]storage.SnapshotSummary, error) {
+	return nil, nil
+}
+
+// GetSnapshot always returns no records for the NoOp store.
+func (s *noOpStorage) GetSnapshot(ctx context.Context, snapshotID string) ([]storage.SnapshotRecord, error) {
+	return nil, nil
+}
+
 // Close does nothing.
 func (s *noOpStorage) Close() error {
 	// No operation
@@ -39,3 +76,23 @@ func (s *noOpStorage) GetState(ctx context.Context, key string) (string, error)
 func (s *noOpStorage) SetState(ctx context.Context, key, value string) error {
 	return nil // No operation, always successful
 }
+
+// ListState always returns an empty state for the NoOp store.
+func (s *noOpStorage) ListState(ctx context.Context) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+// DeleteState does nothing for the NoOp store.
+func (s *noOpStorage) DeleteState(ctx context.Context, key string) error {
+	return nil
+}
+
+// AcquireLock always succeeds for the NoOp store: without a database runs are not locked.
+func (s *noOpStorage) AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+// ReleaseLock does nothing for the NoOp store.
+func (s *noOpStorage) ReleaseLock(ctx context.Context, name, owner string) error {
+	return nil
+}
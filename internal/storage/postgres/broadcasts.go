@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"retro/internal/storage"
+	"retro/internal/types"
+)
+
+// SaveBroadcast inserts or replaces a record in the 'broadcasts' table.
+func (s *store) SaveBroadcast(ctx context.Context, record storage.BroadcastRecord) error {
+	query := `INSERT INTO broadcasts (tx_hash, idempotency_key, timestamp, wallet_address, network, nonce, to_address, value_wei, status, fee_wei)
+	           VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	           ON CONFLICT (tx_hash) DO UPDATE SET status = EXCLUDED.status, fee_wei = EXCLUDED.fee_wei`
+	_, err := s.pool.Exec(ctx, query,
+		record.TxHash,
+		record.IdempotencyKey,
+		record.Timestamp,
+		record.WalletAddress,
+		record.Network,
+		int64(record.Nonce),
+		record.To,
+		record.ValueWei,
+		string(record.Status),
+		record.FeeWei,
+	)
+	if err != nil {
+		s.log.Error("Failed to save broadcast record", "tx_hash", record.TxHash, "error", err)
+		return fmt.Errorf("failed to save broadcast %s: %w", record.TxHash, err)
+	}
+	s.log.Debug("Broadcast record saved to DB", "tx_hash", record.TxHash, "status", record.Status)
+	return nil
+}
+
+// UpdateBroadcastStatus sets the status and fee of a record in the 'broadcasts' table.
+func (s *store) UpdateBroadcastStatus(ctx context.Context, txHash string, status types.BroadcastStatus, feeWei string) error {
+	query := `UPDATE broadcasts SET status = $1, fee_wei = $2 WHERE tx_hash = $3`
+	if _, err := s.pool.Exec(ctx, query, string(status), feeWei, txHash); err != nil {
+		s.log.Error("Failed to update broadcast status", "tx_hash", txHash, "error", err)
+		return fmt.Errorf("failed to update broadcast %s: %w", txHash, err)
+	}
+	s.log.Debug("Broadcast status updated in DB", "tx_hash", txHash, "status", status)
+	return nil
+}
+
+// ListBroadcasts returns records from the 'broadcasts' table matching the filter, oldest first.
+func (s *store) ListBroadcasts(ctx context.Context, filter storage.BroadcastFilter) ([]storage.BroadcastRecord, error) {
+	query, args := storage.BuildListBroadcastsQuery(filter, func(n int) string { return fmt.Sprintf("$%d", n) })
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Failed to query broadcasts from DB", "error", err)
+		return nil, fmt.Errorf("failed to query broadcasts: %w", err)
+	}
+	defer rows.Close()
+
+	var records []storage.BroadcastRecord
+	for rows.Next() {
+		var (
+			record               storage.BroadcastRecord
+			nonce                int64
+			status               string
+			to, valueWei, feeWei *string
+		)
+		if err := rows.Scan(&record.TxHash, &record.IdempotencyKey, &record.Timestamp, &record.WalletAddress,
+			&record.Network, &nonce, &to, &valueWei, &status, &feeWei); err != nil {
+			return nil, fmt.Errorf("failed to scan broadcast row: %w", err)
+		}
+		record.Nonce = uint64(nonce)
+		record.To = derefString(to)
+		record.ValueWei = derefString(valueWei)
+		record.Status = types.BroadcastStatus(status)
+		record.FeeWei = derefString(feeWei)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate broadcast rows: %w", err)
+	}
+	return records, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"retro/internal/logger"
 	"retro/internal/storage"
+	"retro/internal/types"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -36,6 +38,33 @@ func NewStore(
 	}
 	log.Info("Table 'application_state' initialized successfully (or already existed).")
 
+	if _, err := pool.Exec(ctx, storage.CreateBroadcastsTableSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create broadcasts table: %w", err)
+	}
+	if _, err := pool.Exec(ctx, storage.CreateBroadcastsKeyIndexSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create broadcasts index: %w", err)
+	}
+	log.Info("Table 'broadcasts' initialized successfully (or already existed).")
+
+	if _, err := pool.Exec(ctx, storage.CreateSnapshotsTableSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create snapshots table: %w", err)
+	}
+	log.Info("Table 'snapshots' initialized successfully (or already existed).")
+
+	if _, err := pool.Exec(ctx, storage.CreateLocksTableSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create locks table: %w", err)
+	}
+	log.Info("Table 'locks' initialized successfully (or already existed).")
+
+	for _, migration := range storage.ColumnMigrations {
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s",
+			migration.Table, migration.Column, migration.Definition)
+		if _, err := pool.Exec(ctx, query); err != nil {
+			return nil, nil, fmt.Errorf("failed to add column %s.%s: %w", migration.Table, migration.Column, err)
+		}
+	}
+	log.Debug("Column migrations applied.", "count", len(storage.ColumnMigrations))
+
 	log.Success("PostgreSQL schema initialized.")
 	s := &store{pool: pool, log: log}
 	return s, s, nil
@@ -43,8 +72,8 @@ func NewStore(
 
 // LogTransaction saves a transaction record to the 'transactions' table.
 func (s *store) LogTransaction(ctx context.Context, record storage.TransactionRecord) error {
-	query := `INSERT INTO transactions (timestamp, wallet_address, task_name, network, tx_hash, status, error_message) 
-	           VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	query := `INSERT INTO transactions (timestamp, wallet_address, task_name, network, tx_hash, status, error_message, error_class, outputs, idempotency_key) 
+	           VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
 	_, err := s.pool.Exec(ctx, query,
 		record.Timestamp,
@@ -54,6 +83,9 @@ func (s *store) LogTransaction(ctx context.Context, record storage.TransactionRe
 		record.TxHash,
 		string(record.Status),
 		record.Error,
+		string(record.ErrorClass),
+		record.Outputs,
+		record.IdempotencyKey,
 	)
 
 	if err != nil {
@@ -69,6 +101,50 @@ func (s *store) LogTransaction(ctx context.Context, record storage.TransactionRe
 	return nil
 }
 
+// ListTransactions returns records from the 'transactions' table matching the filter, newest first.
+func (s *store) ListTransactions(ctx context.Context, filter storage.TransactionFilter) ([]storage.TransactionRecord, error) {
+	query, args := storage.BuildListTransactionsQuery(filter, func(n int) string { return fmt.Sprintf("$%d", n) })
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Failed to query transactions from DB", "error", err)
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var records []storage.TransactionRecord
+	for rows.Next() {
+		var (
+			record                                  storage.TransactionRecord
+			taskName, status                        string
+			txHash, errText, errClass, outputs, key *string
+		)
+		if err := rows.Scan(&record.Timestamp, &record.WalletAddress, &taskName, &record.Network,
+			&txHash, &status, &errText, &errClass, &outputs, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction row: %w", err)
+		}
+		record.TaskName = types.TaskName(taskName)
+		record.Status = types.TxStatus(status)
+		record.TxHash = derefString(txHash)
+		record.Error = derefString(errText)
+		record.ErrorClass = types.ErrorClass(derefString(errClass))
+		record.Outputs = derefString(outputs)
+		record.IdempotencyKey = derefString(key)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate transaction rows: %w", err)
+	}
+	return records, nil
+}
+
+// derefString returns the pointed-to string or "" for NULL columns.
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
 // GetState retrieves a value from the application_state table.
 func (s *store) GetState(ctx context.Context, key string) (string, error) {
 	query := `SELECT value FROM application_state WHERE key = $1`
@@ -98,6 +174,60 @@ func (s *store) SetState(ctx context.Context, key, value string) error {
 	return nil
 }
 
+// ListState returns all rows of the application_state table.
+func (s *store) ListState(ctx context.Context) (map[string]string, error) {
+	rows, err := s.pool.Query(ctx, `SELECT key, value FROM application_state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state: %w", err)
+	}
+	defer rows.Close()
+
+	state := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan state row: %w", err)
+		}
+		state[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate state rows: %w", err)
+	}
+	return state, nil
+}
+
+// DeleteState removes a key from the application_state table.
+func (s *store) DeleteState(ctx context.Context, key string) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM application_state WHERE key = $1`, key); err != nil {
+		s.log.Error("Failed to delete state in DB", "key", key, "error", err)
+		return fmt.Errorf("failed to delete state for key '%s': %w", key, err)
+	}
+	s.log.Debug("State deleted from DB", "key", key)
+	return nil
+}
+
+// AcquireLock takes or extends a row of the locks table.
+func (s *store) AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	query := fmt.Sprintf(storage.AcquireLockSQL, "$1", "$2", "$3", "$4")
+	tag, err := s.pool.Exec(ctx, query, name, owner, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		s.log.Error("Failed to acquire lock in DB", "lock", name, "error", err)
+		return false, fmt.Errorf("failed to acquire lock '%s': %w", name, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReleaseLock deletes the row of the locks table if it belongs to the owner.
+func (s *store) ReleaseLock(ctx context.Context, name, owner string) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM locks WHERE name = $1 AND owner = $2`, name, owner); err != nil {
+		s.log.Error("Failed to release lock in DB", "lock", name, "error", err)
+		return fmt.Errorf("failed to release lock '%s': %w", name, err)
+	}
+	s.log.Debug("Lock released in DB", "lock", name)
+	return nil
+}
+
 // Close closes the database connection pool.
 func (s *store) Close() error {
 	if s.pool != nil {
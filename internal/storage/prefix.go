@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"strings"
+)
+
+// prefixedState keeps the state of one consumer under a key prefix of a shared StateStorage.
+type prefixedState struct {
+	StateStorage
+	prefix string
+}
+
+// WithKeyPrefix returns a StateStorage that stores all keys under the prefix, so several runs
+// (e.g. daemon schedules) can keep separate resume state in one table. Locks are not prefixed.
+// Close does not close the underlying storage.
+func WithKeyPrefix(state StateStorage, prefix string) StateStorage {
+	return &prefixedState{StateStorage: state, prefix: prefix}
+}
+
+// GetState retrieves the value of the prefixed key.
+func (p *prefixedState) GetState(ctx context.Context, key string) (string, error) {
+	return p.StateStorage.GetState(ctx, p.prefix+key)
+}
+
+// SetState saves the value under the prefixed key.
+func (p *prefixedState) SetState(ctx context.Context, key, value string) error {
+	return p.StateStorage.SetState(ctx, p.prefix+key, value)
+}
+
+// ListState returns the keys under the prefix, with the prefix removed.
+func (p *prefixedState) ListState(ctx context.Context) (map[string]string, error) {
+	all, err := p.StateStorage.ListState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state := make(map[string]string)
+	for key, value := range all {
+		if rest, ok := strings.CutPrefix(key, p.prefix); ok {
+			state[rest] = value
+		}
+	}
+	return state, nil
+}
+
+// DeleteState removes the prefixed key.
+func (p *prefixedState) DeleteState(ctx context.Context, key string) error {
+	return p.StateStorage.DeleteState(ctx, p.prefix+key)
+}
+
+// Close does nothing: the underlying storage is owned by its creator.
+func (p *prefixedState) Close() error {
+	return nil
+}
@@ -17,3 +17,77 @@ CREATE TABLE IF NOT EXISTS application_state (
 	key TEXT PRIMARY KEY,
 	value TEXT NOT NULL
 );`
+
+const CreateBroadcastsTableSQL = `
+CREATE TABLE IF NOT EXISTS broadcasts (
+    tx_hash VARCHAR(66) PRIMARY KEY,
+    idempotency_key VARCHAR(66) NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    network VARCHAR(255) NOT NULL,
+    nonce BIGINT NOT NULL,
+    to_address VARCHAR(42),
+    value_wei TEXT,
+    status VARCHAR(50) NOT NULL,
+    fee_wei TEXT
+);`
+
+const CreateBroadcastsKeyIndexSQL = `
+CREATE INDEX IF NOT EXISTS idx_broadcasts_idempotency_key ON broadcasts (idempotency_key);`
+
+const CreateSnapshotsTableSQL = `
+CREATE TABLE IF NOT EXISTS snapshots (
+    snapshot_id VARCHAR(64) NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    network VARCHAR(255) NOT NULL,
+    asset VARCHAR(64) NOT NULL,
+    token_address VARCHAR(42) NOT NULL DEFAULT '',
+    decimals INTEGER NOT NULL,
+    balance TEXT NOT NULL,
+    nonce BIGINT NOT NULL,
+    PRIMARY KEY (snapshot_id, wallet_address, network, token_address)
+);`
+
+const CreateLocksTableSQL = `
+CREATE TABLE IF NOT EXISTS locks (
+    name VARCHAR(255) PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    expires_at BIGINT NOT NULL
+);`
+
+// AcquireLockSQL takes a free or expired lock, or extends a lock already held by the same owner.
+// Placeholders: name, owner, expiry and the current time (unix milliseconds); the row is
+// affected only when the lock was acquired.
+const AcquireLockSQL = `
+INSERT INTO locks (name, owner, expires_at) VALUES (%[1]s, %[2]s, %[3]s)
+ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+WHERE locks.owner = excluded.owner OR locks.expires_at < %[4]s`
+
+// ListSnapshotsSQL summarizes stored snapshots, newest first.
+const ListSnapshotsSQL = `
+SELECT snapshot_id, timestamp, COUNT(DISTINCT wallet_address), COUNT(DISTINCT network), COUNT(*)
+FROM snapshots
+GROUP BY snapshot_id, timestamp
+ORDER BY timestamp DESC, snapshot_id DESC`
+
+// GetSnapshotSQL selects the records of one snapshot; the placeholder is substituted per driver.
+const GetSnapshotSQL = `
+SELECT snapshot_id, timestamp, wallet_address, network, asset, token_address, decimals, balance, nonce
+FROM snapshots
+WHERE snapshot_id = %s
+ORDER BY wallet_address, network, token_address`
+
+// ColumnMigration describes a column added to an existing table after its initial version.
+type ColumnMigration struct {
+	Table      string
+	Column     string
+	Definition string
+}
+
+// ColumnMigrations lists columns that stores add to existing tables on startup.
+var ColumnMigrations = []ColumnMigration{
+	{Table: "transactions", Column: "outputs", Definition: "TEXT"},
+	{Table: "transactions", Column: "error_class", Definition: "VARCHAR(50)"},
+	{Table: "transactions", Column: "idempotency_key", Definition: "VARCHAR(66)"},
+}
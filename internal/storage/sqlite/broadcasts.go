@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"retro/internal/storage"
+	"retro/internal/types"
+)
+
+// SaveBroadcast inserts or replaces a record in the SQLite 'broadcasts' table.
+func (s *store) SaveBroadcast(ctx context.Context, record storage.BroadcastRecord) error {
+	query := `INSERT INTO broadcasts (tx_hash, idempotency_key, timestamp, wallet_address, network, nonce, to_address, value_wei, status, fee_wei)
+	           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	           ON CONFLICT (tx_hash) DO UPDATE SET status = excluded.status, fee_wei = excluded.fee_wei`
+	_, err := s.db.ExecContext(ctx, query,
+		record.TxHash,
+		record.IdempotencyKey,
+		record.Timestamp,
+		record.WalletAddress,
+		record.Network,
+		int64(record.Nonce),
+		record.To,
+		record.ValueWei,
+		string(record.Status),
+		record.FeeWei,
+	)
+	if err != nil {
+		s.log.Error("Failed to save broadcast record in SQLite", "tx_hash", record.TxHash, "error", err)
+		return fmt.Errorf("failed to save sqlite broadcast %s: %w", record.TxHash, err)
+	}
+	s.log.Debug("Broadcast record saved to SQLite DB", "tx_hash", record.TxHash, "status", record.Status)
+	return nil
+}
+
+// UpdateBroadcastStatus sets the status and fee of a record in the SQLite 'broadcasts' table.
+func (s *store) UpdateBroadcastStatus(ctx context.Context, txHash string, status types.BroadcastStatus, feeWei string) error {
+	query := `UPDATE broadcasts SET status = ?, fee_wei = ? WHERE tx_hash = ?`
+	if _, err := s.db.ExecContext(ctx, query, string(status), feeWei, txHash); err != nil {
+		s.log.Error("Failed to update broadcast status in SQLite", "tx_hash", txHash, "error", err)
+		return fmt.Errorf("failed to update sqlite broadcast %s: %w", txHash, err)
+	}
+	s.log.Debug("Broadcast status updated in SQLite DB", "tx_hash", txHash, "status", status)
+	return nil
+}
+
+// ListBroadcasts returns records from the SQLite 'broadcasts' table matching the filter, oldest first.
+func (s *store) ListBroadcasts(ctx context.Context, filter storage.BroadcastFilter) ([]storage.BroadcastRecord, error) {
+	query, args := storage.BuildListBroadcastsQuery(filter, func(int) string { return "?" })
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Failed to query broadcasts from SQLite DB", "error", err)
+		return nil, fmt.Errorf("failed to query sqlite broadcasts: %w", err)
+	}
+	defer rows.Close()
+
+	var records []storage.BroadcastRecord
+	for rows.Next() {
+		var (
+			record               storage.BroadcastRecord
+			nonce                int64
+			status               string
+			to, valueWei, feeWei sql.NullString
+		)
+		if err := rows.Scan(&record.TxHash, &record.IdempotencyKey, &record.Timestamp, &record.WalletAddress,
+			&record.Network, &nonce, &to, &valueWei, &status, &feeWei); err != nil {
+			return nil, fmt.Errorf("failed to scan sqlite broadcast row: %w", err)
+		}
+		record.Nonce = uint64(nonce)
+		record.To = to.String
+		record.ValueWei = valueWei.String
+		record.Status = types.BroadcastStatus(status)
+		record.FeeWei = feeWei.String
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sqlite broadcast rows: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"retro/internal/storage"
+)
+
+// SaveSnapshot inserts all records of a snapshot into the SQLite 'snapshots' table in one transaction.
+func (s *store) SaveSnapshot(ctx context.Context, records []storage.SnapshotRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin sqlite snapshot transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO snapshots (snapshot_id, timestamp, wallet_address, network, asset, token_address, decimals, balance, nonce)
+	           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, record := range records {
+		_, err := tx.ExecContext(ctx, query,
+			record.SnapshotID,
+			record.Timestamp,
+			record.WalletAddress,
+			record.Network,
+			record.Asset,
+			record.TokenAddress,
+			int(record.Decimals),
+			record.Balance,
+			int64(record.Nonce),
+		)
+		if err != nil {
+			s.log.Error("Failed to save snapshot record in SQLite", "snapshot_id", record.SnapshotID, "error", err)
+			return fmt.Errorf("failed to save sqlite snapshot %s: %w", record.SnapshotID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sqlite snapshot: %w", err)
+	}
+	s.log.Debug("Snapshot saved to SQLite DB", "records", len(records))
+	return nil
+}
+
+// ListSnapshots returns summaries of the snapshots in the SQLite 'snapshots' table, newest first.
+func (s *store) ListSnapshots(ctx context.Context) ([]storage.SnapshotSummary, error) {
+	rows, err := s.db.QueryContext(ctx, storage.ListSnapshotsSQL)
+	if err != nil {
+		s.log.Error("Failed to query snapshots from SQLite DB", "error", err)
+		return nil, fmt.Errorf("failed to query sqlite snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []storage.SnapshotSummary
+	for rows.Next() {
+		var summary storage.SnapshotSummary
+		if err := rows.Scan(&summary.SnapshotID, &summary.Timestamp, &summary.Wallets, &summary.Networks, &summary.Records); err != nil {
+			return nil, fmt.Errorf("failed to scan sqlite snapshot row: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sqlite snapshot rows: %w", err)
+	}
+	return summaries, nil
+}
+
+// GetSnapshot returns the records of a snapshot from the SQLite 'snapshots' table.
+func (s *store) GetSnapshot(ctx context.Context, snapshotID string) ([]storage.SnapshotRecord, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(storage.GetSnapshotSQL, "?"), snapshotID)
+	if err != nil {
+		s.log.Error("Failed to query snapshot from SQLite DB", "snapshot_id", snapshotID, "error", err)
+		return nil, fmt.Errorf("failed to query sqlite snapshot %s: %w", snapshotID, err)
+	}
+	defer rows.Close()
+
+	var records []storage.SnapshotRecord
+	for rows.Next() {
+		var (
+			record   storage.SnapshotRecord
+			decimals int
+			nonce    int64
+		)
+		if err := rows.Scan(&record.SnapshotID, &record.Timestamp, &record.WalletAddress, &record.Network,
+			&record.Asset, &record.TokenAddress, &decimals, &record.Balance, &nonce); err != nil {
+			return nil, fmt.Errorf("failed to scan sqlite snapshot record: %w", err)
+		}
+		record.Decimals = uint8(decimals)
+		record.Nonce = uint64(nonce)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sqlite snapshot records: %w", err)
+	}
+	return records, nil
+}
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"retro/internal/logger"
 	"retro/internal/storage"
+	"retro/internal/types"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -36,15 +38,74 @@ func NewStore(
 	}
 	log.Info("Table 'application_state' initialized successfully (or already existed).")
 
+	if _, err := db.ExecContext(ctx, storage.CreateBroadcastsTableSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create broadcasts table in sqlite: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, storage.CreateBroadcastsKeyIndexSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create broadcasts index in sqlite: %w", err)
+	}
+	log.Info("Table 'broadcasts' initialized successfully (or already existed).")
+
+	if _, err := db.ExecContext(ctx, storage.CreateSnapshotsTableSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create snapshots table in sqlite: %w", err)
+	}
+	log.Info("Table 'snapshots' initialized successfully (or already existed).")
+
+	if _, err := db.ExecContext(ctx, storage.CreateLocksTableSQL); err != nil {
+		return nil, nil, fmt.Errorf("failed to create locks table in sqlite: %w", err)
+	}
+	log.Info("Table 'locks' initialized successfully (or already existed).")
+
+	if err := applyColumnMigrations(ctx, db); err != nil {
+		return nil, nil, err
+	}
+	log.Debug("Column migrations applied.", "count", len(storage.ColumnMigrations))
+
 	log.Success("SQLite schema initialized.")
 	s := &store{db: db, log: log}
 	return s, s, nil
 }
 
+// applyColumnMigrations adds columns missing from existing tables (SQLite has no ADD COLUMN IF NOT EXISTS).
+func applyColumnMigrations(ctx context.Context, db *sql.DB) error {
+	for _, migration := range storage.ColumnMigrations {
+		rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", migration.Table))
+		if err != nil {
+			return fmt.Errorf("failed to read sqlite table info for %s: %w", migration.Table, err)
+		}
+		exists := false
+		for rows.Next() {
+			var (
+				cid          int
+				name, ctype  string
+				notNull, pk  int
+				defaultValue sql.NullString
+			)
+			if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultValue, &pk); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan sqlite table info for %s: %w", migration.Table, err)
+			}
+			if name == migration.Column {
+				exists = true
+			}
+		}
+		rows.Close()
+		if exists {
+			continue
+		}
+
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", migration.Table, migration.Column, migration.Definition)
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to add column %s.%s in sqlite: %w", migration.Table, migration.Column, err)
+		}
+	}
+	return nil
+}
+
 // LogTransaction saves a transaction record to the SQLite database.
 func (s *store) LogTransaction(ctx context.Context, record storage.TransactionRecord) error {
-	query := `INSERT INTO transactions (timestamp, wallet_address, task_name, network, tx_hash, status, error_message)
-               VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO transactions (timestamp, wallet_address, task_name, network, tx_hash, status, error_message, error_class, outputs, idempotency_key)
+               VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := s.db.ExecContext(ctx, query,
 		record.Timestamp,
@@ -54,6 +115,9 @@ func (s *store) LogTransaction(ctx context.Context, record storage.TransactionRe
 		record.TxHash,
 		string(record.Status),
 		record.Error,
+		string(record.ErrorClass),
+		record.Outputs,
+		record.IdempotencyKey,
 	)
 
 	if err != nil {
@@ -65,6 +129,42 @@ func (s *store) LogTransaction(ctx context.Context, record storage.TransactionRe
 	return nil
 }
 
+// ListTransactions returns records from the SQLite 'transactions' table matching the filter, newest first.
+func (s *store) ListTransactions(ctx context.Context, filter storage.TransactionFilter) ([]storage.TransactionRecord, error) {
+	query, args := storage.BuildListTransactionsQuery(filter, func(int) string { return "?" })
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.log.Error("Failed to query transactions from SQLite DB", "error", err)
+		return nil, fmt.Errorf("failed to query transactions in sqlite: %w", err)
+	}
+	defer rows.Close()
+
+	var records []storage.TransactionRecord
+	for rows.Next() {
+		var (
+			record                                  storage.TransactionRecord
+			taskName, status                        string
+			txHash, errText, errClass, outputs, key sql.NullString
+		)
+		if err := rows.Scan(&record.Timestamp, &record.WalletAddress, &taskName, &record.Network,
+			&txHash, &status, &errText, &errClass, &outputs, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction row in sqlite: %w", err)
+		}
+		record.TaskName = types.TaskName(taskName)
+		record.Status = types.TxStatus(status)
+		record.TxHash = txHash.String
+		record.Error = errText.String
+		record.ErrorClass = types.ErrorClass(errClass.String)
+		record.Outputs = outputs.String
+		record.IdempotencyKey = key.String
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate transaction rows in sqlite: %w", err)
+	}
+	return records, nil
+}
+
 // GetState retrieves a value from the application_state table.
 func (s *store) GetState(ctx context.Context, key string) (string, error) {
 	query := `SELECT value FROM application_state WHERE key = ?`
@@ -94,6 +194,64 @@ func (s *store) SetState(ctx context.Context, key, value string) error {
 	return nil
 }
 
+// ListState returns all rows of the application_state table.
+func (s *store) ListState(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT key, value FROM application_state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state in sqlite: %w", err)
+	}
+	defer rows.Close()
+
+	state := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan state row from sqlite: %w", err)
+		}
+		state[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate state rows in sqlite: %w", err)
+	}
+	return state, nil
+}
+
+// DeleteState removes a key from the application_state table.
+func (s *store) DeleteState(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM application_state WHERE key = ?`, key); err != nil {
+		s.log.Error("Failed to delete state in SQLite DB", "key", key, "error", err)
+		return fmt.Errorf("failed to delete state in sqlite for key '%s': %w", key, err)
+	}
+	s.log.Debug("State deleted from SQLite DB", "key", key)
+	return nil
+}
+
+// AcquireLock takes or extends a row of the locks table.
+func (s *store) AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	query := fmt.Sprintf(storage.AcquireLockSQL, "?", "?", "?", "?")
+	result, err := s.db.ExecContext(ctx, query, name, owner, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		s.log.Error("Failed to acquire lock in SQLite DB", "lock", name, "error", err)
+		return false, fmt.Errorf("failed to acquire sqlite lock '%s': %w", name, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire sqlite lock '%s': %w", name, err)
+	}
+	return affected > 0, nil
+}
+
+// ReleaseLock deletes the row of the locks table if it belongs to the owner.
+func (s *store) ReleaseLock(ctx context.Context, name, owner string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM locks WHERE name = ? AND owner = ?`, name, owner); err != nil {
+		s.log.Error("Failed to release lock in SQLite DB", "lock", name, "error", err)
+		return fmt.Errorf("failed to release sqlite lock '%s': %w", name, err)
+	}
+	s.log.Debug("Lock released in SQLite DB", "lock", name)
+	return nil
+}
+
 // Close closes the database connection.
 func (s *store) Close() error {
 	s.log.Info("Closing SQLite database connection...")
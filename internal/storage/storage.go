@@ -17,12 +17,100 @@ type TransactionRecord struct {
 	TxHash        string         `json:"tx_hash,omitempty"`
 	Status        types.TxStatus `json:"status"`
 	Error         string         `json:"error,omitempty"`
+	// ErrorClass is the final classification of Error (retryable, non_retryable or fatal).
+	ErrorClass types.ErrorClass `json:"error_class,omitempty"`
+	// Outputs holds the JSON-encoded step outputs written to the run context.
+	Outputs string `json:"outputs,omitempty"`
+	// IdempotencyKey identifies the task step across retries and process restarts.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// BroadcastRecord represents a single signed transaction handed to a node by a task.
+// It is written before the transaction is sent, so a crash never leaves an unknown broadcast behind.
+type BroadcastRecord struct {
+	TxHash         string                `json:"tx_hash"`
+	IdempotencyKey string                `json:"idempotency_key"`
+	Timestamp      time.Time             `json:"timestamp"`
+	WalletAddress  string                `json:"wallet_address"`
+	Network        string                `json:"network"`
+	Nonce          uint64                `json:"nonce"`
+	To             string                `json:"to,omitempty"`
+	ValueWei       string                `json:"value_wei,omitempty"`
+	Status         types.BroadcastStatus `json:"status"`
+	// FeeWei is the fee paid for a mined or reverted transaction.
+	FeeWei string `json:"fee_wei,omitempty"`
+}
+
+// SnapshotRecord is the balance of one asset of a wallet in one network at the time of a snapshot.
+type SnapshotRecord struct {
+	SnapshotID    string    `json:"snapshot_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	WalletAddress string    `json:"wallet_address"`
+	Network       string    `json:"network"`
+	// Asset is the token symbol, or the native coin symbol.
+	Asset string `json:"asset"`
+	// TokenAddress is empty for the native coin.
+	TokenAddress string `json:"token_address,omitempty"`
+	Decimals     uint8  `json:"decimals"`
+	// Balance is the amount in the asset's smallest units.
+	Balance string `json:"balance"`
+	// Nonce is the wallet's transaction count in the network.
+	Nonce uint64 `json:"nonce"`
+}
+
+// SnapshotSummary describes a stored snapshot.
+type SnapshotSummary struct {
+	SnapshotID string    `json:"snapshot_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Wallets    int       `json:"wallets"`
+	Networks   int       `json:"networks"`
+	Records    int       `json:"records"`
+}
+
+// BroadcastFilter narrows down the records returned by ListBroadcasts.
+// Zero-valued fields are not applied.
+type BroadcastFilter struct {
+	IdempotencyKey string
+	WalletAddress  string
+	Network        string
+	Status         types.BroadcastStatus
+	Since          time.Time
+	Until          time.Time
+}
+
+// TransactionFilter narrows down the records returned by ListTransactions.
+// Zero-valued fields are not applied.
+type TransactionFilter struct {
+	WalletAddress string
+	TaskName      types.TaskName
+	Network       string
+	Status        types.TxStatus
+	Since         time.Time
+	Until         time.Time
+	// IdempotencyKey selects the records of one task step.
+	IdempotencyKey string
+	// Limit caps the number of returned records (0 = no limit).
+	Limit int
 }
 
 // TransactionLogger defines the interface for storing transaction history.
 type TransactionLogger interface {
 	// LogTransaction saves a record of an attempted or completed transaction.
 	LogTransaction(ctx context.Context, record TransactionRecord) error
+	// ListTransactions returns records matching the filter, newest first.
+	ListTransactions(ctx context.Context, filter TransactionFilter) ([]TransactionRecord, error)
+	// SaveBroadcast inserts or replaces a broadcast transaction record.
+	SaveBroadcast(ctx context.Context, record BroadcastRecord) error
+	// UpdateBroadcastStatus sets the status and the paid fee of a broadcast transaction.
+	UpdateBroadcastStatus(ctx context.Context, txHash string, status types.BroadcastStatus, feeWei string) error
+	// ListBroadcasts returns broadcast records matching the filter, oldest first.
+	ListBroadcasts(ctx context.Context, filter BroadcastFilter) ([]BroadcastRecord, error)
+	// SaveSnapshot stores all records of one snapshot atomically.
+	SaveSnapshot(ctx context.Context, records []SnapshotRecord) error
+	// ListSnapshots returns summaries of stored snapshots, newest first.
+	ListSnapshots(ctx context.Context) ([]SnapshotSummary, error)
+	// GetSnapshot returns the records of a snapshot (none if it does not exist).
+	GetSnapshot(ctx context.Context, snapshotID string) ([]SnapshotRecord, error)
 	// Close closes any underlying resources (like database connections).
 	Close() error
 }
@@ -35,6 +123,15 @@ type StateStorage interface {
 	GetState(ctx context.Context, key string) (string, error)
 	// SetState saves a key-value pair.
 	SetState(ctx context.Context, key, value string) error
+	// ListState returns all stored key-value pairs.
+	ListState(ctx context.Context) (map[string]string, error)
+	// DeleteState removes a key; removing a missing key is not an error.
+	DeleteState(ctx context.Context, key string) error
+	// AcquireLock takes the named lock for the owner until ttl passes, or extends it if the owner
+	// already holds it. It reports false if another owner holds an unexpired lock.
+	AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// ReleaseLock frees the lock if it is held by the owner.
+	ReleaseLock(ctx context.Context, name, owner string) error
 	// Close releases any resources used by the storage.
 	Close() error
 }
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"retro/internal/utils"
+)
+
+var (
+	rndMu sync.Mutex
+	// rnd is the source of random amounts.
+	rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Amount is an amount parameter: a fixed value ("0.01"), a random value in a range ("0.01-0.02"),
+// a share of the available balance ("25%") or a random share in a range ("20%-40%").
+type Amount struct {
+	// min and max are decimal amounts in token units, or percents when percent is set.
+	min, max string
+	percent  bool
+}
+
+// AmountParam parses an amount parameter; name is used in the error.
+func AmountParam(value interface{}, name string) (Amount, error) {
+	s := strings.ReplaceAll(fmt.Sprint(value), " ", "")
+	invalid := fmt.Errorf("параметр '%s' должен быть суммой (0.01), диапазоном (0.01-0.02) или долей баланса (25%% или 20%%-40%%), получено '%v'", name, value)
+	if value == nil || s == "" {
+		return Amount{}, invalid
+	}
+	minPart, maxPart, isRange := strings.Cut(s, "-")
+	if !isRange {
+		maxPart = minPart
+	}
+	var amount Amount
+	amount.percent = strings.HasSuffix(minPart, "%")
+	if strings.HasSuffix(maxPart, "%") != amount.percent {
+		return Amount{}, invalid
+	}
+	amount.min, amount.max = strings.TrimSuffix(minPart, "%"), strings.TrimSuffix(maxPart, "%")
+	low, errLow := strconv.ParseFloat(amount.min, 64)
+	high, errHigh := strconv.ParseFloat(amount.max, 64)
+	if errLow != nil || errHigh != nil || low <= 0 || high < low || amount.percent && high > 100 {
+		return Amount{}, invalid
+	}
+	return amount, nil
+}
+
+// IsPercent reports whether the amount is a share of the available balance.
+func (a Amount) IsPercent() bool {
+	return a.percent
+}
+
+// Resolve picks the amount in the smallest units. A share is taken of available; a random value
+// is rounded down to a random number of decimal places (3 to 6), so amounts look like manual ones.
+func (a Amount) Resolve(available *big.Int, decimals uint8) (*big.Int, error) {
+	if a.percent {
+		low, _ := strconv.ParseFloat(a.min, 64)
+		high, _ := strconv.ParseFloat(a.max, 64)
+		// The share is picked in hundredths of a percent.
+		basisPoints := int64(low * 100)
+		if spread := int64(high*100) - basisPoints; spread > 0 {
+			basisPoints += randInt63n(spread + 1)
+		}
+		amount := new(big.Int).Mul(available, big.NewInt(basisPoints))
+		return roundAmount(amount.Div(amount, big.NewInt(10000)), decimals), nil
+	}
+
+	low, err := utils.ToUnits(a.min, decimals)
+	if err != nil {
+		return nil, err
+	}
+	if a.min == a.max {
+		return low, nil
+	}
+	high, err := utils.ToUnits(a.max, decimals)
+	if err != nil {
+		return nil, err
+	}
+	amount := new(big.Int).Add(low, randBigInt(new(big.Int).Sub(high, low)))
+	if rounded := roundAmount(amount, decimals); rounded.Cmp(low) >= 0 {
+		return rounded, nil
+	}
+	return amount, nil
+}
+
+// String returns the amount as written in the config.
+func (a Amount) String() string {
+	suffix := ""
+	if a.percent {
+		suffix = "%"
+	}
+	if a.min == a.max {
+		return a.min + suffix
+	}
+	return a.min + suffix + "-" + a.max + suffix
+}
+
+// roundAmount rounds the amount down to a random number of decimal places; an amount that would
+// round down to zero is left as is.
+func roundAmount(amount *big.Int, decimals uint8) *big.Int {
+	precision := 3 + int(randInt63n(4))
+	if int(decimals) <= precision {
+		return amount
+	}
+	step := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(int(decimals)-precision)), nil)
+	rounded := new(big.Int).Sub(amount, new(big.Int).Mod(amount, step))
+	if rounded.Sign() == 0 {
+		return amount
+	}
+	return rounded
+}
+
+// randInt63n returns a random number in [0, n).
+func randInt63n(n int64) int64 {
+	rndMu.Lock()
+	defer rndMu.Unlock()
+	return rnd.Int63n(n)
+}
+
+// randBigInt returns a random number in [0, n].
+func randBigInt(n *big.Int) *big.Int {
+	rndMu.Lock()
+	defer rndMu.Unlock()
+	return new(big.Int).Rand(rnd, new(big.Int).Add(n, big.NewInt(1)))
+}
@@ -0,0 +1,301 @@
+// Package bridge implements the bridge task: it deposits the native coin from L1 to an L2 through
+// the canonical bridge of an OP-stack chain (L1StandardBridge.depositETH) or Arbitrum (Inbox.depositEth).
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/runctx"
+	"retro/internal/tasks"
+	"retro/internal/tasks/wait"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Step outputs of the task, in wei.
+const (
+	// OutputAmount is the deposited amount.
+	OutputAmount = "amount"
+	// OutputReceived is the increase of the L2 balance, set when the task waits for it.
+	OutputReceived = "received"
+)
+
+// Kind is the bridge protocol of a target.
+type Kind string
+
+const (
+	KindOPStack  Kind = "op_stack"
+	KindArbitrum Kind = "arbitrum"
+)
+
+// defaultMinGasLimit is the L2 gas limit of OP-stack deposits, as used by the OP SDK.
+const defaultMinGasLimit = 200000
+
+// mainnetChainID is the chain of the default bridge contracts.
+const mainnetChainID = 1
+
+// target is the bridge of an L2 on L1.
+type target struct {
+	kind     Kind
+	contract common.Address
+}
+
+// mainnetTargets are the bridge contracts on Ethereum mainnet of the known L2 networks.
+var mainnetTargets = map[string]target{
+	"arbitrum": {kind: KindArbitrum, contract: common.HexToAddress("0x4Dbd4fc535Ac27206064B68FfCf827b0A60BAB3f")},
+	"optimism": {kind: KindOPStack, contract: common.HexToAddress("0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1")},
+	"base":     {kind: KindOPStack, contract: common.HexToAddress("0x3154Cf16ccdb4C6d922629664174b904d80F2C35")},
+}
+
+const bridgeABIJSON = `[
+	{"inputs":[{"name":"_minGasLimit","type":"uint32"},{"name":"_extraData","type":"bytes"}],"name":"depositETH","outputs":[],"stateMutability":"payable","type":"function"},
+	{"inputs":[],"name":"depositEth","outputs":[{"name":"","type":"uint256"}],"stateMutability":"payable","type":"function"}
+]`
+
+// bridgeABI holds the deposit functions of L1StandardBridge and of the Arbitrum Inbox.
+var bridgeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(bridgeABIJSON))
+	if err != nil {
+		panic(fmt.Sprintf("invalid bridge ABI: %v", err))
+	}
+	return parsed
+}()
+
+// Task deposits the native coin to an L2.
+type Task struct {
+	log logger.Logger
+}
+
+var _ tasks.TaskRunner = (*Task)(nil)
+
+// NewTask creates a new bridge task.
+func NewTask(log logger.Logger) tasks.TaskRunner {
+	return &Task{log: log}
+}
+
+// ParamSchema implements tasks.ParamDescriber.
+func (t *Task) ParamSchema() []tasks.ParamSpec {
+	return []tasks.ParamSpec{
+		{Name: "target", Type: "string", Required: true, Description: "сеть L2 из rpc_nodes; для arbitrum, optimism и base в Ethereum mainnet контракт и тип известны"},
+		{Name: "amount", Type: "amount", Required: true, Description: "сумма (0.01), диапазон (0.01-0.02) или доля доступного баланса (25% или 20%-40%)"},
+		{Name: "keep", Type: "decimal", Description: "нативная монета, остающаяся на L1 после депозита и комиссии"},
+		{Name: "type", Type: "string", Description: "протокол моста: op_stack или arbitrum"},
+		{Name: "contract", Type: "address", Description: "L1StandardBridge (op_stack) или Inbox (arbitrum) в сети задачи"},
+		{Name: "min_gas_limit", Type: "int", Description: "лимит газа депозита на L2 для op_stack (по умолчанию 200000)"},
+		{Name: "wait", Type: "bool", Description: "дождаться поступления суммы на L2"},
+		{Name: "wait_timeout", Type: "duration", Description: "наибольшее время ожидания на L2 (по умолчанию 30m)"},
+	}
+}
+
+// params are the parsed task parameters.
+type params struct {
+	target      string
+	amount      tasks.Amount
+	keep        *big.Int
+	kind        Kind
+	contract    *common.Address
+	minGasLimit uint32
+	wait        bool
+	waitTimeout time.Duration
+}
+
+// Run deposits the chosen amount through the bridge of the target and, with wait, waits until
+// the L2 balance rises by the deposit.
+func (t *Task) Run(ctx context.Context, signer *evm.Signer, client evm.EVMClient, raw map[string]interface{}) error {
+	p, err := parseParams(raw)
+	if err != nil {
+		return errclass.NonRetryable(err)
+	}
+	bridge, err := resolveTarget(p, client.GetChainID())
+	if err != nil {
+		return errclass.NonRetryable(err)
+	}
+	data, err := depositData(bridge.kind, p.minGasLimit)
+	if err != nil {
+		return errclass.NonRetryable(err)
+	}
+	wallet := signer.Address()
+
+	// The L2 client and balance are read before the deposit, so a missing network fails the task
+	// before any funds are sent and the balance cannot change before the baseline is taken.
+	var l2Client evm.EVMClient
+	var l2Baseline *big.Int
+	_, dryRun := client.(*evm.DryRunClient)
+	if p.wait && !dryRun {
+		if l2Client, err = evm.ClientFromContext(ctx, p.target); err != nil {
+			return err
+		}
+		if l2Client == nil {
+			return errclass.NonRetryable(fmt.Errorf("сеть '%s' недоступна для ожидания баланса", p.target))
+		}
+		if l2Baseline, err = l2Client.GetBalance(ctx, wallet); err != nil {
+			return err
+		}
+	}
+
+	tx, err := t.buildDeposit(ctx, client, wallet, bridge.contract, data, p)
+	if err != nil {
+		return err
+	}
+	receipt, err := evm.SignAndSendTx(ctx, client, signer, tx)
+	if err != nil {
+		return fmt.Errorf("депозит в мост %s: %w", p.target, err)
+	}
+	step := runctx.StepFromContext(ctx)
+	step.SetAmount(OutputAmount, tx.Value())
+	t.log.Success("Депозит в мост отправлен", "wallet", wallet.Hex(), "target", p.target, "bridge", string(bridge.kind),
+		"amount", utils.FromWei(tx.Value()), "symbol", client.Network().NativeSymbol, "tx_hash", receipt.TxHash.Hex())
+
+	if l2Client == nil {
+		return nil
+	}
+	watch := wait.Watch{Baseline: l2Baseline, MinIncrease: tx.Value(), Timeout: p.waitTimeout}
+	_, received, err := watch.Run(ctx, l2Client, wallet, t.log)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		// The deposit is sent: a retry would bridge the funds again.
+		return errclass.NonRetryable(fmt.Errorf("депозит %s отправлен, но средства не поступили в %s: %w",
+			receipt.TxHash.Hex(), p.target, err))
+	}
+	step.SetAmount(OutputReceived, received)
+	return nil
+}
+
+// buildDeposit prepares the deposit transaction: the fee is estimated first, and the amount is
+// taken from the balance left after the fee and the L1 reserve.
+func (t *Task) buildDeposit(ctx context.Context, client evm.EVMClient, wallet, contract common.Address, data []byte, p params) (*types.Transaction, error) {
+	balance, err := client.GetBalance(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+	estimate, err := evm.BuildTx(ctx, client, wallet, contract, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(estimate.Gas()), estimate.GasFeeCap())
+	available := new(big.Int).Sub(balance, fee)
+	available.Sub(available, p.keep)
+	if available.Sign() <= 0 {
+		return nil, errclass.NonRetryable(fmt.Errorf("недостаточно средств для депозита: баланс %s, комиссия до %s, резерв %s",
+			utils.FromWei(balance), utils.FromWei(fee), utils.FromWei(p.keep)))
+	}
+	amount, err := p.amount.Resolve(available, 18)
+	if err != nil {
+		return nil, errclass.NonRetryable(err)
+	}
+	if amount.Sign() <= 0 || amount.Cmp(available) > 0 {
+		return nil, errclass.NonRetryable(fmt.Errorf("сумма депозита %s недоступна: доступно %s после комиссии и резерва",
+			utils.FromWei(amount), utils.FromWei(available)))
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   estimate.ChainId(),
+		Nonce:     estimate.Nonce(),
+		GasTipCap: estimate.GasTipCap(),
+		GasFeeCap: estimate.GasFeeCap(),
+		Gas:       estimate.Gas(),
+		To:        &contract,
+		Value:     amount,
+		Data:      data,
+	}), nil
+}
+
+// resolveTarget returns the bridge of the target: the configured contract and type, or the
+// known mainnet ones.
+func resolveTarget(p params, chainID *big.Int) (target, error) {
+	known, isKnown := mainnetTargets[p.target]
+	isKnown = isKnown && chainID != nil && chainID.Cmp(big.NewInt(mainnetChainID)) == 0
+	var bridge target
+	switch {
+	case p.contract != nil:
+		bridge.contract = *p.contract
+	case isKnown:
+		bridge.contract = known.contract
+	default:
+		return target{}, fmt.Errorf("не задан 'contract' для цели '%s' (известны только arbitrum, optimism и base в Ethereum mainnet)", p.target)
+	}
+	switch {
+	case p.kind != "":
+		bridge.kind = p.kind
+	case isKnown:
+		bridge.kind = known.kind
+	default:
+		return target{}, fmt.Errorf("не задан 'type' для цели '%s': op_stack или arbitrum", p.target)
+	}
+	return bridge, nil
+}
+
+// depositData returns the calldata of the deposit function of the bridge kind.
+func depositData(kind Kind, minGasLimit uint32) ([]byte, error) {
+	if kind == KindArbitrum {
+		return bridgeABI.Pack("depositEth")
+	}
+	return bridgeABI.Pack("depositETH", minGasLimit, []byte{})
+}
+
+// parseParams reads the task parameters.
+func parseParams(raw map[string]interface{}) (params, error) {
+	p := params{keep: new(big.Int), minGasLimit: defaultMinGasLimit}
+	if raw["target"] != nil {
+		p.target = fmt.Sprint(raw["target"])
+	}
+	if p.target == "" || p.target == "any" {
+		return params{}, errors.New("параметр 'target' должен быть сетью L2 из rpc_nodes")
+	}
+	var err error
+	if p.amount, err = tasks.AmountParam(raw["amount"], "amount"); err != nil {
+		return params{}, err
+	}
+	if value := raw["keep"]; value != nil {
+		p.keep, err = utils.ToWei(fmt.Sprint(value))
+		if err != nil || p.keep.Sign() < 0 {
+			return params{}, fmt.Errorf("параметр 'keep' должен быть неотрицательной суммой, получено '%v'", value)
+		}
+	}
+	if value := raw["type"]; value != nil {
+		p.kind = Kind(fmt.Sprint(value))
+		if p.kind != KindOPStack && p.kind != KindArbitrum {
+			return params{}, fmt.Errorf("параметр 'type' должен быть op_stack или arbitrum, получено '%v'", value)
+		}
+	}
+	if value := raw["contract"]; value != nil {
+		contract, err := tasks.AddressParam(value, "contract")
+		if err != nil {
+			return params{}, err
+		}
+		p.contract = &contract
+	}
+	if value := raw["min_gas_limit"]; value != nil {
+		limit, err := strconv.ParseUint(fmt.Sprint(value), 10, 32)
+		if err != nil || limit == 0 {
+			return params{}, fmt.Errorf("параметр 'min_gas_limit' должен быть положительным целым числом, получено '%v'", value)
+		}
+		p.minGasLimit = uint32(limit)
+	}
+	if value := raw["wait"]; value != nil {
+		if p.wait, err = strconv.ParseBool(fmt.Sprint(value)); err != nil {
+			return params{}, fmt.Errorf("параметр 'wait' должен быть true или false, получено '%v'", value)
+		}
+	}
+	if value := raw["wait_timeout"]; value != nil {
+		p.waitTimeout, err = time.ParseDuration(fmt.Sprint(value))
+		if err != nil || p.waitTimeout <= 0 {
+			return params{}, fmt.Errorf("параметр 'wait_timeout' должен быть длительностью, например 30m, получено '%v'", value)
+		}
+	}
+	return p, nil
+}
@@ -24,6 +24,11 @@ func NewTask(log logger.Logger) tasks.TaskRunner {
 	return &DummyTask{log: log}
 }
 
+// ParamSchema реализует tasks.ParamDescriber: у задачи нет параметров.
+func (dt *DummyTask) ParamSchema() []tasks.ParamSpec {
+	return []tasks.ParamSpec{}
+}
+
 // Run выполняет логику задачи-заглушки.
 func (dt *DummyTask) Run(ctx context.Context, signer *evm.Signer, client evm.EVMClient, params map[string]interface{}) error {
 	walletAddress := signer.Address()
@@ -7,6 +7,7 @@ import (
 
 	"retro/internal/evm"
 	"retro/internal/logger"
+	"retro/internal/runctx"
 	"retro/internal/utils"
 	// "retro/internal/wallet" // No longer needed
 )
@@ -33,11 +34,21 @@ func (t *LogBalanceTask) Run(ctx context.Context, signer *evm.Signer, client evm
 	}
 
 	balanceEtherStr := utils.FromWei(balanceWei)
+	runctx.StepFromContext(ctx).SetAmount("balance", balanceWei)
 
-	t.log.Success("Баланс получен", "wallet", walletAddress.Hex(), "balance_eth", balanceEtherStr)
+	if symbol := client.Network().NativeSymbol; symbol != "" {
+		t.log.Success("Баланс получен", "wallet", walletAddress.Hex(), "balance", balanceEtherStr+" "+symbol)
+	} else {
+		t.log.Success("Баланс получен", "wallet", walletAddress.Hex(), "balance_eth", balanceEtherStr)
+	}
 	return nil
 }
 
+// ParamSchema implements ParamDescriber: the task has no parameters.
+func (t *LogBalanceTask) ParamSchema() []ParamSpec {
+	return []ParamSpec{}
+}
+
 // NewLogBalanceTask creates a new instance of LogBalanceTask.
 func NewLogBalanceTask(log logger.Logger) TaskRunner {
 	return &LogBalanceTask{log: log}
@@ -0,0 +1,29 @@
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AddressParam parses a task parameter holding a hex address; name is used in the error.
+func AddressParam(value interface{}, name string) (common.Address, error) {
+	s := fmt.Sprint(value)
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("параметр '%s' должен быть адресом, получено '%s'", name, s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// ListParam returns a list task parameter; a missing parameter is an empty list.
+func ListParam(params map[string]interface{}, name string) ([]interface{}, error) {
+	raw, ok := params[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("параметр '%s' должен быть списком, получено '%v'", name, raw)
+	}
+	return list, nil
+}
@@ -29,6 +29,12 @@ func MustRegisterConstructor(name types.TaskName, constructor TaskConstructor) {
 	}
 }
 
+// IsRegistered reports whether a constructor is registered under the name.
+func IsRegistered(name types.TaskName) bool {
+	_, ok := constructors.Load(name)
+	return ok
+}
+
 // NewTask creates a new task runner instance by its name.
 func NewTask(name types.TaskName, log logger.Logger) (TaskRunner, error) {
 	value, ok := constructors.Load(name)
@@ -0,0 +1,125 @@
+// Package revoke implements the revoke_approvals task: it sets the chosen ERC-20 allowances of
+// the wallet to zero.
+package revoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"retro/internal/approvals"
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/runctx"
+	"retro/internal/tasks"
+)
+
+// OutputRevoked is the step output holding the number of revoked allowances.
+const OutputRevoked = "revoked"
+
+// Task revokes allowances of the wallet.
+type Task struct {
+	log logger.Logger
+}
+
+var _ tasks.TaskRunner = (*Task)(nil)
+
+// NewTask creates a new revoke_approvals task.
+func NewTask(log logger.Logger) tasks.TaskRunner {
+	return &Task{log: log}
+}
+
+// ParamSchema implements tasks.ParamDescriber.
+func (t *Task) ParamSchema() []tasks.ParamSpec {
+	return []tasks.ParamSpec{
+		{Name: "pairs", Type: "list", Description: "пары {token, spender}, для которых отзывается allowance"},
+		{Name: "from_block", Type: "block", Description: "дополнительно искать пары в событиях Approval кошелька начиная с этого блока"},
+		{Name: "unlimited_only", Type: "bool", Description: "отзывать только неограниченные allowance"},
+	}
+}
+
+// Run reads the allowance of every pair and sends approve(spender, 0) for each non-zero one.
+func (t *Task) Run(ctx context.Context, signer *evm.Signer, client evm.EVMClient, params map[string]interface{}) error {
+	pairs, fromBlock, unlimitedOnly, err := parseParams(params)
+	if err != nil {
+		return errclass.NonRetryable(err)
+	}
+	if len(pairs) == 0 && fromBlock == nil {
+		return errclass.NonRetryable(errors.New("не заданы пары 'pairs' и не указан 'from_block'"))
+	}
+	wallet := signer.Address()
+	if fromBlock != nil {
+		found, err := approvals.PairsFromLogs(ctx, client, wallet, *fromBlock, nil)
+		if err != nil {
+			return err
+		}
+		pairs = approvals.Merge(pairs, found)
+	}
+
+	allowances, err := approvals.Scan(ctx, client, wallet, pairs, unlimitedOnly, t.log)
+	if err != nil {
+		return err
+	}
+	if len(allowances) == 0 {
+		t.log.Info("Открытых allowance для отзыва нет", "wallet", wallet.Hex(), "network", client.Network().Name,
+			"pairs", len(pairs), "unlimited_only", unlimitedOnly)
+	}
+	for i, allowance := range allowances {
+		data, err := evm.ERC20ApproveData(allowance.Spender, big.NewInt(0))
+		if err != nil {
+			return errclass.NonRetryable(err)
+		}
+		receipt, err := evm.SendTx(ctx, client, signer, allowance.Token, nil, data)
+		if err != nil {
+			return fmt.Errorf("отзыв allowance токена %s для %s: %w", allowance.Token.Hex(), allowance.Spender.Hex(), err)
+		}
+		runctx.StepFromContext(ctx).SetString(OutputRevoked, strconv.Itoa(i+1))
+		t.log.Success("Allowance отозван", "wallet", wallet.Hex(), "token", allowance.Token.Hex(),
+			"spender", allowance.Spender.Hex(), "was", allowance.Amount.String(), "tx_hash", receipt.TxHash.Hex())
+	}
+	return nil
+}
+
+// parseParams reads the pairs, the optional first block of the log scan and the unlimited-only filter.
+func parseParams(params map[string]interface{}) ([]approvals.Pair, *uint64, bool, error) {
+	list, err := tasks.ListParam(params, "pairs")
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var pairs []approvals.Pair
+	for i, item := range list {
+		path := fmt.Sprintf("pairs[%d]", i)
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil, false, fmt.Errorf("параметр '%s' должен быть {token, spender}, получено '%v'", path, item)
+		}
+		var pair approvals.Pair
+		if pair.Token, err = tasks.AddressParam(entry["token"], path+".token"); err != nil {
+			return nil, nil, false, err
+		}
+		if pair.Spender, err = tasks.AddressParam(entry["spender"], path+".spender"); err != nil {
+			return nil, nil, false, err
+		}
+		pairs = append(pairs, pair)
+	}
+
+	var fromBlock *uint64
+	if raw, ok := params["from_block"]; ok && raw != nil {
+		block, err := strconv.ParseUint(fmt.Sprint(raw), 10, 64)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("параметр 'from_block' должен быть номером блока, получено '%v'", raw)
+		}
+		fromBlock = &block
+	}
+
+	unlimitedOnly := false
+	if raw, ok := params["unlimited_only"]; ok && raw != nil {
+		if unlimitedOnly, err = strconv.ParseBool(fmt.Sprint(raw)); err != nil {
+			return nil, nil, false, fmt.Errorf("параметр 'unlimited_only' должен быть true или false, получено '%v'", raw)
+		}
+	}
+	return pairs, fromBlock, unlimitedOnly, nil
+}
@@ -0,0 +1,23 @@
+package tasks
+
+// ParamSpec describes one parameter a task reads from its `params` config section.
+type ParamSpec struct {
+	Name string
+	// Type is a human-readable value type, e.g. "address", "wei" or "string".
+	Type        string
+	Required    bool
+	Description string
+}
+
+// ParamDescriber is implemented by tasks that document their parameters (shown by `tasks list`).
+type ParamDescriber interface {
+	ParamSchema() []ParamSpec
+}
+
+// ParamSchemaOf returns the parameter schema of a task, or nil if the task does not describe its parameters.
+func ParamSchemaOf(runner TaskRunner) []ParamSpec {
+	if describer, ok := runner.(ParamDescriber); ok {
+		return describer.ParamSchema()
+	}
+	return nil
+}
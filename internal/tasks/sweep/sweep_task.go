@@ -0,0 +1,169 @@
+// Package sweep implements the sweep task: it sends the listed tokens and then the whole native
+// balance (less the fee) of the wallet to a collector address.
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/runctx"
+	"retro/internal/tasks"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OutputNative is the step output holding the swept native amount in wei. Swept tokens are
+// stored under their checksummed addresses, in the token's smallest units.
+const OutputNative = "native"
+
+// Task sweeps the funds of a wallet to the "to" address.
+type Task struct {
+	log logger.Logger
+}
+
+var _ tasks.TaskRunner = (*Task)(nil)
+
+// NewTask creates a new sweep task.
+func NewTask(log logger.Logger) tasks.TaskRunner {
+	return &Task{log: log}
+}
+
+// ParamSchema implements tasks.ParamDescriber.
+func (t *Task) ParamSchema() []tasks.ParamSpec {
+	return []tasks.ParamSpec{
+		{Name: "to", Type: "address", Required: true, Description: "адрес, на который собираются средства"},
+		{Name: "tokens", Type: "list", Description: "токены, отправляемые перед нативной монетой: адреса или {address, min}; min - порог пыли в единицах токена"},
+		{Name: "min_native", Type: "decimal", Description: "порог пыли нативной монеты: меньшая сумма после комиссии не отправляется"},
+	}
+}
+
+// token is a token to sweep with its dust threshold in token units ("" = any non-zero balance).
+type token struct {
+	address common.Address
+	min     string
+}
+
+// Run sends every listed token balance above its threshold, then the native balance less the fee.
+func (t *Task) Run(ctx context.Context, signer *evm.Signer, client evm.EVMClient, params map[string]interface{}) error {
+	to, tokens, minNative, err := parseParams(params)
+	if err != nil {
+		return errclass.NonRetryable(err)
+	}
+	wallet := signer.Address()
+	if wallet == to {
+		t.log.Info("Кошелек совпадает с адресом сбора, sweep пропущен", "wallet", wallet.Hex())
+		return nil
+	}
+	step := runctx.StepFromContext(ctx)
+
+	for _, tok := range tokens {
+		amount, err := t.sweepToken(ctx, signer, client, tok, to)
+		if err != nil {
+			return err
+		}
+		if amount != nil {
+			step.SetAmount(tok.address.Hex(), amount)
+		}
+	}
+
+	tx, err := evm.BuildSweepTx(ctx, client, wallet, to)
+	if err != nil {
+		return err
+	}
+	if tx == nil || tx.Value().Cmp(minNative) < 0 {
+		t.log.Info("Нативный баланс ниже порога пыли, не отправляется", "wallet", wallet.Hex(),
+			"network", client.Network().Name, "min", utils.FromWei(minNative))
+		return nil
+	}
+	receipt, err := evm.SignAndSendTx(ctx, client, signer, tx)
+	if err != nil {
+		return err
+	}
+	step.SetAmount(OutputNative, tx.Value())
+	t.log.Success("Нативная монета отправлена на адрес сбора", "wallet", wallet.Hex(), "to", to.Hex(),
+		"amount", utils.FromWei(tx.Value()), "symbol", client.Network().NativeSymbol, "tx_hash", receipt.TxHash.Hex())
+	return nil
+}
+
+// sweepToken sends the whole token balance to the collector and returns the amount, or nil when
+// the balance is below the dust threshold.
+func (t *Task) sweepToken(ctx context.Context, signer *evm.Signer, client evm.EVMClient, tok token, to common.Address) (*big.Int, error) {
+	wallet := signer.Address()
+	balance, err := evm.ERC20BalanceOf(ctx, client, tok.address, wallet)
+	if err != nil {
+		return nil, err
+	}
+	minAmount := big.NewInt(1)
+	if tok.min != "" {
+		decimals, err := evm.ERC20Decimals(ctx, client, tok.address)
+		if err != nil {
+			return nil, err
+		}
+		if minAmount, err = utils.ToUnits(tok.min, decimals); err != nil {
+			return nil, errclass.NonRetryable(err)
+		}
+	}
+	if balance.Sign() == 0 || balance.Cmp(minAmount) < 0 {
+		t.log.Info("Баланс токена ниже порога пыли, не отправляется", "wallet", wallet.Hex(),
+			"token", tok.address.Hex(), "balance", balance.String())
+		return nil, nil
+	}
+
+	data, err := evm.ERC20TransferData(to, balance)
+	if err != nil {
+		return nil, errclass.NonRetryable(err)
+	}
+	receipt, err := evm.SendTx(ctx, client, signer, tok.address, nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("перевод токена %s: %w", tok.address.Hex(), err)
+	}
+	t.log.Success("Токен отправлен на адрес сбора", "wallet", wallet.Hex(), "to", to.Hex(),
+		"token", tok.address.Hex(), "amount", balance.String(), "tx_hash", receipt.TxHash.Hex())
+	return balance, nil
+}
+
+// parseParams reads the collector address, the tokens and the native dust threshold in wei.
+func parseParams(params map[string]interface{}) (common.Address, []token, *big.Int, error) {
+	to, err := tasks.AddressParam(params["to"], "to")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	minNative := new(big.Int)
+	if value, ok := params["min_native"]; ok && value != nil {
+		minNative, err = utils.ToWei(fmt.Sprint(value))
+		if err != nil || minNative.Sign() < 0 {
+			return common.Address{}, nil, nil, fmt.Errorf("параметр 'min_native' должен быть неотрицательной суммой, получено '%v'", value)
+		}
+	}
+
+	list, err := tasks.ListParam(params, "tokens")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	var tokens []token
+	for i, item := range list {
+		path := fmt.Sprintf("tokens[%d]", i)
+		var tok token
+		switch v := item.(type) {
+		case map[string]interface{}:
+			if tok.address, err = tasks.AddressParam(v["address"], path+".address"); err != nil {
+				return common.Address{}, nil, nil, err
+			}
+			if v["min"] != nil {
+				tok.min = fmt.Sprint(v["min"])
+			}
+		default:
+			if tok.address, err = tasks.AddressParam(v, path); err != nil {
+				return common.Address{}, nil, nil, err
+			}
+		}
+		tokens = append(tokens, tok)
+	}
+	return to, tokens, minNative, nil
+}
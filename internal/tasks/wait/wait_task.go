@@ -0,0 +1,264 @@
+// Package wait implements the wait_for_balance task: it polls the native or token balance of the
+// wallet until the expected funds arrive, e.g. after a bridge deposit on another network.
+package wait
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"retro/internal/errclass"
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/runctx"
+	"retro/internal/tasks"
+	"retro/internal/types"
+	"retro/internal/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Step outputs of the task, in the smallest units of the asset.
+const (
+	// OutputBalance is the balance that satisfied the wait.
+	OutputBalance = "balance"
+	// OutputReceived is the increase of the balance over the baseline.
+	OutputReceived = "received"
+)
+
+const (
+	defaultTimeout         = 30 * time.Minute
+	defaultPollInterval    = 10 * time.Second
+	defaultMaxPollInterval = 2 * time.Minute
+)
+
+// ErrWaitTimeout means the expected balance did not arrive within the timeout.
+var ErrWaitTimeout = errors.New("баланс не достиг ожидаемого значения за отведенное время")
+
+// Task waits for the balance of the wallet.
+type Task struct {
+	log logger.Logger
+}
+
+var _ tasks.TaskRunner = (*Task)(nil)
+
+// NewTask creates a new wait_for_balance task.
+func NewTask(log logger.Logger) tasks.TaskRunner {
+	return &Task{log: log}
+}
+
+// ParamSchema implements tasks.ParamDescriber.
+func (t *Task) ParamSchema() []tasks.ParamSpec {
+	return []tasks.ParamSpec{
+		{Name: "token", Type: "address", Description: "токен, баланс которого ожидается; без него - нативная монета"},
+		{Name: "min_increase", Type: "decimal", Description: "ожидаемый прирост баланса относительно baseline"},
+		{Name: "baseline", Type: "units", Description: "исходный баланс в минимальных единицах, например {{ steps.log_balance.balance }}; по умолчанию - баланс при запуске задачи"},
+		{Name: "min_balance", Type: "decimal", Description: "абсолютный порог баланса; ожидание завершается при выполнении любого из условий"},
+		{Name: "timeout", Type: "duration", Description: "наибольшее время ожидания (по умолчанию 30m)"},
+		{Name: "poll_interval", Type: "duration", Description: "первый интервал опроса (по умолчанию 10s), затем удваивается"},
+		{Name: "max_poll_interval", Type: "duration", Description: "наибольший интервал опроса (по умолчанию 2m)"},
+	}
+}
+
+// params are the parsed task parameters; amounts stay decimal strings until the decimals are known.
+type params struct {
+	token           *common.Address
+	minIncrease     string
+	baseline        *big.Int
+	minBalance      string
+	timeout         time.Duration
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+}
+
+// Run polls the balance with a doubling interval until it rises by min_increase over the baseline
+// or reaches min_balance. The task fails when the timeout passes first.
+func (t *Task) Run(ctx context.Context, signer *evm.Signer, client evm.EVMClient, raw map[string]interface{}) error {
+	p, err := parseParams(raw)
+	if err != nil {
+		return errclass.NonRetryable(err)
+	}
+	watch := Watch{
+		Token:           p.token,
+		Baseline:        p.baseline,
+		Timeout:         p.timeout,
+		PollInterval:    p.pollInterval,
+		MaxPollInterval: p.maxPollInterval,
+	}
+	decimals := uint8(18)
+	if p.token != nil {
+		if decimals, err = evm.ERC20Decimals(ctx, client, *p.token); err != nil {
+			return err
+		}
+		watch.Decimals = decimals
+	}
+	if p.minIncrease != "" {
+		if watch.MinIncrease, err = utils.ToUnits(p.minIncrease, decimals); err != nil {
+			return errclass.NonRetryable(err)
+		}
+	}
+	if p.minBalance != "" {
+		if watch.MinBalance, err = utils.ToUnits(p.minBalance, decimals); err != nil {
+			return errclass.NonRetryable(err)
+		}
+	}
+
+	balance, received, err := watch.Run(ctx, client, signer.Address(), t.log)
+	if err != nil {
+		return err
+	}
+	step := runctx.StepFromContext(ctx)
+	step.SetAmount(OutputBalance, balance)
+	step.SetAmount(OutputReceived, received)
+	return nil
+}
+
+// Watch describes a wait for the balance of a wallet: it ends when the balance rises by
+// MinIncrease over Baseline or reaches MinBalance, whichever is set and comes first.
+type Watch struct {
+	// Token is the awaited token; nil means the native coin.
+	Token *common.Address
+	// Decimals of Token, used to format the logs; read from the token when zero.
+	Decimals uint8
+	// Baseline is the starting balance; nil means the balance when the wait starts.
+	Baseline    *big.Int
+	MinIncrease *big.Int
+	MinBalance  *big.Int
+	// Timeout, PollInterval and MaxPollInterval default to 30m, 10s and 2m when zero.
+	Timeout         time.Duration
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+}
+
+// Run polls the balance with a doubling interval and returns it with the increase over the
+// baseline once the wait is satisfied. Transient RPC errors are logged and polling goes on; the
+// timeout is returned as a non-retryable ErrWaitTimeout.
+func (w Watch) Run(ctx context.Context, client evm.EVMClient, wallet common.Address, log logger.Logger) (balance, received *big.Int, err error) {
+	if w.MinIncrease == nil && w.MinBalance == nil {
+		return nil, nil, errclass.NonRetryable(errors.New("не задано условие ожидания баланса"))
+	}
+	timeout := cmp.Or(w.Timeout, defaultTimeout)
+	interval := cmp.Or(w.PollInterval, defaultPollInterval)
+	maxInterval := max(cmp.Or(w.MaxPollInterval, defaultMaxPollInterval), interval)
+	network := client.Network()
+	asset, decimals := network.NativeSymbol, uint8(18)
+	if w.Token != nil {
+		asset, decimals = w.Token.Hex(), w.Decimals
+		if decimals == 0 {
+			if decimals, err = evm.ERC20Decimals(ctx, client, *w.Token); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	baseline := w.Baseline
+	if baseline == nil {
+		if baseline, err = readBalance(ctx, client, wallet, w.Token); err != nil {
+			return nil, nil, err
+		}
+	}
+	var target *big.Int
+	if w.MinIncrease != nil {
+		target = new(big.Int).Add(baseline, w.MinIncrease)
+	}
+	log.Info("Ожидание поступления средств", "wallet", wallet.Hex(), "network", network.Name, "asset", asset,
+		"baseline", utils.FromUnits(baseline, decimals), "target", formatTarget(target, decimals),
+		"min_balance", formatTarget(w.MinBalance, decimals), "timeout", timeout)
+
+	for {
+		balance, err := readBalance(ctx, client, wallet, w.Token)
+		switch {
+		case err != nil && errclass.Classify(err) != types.ErrorClassRetryable:
+			return nil, nil, err
+		case err != nil:
+			log.Warn("Не удалось получить баланс, опрос продолжается", "wallet", wallet.Hex(), "error", err)
+		case target != nil && balance.Cmp(target) >= 0, w.MinBalance != nil && balance.Cmp(w.MinBalance) >= 0:
+			received := new(big.Int).Sub(balance, baseline)
+			if received.Sign() < 0 {
+				received.SetInt64(0)
+			}
+			log.Success("Средства поступили", "wallet", wallet.Hex(), "network", network.Name, "asset", asset,
+				"balance", utils.FromUnits(balance, decimals), "received", utils.FromUnits(received, decimals))
+			return balance, received, nil
+		default:
+			log.Debug("Средства еще не поступили", "wallet", wallet.Hex(), "asset", asset,
+				"balance", utils.FromUnits(balance, decimals), "next_check", interval)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, nil, errclass.NonRetryable(fmt.Errorf("%w: %s, сеть %s, ожидание %s", ErrWaitTimeout, asset, network.Name, timeout))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(min(interval, remaining)):
+		}
+		interval = min(interval*2, maxInterval)
+	}
+}
+
+// readBalance reads the native balance, or the token balance when token is set.
+func readBalance(ctx context.Context, client evm.EVMClient, wallet common.Address, token *common.Address) (*big.Int, error) {
+	if token == nil {
+		return client.GetBalance(ctx, wallet)
+	}
+	return evm.ERC20BalanceOf(ctx, client, *token, wallet)
+}
+
+// formatTarget formats an optional amount for the logs.
+func formatTarget(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "-"
+	}
+	return utils.FromUnits(amount, decimals)
+}
+
+// parseParams reads the task parameters; at least one of min_increase and min_balance is required.
+func parseParams(raw map[string]interface{}) (params, error) {
+	p := params{timeout: defaultTimeout, pollInterval: defaultPollInterval, maxPollInterval: defaultMaxPollInterval}
+	if value := raw["token"]; value != nil {
+		token, err := tasks.AddressParam(value, "token")
+		if err != nil {
+			return params{}, err
+		}
+		p.token = &token
+	}
+	for name, dst := range map[string]*string{"min_increase": &p.minIncrease, "min_balance": &p.minBalance} {
+		value := raw[name]
+		if value == nil {
+			continue
+		}
+		amount, ok := new(big.Float).SetString(fmt.Sprint(value))
+		if !ok || amount.Sign() <= 0 {
+			return params{}, fmt.Errorf("параметр '%s' должен быть положительной суммой, получено '%v'", name, value)
+		}
+		*dst = fmt.Sprint(value)
+	}
+	if p.minIncrease == "" && p.minBalance == "" {
+		return params{}, errors.New("не задан ни 'min_increase', ни 'min_balance'")
+	}
+	if value := raw["baseline"]; value != nil {
+		baseline, ok := new(big.Int).SetString(fmt.Sprint(value), 10)
+		if !ok || baseline.Sign() < 0 {
+			return params{}, fmt.Errorf("параметр 'baseline' должен быть целым неотрицательным числом, получено '%v'", value)
+		}
+		p.baseline = baseline
+	}
+	for name, dst := range map[string]*time.Duration{"timeout": &p.timeout, "poll_interval": &p.pollInterval, "max_poll_interval": &p.maxPollInterval} {
+		value := raw[name]
+		if value == nil {
+			continue
+		}
+		duration, err := time.ParseDuration(fmt.Sprint(value))
+		if err != nil || duration <= 0 {
+			return params{}, fmt.Errorf("параметр '%s' должен быть длительностью, например 30s или 10m, получено '%v'", name, value)
+		}
+		*dst = duration
+	}
+	return p, nil
+}
@@ -0,0 +1,181 @@
+package testharness
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"retro/internal/config"
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/storage"
+	"retro/internal/tasks"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// taskInterrupt cancels the run context once, as if the process was killed between two steps.
+const taskInterrupt types.TaskName = "harness_test_interrupt"
+
+var (
+	interruptMu  sync.Mutex
+	interruptRun context.CancelFunc
+)
+
+func init() {
+	tasks.MustRegisterConstructor(taskInterrupt, func(log logger.Logger) tasks.TaskRunner { return interruptTask{} })
+}
+
+type interruptTask struct{}
+
+func (interruptTask) Run(ctx context.Context, _ *evm.Signer, _ evm.EVMClient, _ map[string]interface{}) error {
+	interruptMu.Lock()
+	cancel := interruptRun
+	interruptRun = nil
+	interruptMu.Unlock()
+	if cancel != nil {
+		cancel()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestRunEndToEnd(t *testing.T) {
+	devnet := NewDevnet(t, 3)
+	client := devnet.Client(DevnetNetwork)
+	token := devnet.DeployERC20(t, "Test USD", "TUSD", 6)
+	weth := devnet.DeployWETH(t)
+	for i := range devnet.Keys {
+		devnet.Mint(t, token, devnet.Address(i), big.NewInt(1_000_000))
+	}
+	sink := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	cfg := NewConfig(
+		config.TaskConfigEntry{Name: TaskNativeTransfer, Params: map[string]interface{}{"to": sink.Hex(), "amount_wei": "1000"}},
+		config.TaskConfigEntry{Name: TaskERC20Transfer, Params: map[string]interface{}{"token": token.Hex(), "to": sink.Hex(), "amount": "250"}},
+		config.TaskConfigEntry{Name: TaskWETHDeposit, Params: map[string]interface{}{"weth": weth.Hex(), "amount_wei": "5000"}},
+	)
+	dbPath := filepath.Join(t.TempDir(), "retro.db")
+	store := OpenStorage(t, dbPath)
+	nonces := accountNonces(t, devnet)
+
+	RunApp(context.Background(), t, cfg, devnet, store)
+
+	if balance, err := client.GetBalance(context.Background(), sink); err != nil || balance.Int64() != 3000 {
+		t.Fatalf("sink native balance = %v, %v; want 3000", balance, err)
+	}
+	assertTokenBalance(t, client, token, sink, 750)
+	for i := range devnet.Keys {
+		assertTokenBalance(t, client, weth, devnet.Address(i), 5000)
+		assertNonceDelta(t, devnet, nonces, i, 3)
+
+		records := listRecords(t, store, devnet.Address(i))
+		if len(records) != 3 {
+			t.Fatalf("wallet %d: %d transaction records, want 3", i, len(records))
+		}
+		for _, record := range records {
+			if record.Status != types.TxStatusSuccess || record.TxHash == "" || record.IdempotencyKey == "" {
+				t.Fatalf("wallet %d: unexpected record %+v", i, record)
+			}
+		}
+	}
+
+	// A restart after a completed run resumes past the last wallet and sends nothing.
+	RunApp(context.Background(), t, cfg, devnet, OpenStorage(t, dbPath))
+	for i := range devnet.Keys {
+		assertNonceDelta(t, devnet, nonces, i, 3)
+	}
+}
+
+func TestRunResumesInterruptedWalletWithoutResending(t *testing.T) {
+	devnet := NewDevnet(t, 1)
+	weth := devnet.DeployWETH(t)
+	sink := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	cfg := NewConfig(
+		config.TaskConfigEntry{Name: TaskNativeTransfer, Params: map[string]interface{}{"to": sink.Hex(), "amount_wei": "1000"}},
+		config.TaskConfigEntry{Name: taskInterrupt},
+		config.TaskConfigEntry{Name: TaskWETHDeposit, Params: map[string]interface{}{"weth": weth.Hex(), "amount_wei": "5000"}},
+	)
+	dbPath := filepath.Join(t.TempDir(), "retro.db")
+	nonces := accountNonces(t, devnet)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interruptMu.Lock()
+	interruptRun = cancel
+	interruptMu.Unlock()
+	RunApp(ctx, t, cfg, devnet, OpenStorage(t, dbPath))
+	assertNonceDelta(t, devnet, nonces, 0, 1)
+
+	RunApp(context.Background(), t, cfg, devnet, OpenStorage(t, dbPath))
+
+	// The native transfer completed before the interruption and must not be sent again.
+	assertNonceDelta(t, devnet, nonces, 0, 2)
+	client := devnet.Client(DevnetNetwork)
+	if balance, err := client.GetBalance(context.Background(), sink); err != nil || balance.Int64() != 1000 {
+		t.Fatalf("sink native balance = %v, %v; want 1000", balance, err)
+	}
+	assertTokenBalance(t, client, weth, devnet.Address(0), 5000)
+}
+
+func TestRunAfterCompletedRunStartsNewRun(t *testing.T) {
+	devnet := NewDevnet(t, 2)
+	sink := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	cfg := NewConfig(config.TaskConfigEntry{Name: TaskNativeTransfer, Params: map[string]interface{}{"to": sink.Hex(), "amount_wei": "1000"}})
+	dbPath := filepath.Join(t.TempDir(), "retro.db")
+	nonces := accountNonces(t, devnet)
+
+	store := OpenStorage(t, dbPath)
+	RunApp(context.Background(), t, cfg, devnet, store)
+	if _, err := store.State.GetState(context.Background(), "current_run_id"); !errors.Is(err, storage.ErrStateNotFound) {
+		t.Fatalf("current_run_id after a completed run: %v, want it cleared", err)
+	}
+
+	// Resetting the progress starts a new run: its steps must not match the completed ones.
+	if err := store.State.DeleteState(context.Background(), "last_completed_wallet_index"); err != nil {
+		t.Fatalf("reset state: %v", err)
+	}
+	RunApp(context.Background(), t, cfg, devnet, OpenStorage(t, dbPath))
+	for i := range devnet.Keys {
+		assertNonceDelta(t, devnet, nonces, i, 2)
+	}
+}
+
+func accountNonces(t *testing.T, devnet *Devnet) []uint64 {
+	t.Helper()
+	nonces := make([]uint64, len(devnet.Keys))
+	for i := range devnet.Keys {
+		nonces[i] = accountNonce(t, devnet, i)
+	}
+	return nonces
+}
+
+func accountNonce(t *testing.T, devnet *Devnet, i int) uint64 {
+	t.Helper()
+	nonce, err := devnet.Client(DevnetNetwork).GetNonce(context.Background(), devnet.Address(i))
+	if err != nil {
+		t.Fatalf("nonce of account %d: %v", i, err)
+	}
+	return nonce
+}
+
+func assertNonceDelta(t *testing.T, devnet *Devnet, start []uint64, i int, want uint64) {
+	t.Helper()
+	if got := accountNonce(t, devnet, i) - start[i]; got != want {
+		t.Fatalf("account %d sent %d transactions, want %d", i, got, want)
+	}
+}
+
+func listRecords(t *testing.T, store *Storage, wallet common.Address) []storage.TransactionRecord {
+	t.Helper()
+	records, err := store.TxLogger.ListTransactions(context.Background(), storage.TransactionFilter{WalletAddress: wallet.Hex()})
+	if err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+	return records
+}
@@ -0,0 +1,108 @@
+package testharness
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// assembler builds EVM bytecode with named jump labels. Label references are emitted as
+// PUSH2 placeholders and patched by bytes(), so forward jumps work.
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	refs   map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: make(map[string]int), refs: make(map[int]string)}
+}
+
+// op appends plain opcodes.
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push appends the shortest PUSH of a non-negative value (int, uint64, *big.Int or []byte).
+func (a *assembler) push(value any) *assembler {
+	var data []byte
+	switch v := value.(type) {
+	case int:
+		data = big.NewInt(int64(v)).Bytes()
+	case uint64:
+		data = new(big.Int).SetUint64(v).Bytes()
+	case *big.Int:
+		data = v.Bytes()
+	case []byte:
+		data = new(big.Int).SetBytes(v).Bytes()
+	default:
+		panic(fmt.Sprintf("assembler: unsupported push value %T", value))
+	}
+	if len(data) == 0 {
+		return a.op(vm.PUSH0)
+	}
+	if len(data) > 32 {
+		panic("assembler: push value longer than 32 bytes")
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(data)-1))
+	a.code = append(a.code, data...)
+	return a
+}
+
+// pushLabel appends a PUSH2 of the label position.
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.refs[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+// label marks the current position with a JUMPDEST.
+func (a *assembler) label(name string) *assembler {
+	if _, exists := a.labels[name]; exists {
+		panic("assembler: duplicate label " + name)
+	}
+	a.labels[name] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+// jump appends an unconditional jump to the label.
+func (a *assembler) jump(name string) *assembler {
+	return a.pushLabel(name).op(vm.JUMP)
+}
+
+// jumpIf jumps to the label if the value on top of the stack is non-zero (and pops it).
+func (a *assembler) jumpIf(name string) *assembler {
+	return a.pushLabel(name).op(vm.JUMPI)
+}
+
+// bytes returns the code with all label references resolved.
+func (a *assembler) bytes() []byte {
+	code := make([]byte, len(a.code))
+	copy(code, a.code)
+	for offset, name := range a.refs {
+		position, ok := a.labels[name]
+		if !ok {
+			panic("assembler: undefined label " + name)
+		}
+		code[offset] = byte(position >> 8)
+		code[offset+1] = byte(position)
+	}
+	return code
+}
+
+// deployCode wraps runtime code into init code that copies it to memory and returns it.
+func deployCode(runtime []byte) []byte {
+	const initSize = 11
+	size := len(runtime)
+	init := []byte{
+		byte(vm.PUSH2), byte(size >> 8), byte(size), byte(vm.DUP1),
+		byte(vm.PUSH2), 0, initSize, byte(vm.PUSH0), byte(vm.CODECOPY),
+		byte(vm.PUSH0), byte(vm.RETURN),
+	}
+	return append(init, runtime...)
+}
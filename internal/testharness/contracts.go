@@ -0,0 +1,245 @@
+package testharness
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// TokenABI describes the mock tokens: the ERC-20 interface, mint (ERC-20 mock only)
+// and deposit/withdraw (WETH mock only).
+var TokenABI = mustParseABI(`[
+	{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"transferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"mint","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"deposit","stateMutability":"payable","inputs":[],"outputs":[]},
+	{"type":"function","name":"withdraw","stateMutability":"nonpayable","inputs":[{"name":"wad","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Deposit","inputs":[{"name":"dst","type":"address","indexed":true},{"name":"wad","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Withdrawal","inputs":[{"name":"src","type":"address","indexed":true},{"name":"wad","type":"uint256","indexed":false}]}
+]`)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Storage layout of the mock tokens: the balance of an address lives in the slot equal to the
+// address, an allowance in keccak256(owner, spender) and the total supply in a fixed high slot.
+var totalSupplySlot = new(big.Int).Lsh(big.NewInt(1), 200)
+
+var addressMask = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ERC20Code returns the init code of a mintable ERC-20 mock. Anyone can mint.
+func ERC20Code(name, symbol string, decimals uint8) []byte {
+	a := newAssembler()
+	tokenDispatch(a, true, false)
+	a.label("fallback").op(vm.POP).jump("revert")
+	tokenFunctions(a, name, symbol, decimals)
+	mintFunction(a)
+	a.label("revert").op(vm.PUSH0, vm.PUSH0, vm.REVERT)
+	return deployCode(a.bytes())
+}
+
+// WETHCode returns the init code of a WETH9-like mock: plain ETH transfers and unknown calls deposit.
+func WETHCode() []byte {
+	a := newAssembler()
+	tokenDispatch(a, false, true)
+	a.label("fallback").jump("deposit") // deposit pops the selector
+	tokenFunctions(a, "Wrapped Ether", "WETH", 18)
+	wethFunctions(a)
+	a.label("revert").op(vm.PUSH0, vm.PUSH0, vm.REVERT)
+	return deployCode(a.bytes())
+}
+
+// tokenDispatch jumps to the function matching the selector; the selector stays on the stack.
+func tokenDispatch(a *assembler, withMint, withWETH bool) {
+	functions := []string{"name", "symbol", "decimals", "totalSupply", "balanceOf", "allowance", "approve", "transfer", "transferFrom"}
+	if withMint {
+		functions = append(functions, "mint")
+	}
+	if withWETH {
+		functions = append(functions, "deposit", "withdraw")
+	}
+
+	a.op(vm.PUSH0, vm.CALLDATALOAD).push(0xe0).op(vm.SHR)
+	for _, name := range functions {
+		a.op(vm.DUP1).push(TokenABI.Methods[name].ID).op(vm.EQ).jumpIf(name)
+	}
+	a.jump("fallback")
+}
+
+// arg pushes the i-th 32-byte call argument.
+func arg(a *assembler, i int) *assembler {
+	return a.push(4 + 32*i).op(vm.CALLDATALOAD)
+}
+
+// addressArg pushes the i-th call argument masked to 160 bits.
+func addressArg(a *assembler, i int) *assembler {
+	return arg(a, i).push(addressMask).op(vm.AND)
+}
+
+// returnTop returns the value on top of the stack as a single word.
+func returnTop(a *assembler) {
+	a.op(vm.PUSH0, vm.MSTORE).push(0x20).op(vm.PUSH0, vm.RETURN)
+}
+
+// returnString returns an ABI-encoded string of at most 32 bytes.
+func returnString(a *assembler, value string) {
+	padded := make([]byte, 32)
+	copy(padded, value)
+	a.push(0x20).op(vm.PUSH0, vm.MSTORE)
+	a.push(len(value)).push(0x20).op(vm.MSTORE)
+	a.op(vm.PUSH32)
+	a.code = append(a.code, padded...)
+	a.push(0x40).op(vm.MSTORE)
+	a.push(0x60).op(vm.PUSH0, vm.RETURN)
+}
+
+// eventTopic returns the topic of the event with the given name.
+func eventTopic(name string) []byte {
+	return TokenABI.Events[name].ID.Bytes()
+}
+
+// pushTopic appends a full PUSH32 of an event topic (topics may start with zero bytes).
+func pushTopic(a *assembler, name string) *assembler {
+	a.op(vm.PUSH32)
+	a.code = append(a.code, eventTopic(name)...)
+	return a
+}
+
+// allowanceSlot computes keccak256(owner, spender) from the stack [owner, spender] (spender on top).
+func allowanceSlot(a *assembler) {
+	a.push(0x20).op(vm.MSTORE, vm.PUSH0, vm.MSTORE)
+	a.push(0x40).op(vm.PUSH0, vm.KECCAK256)
+}
+
+// transferBody moves tokens with the stack [from, to, amount] and emits Transfer; the stack is emptied.
+func transferBody(a *assembler) {
+	a.op(vm.DUP3, vm.SLOAD)         // [from, to, amount, balFrom]
+	a.op(vm.DUP2, vm.DUP2, vm.LT)   // balFrom < amount
+	a.jumpIf("revert")              // [from, to, amount, balFrom]
+	a.op(vm.DUP2, vm.SWAP1, vm.SUB) // [from, to, amount, balFrom-amount]
+	a.op(vm.DUP4, vm.SSTORE)        // [from, to, amount]
+	a.op(vm.DUP2, vm.SLOAD, vm.DUP2, vm.ADD, vm.DUP3, vm.SSTORE)
+	a.op(vm.DUP1, vm.PUSH0, vm.MSTORE) // memory[0] = amount
+	a.op(vm.DUP2, vm.DUP4)             // topics: to, from
+	pushTopic(a, "Transfer").push(0x20).op(vm.PUSH0, vm.LOG3)
+	a.op(vm.POP, vm.POP, vm.POP)
+}
+
+// returnTrue returns the ABI encoding of true.
+func returnTrue(a *assembler) {
+	a.push(1)
+	returnTop(a)
+}
+
+// tokenFunctions emits the ERC-20 functions shared by both mocks.
+func tokenFunctions(a *assembler, name, symbol string, decimals uint8) {
+	a.label("name").op(vm.POP)
+	returnString(a, name)
+
+	a.label("symbol").op(vm.POP)
+	returnString(a, symbol)
+
+	a.label("decimals").op(vm.POP).push(int(decimals))
+	returnTop(a)
+
+	a.label("totalSupply").op(vm.POP).push(totalSupplySlot).op(vm.SLOAD)
+	returnTop(a)
+
+	a.label("balanceOf").op(vm.POP)
+	addressArg(a, 0).op(vm.SLOAD)
+	returnTop(a)
+
+	a.label("allowance").op(vm.POP)
+	addressArg(a, 0)
+	addressArg(a, 1)
+	allowanceSlot(a)
+	a.op(vm.SLOAD)
+	returnTop(a)
+
+	a.label("approve").op(vm.POP)
+	arg(a, 1)
+	a.op(vm.CALLER)
+	addressArg(a, 0)
+	allowanceSlot(a)
+	a.op(vm.SSTORE)
+	arg(a, 1).op(vm.PUSH0, vm.MSTORE)
+	addressArg(a, 0).op(vm.CALLER)
+	pushTopic(a, "Approval").push(0x20).op(vm.PUSH0, vm.LOG3)
+	returnTrue(a)
+
+	a.label("transfer").op(vm.POP, vm.CALLER)
+	addressArg(a, 0)
+	arg(a, 1)
+	transferBody(a)
+	returnTrue(a)
+
+	a.label("transferFrom").op(vm.POP)
+	addressArg(a, 0)
+	addressArg(a, 1)
+	arg(a, 2) // [from, to, amount]
+	a.op(vm.DUP3, vm.CALLER)
+	allowanceSlot(a)                         // [from, to, amount, slot]
+	a.op(vm.DUP1, vm.SLOAD)                  // [.., slot, allowance]
+	a.op(vm.DUP1).push(maxUint256).op(vm.EQ) // infinite approval is not spent
+	a.jumpIf("transferFrom_unlimited")
+	a.op(vm.DUP3, vm.DUP2, vm.LT).jumpIf("revert") // allowance < amount
+	a.op(vm.DUP3, vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+	a.jump("transferFrom_move")
+	a.label("transferFrom_unlimited").op(vm.POP, vm.POP)
+	a.label("transferFrom_move")
+	transferBody(a)
+	returnTrue(a)
+}
+
+// mintFunction emits mint(to, amount), open to everyone.
+func mintFunction(a *assembler) {
+	a.label("mint").op(vm.POP)
+	addressArg(a, 0).op(vm.DUP1, vm.SLOAD)
+	arg(a, 1).op(vm.ADD, vm.SWAP1, vm.SSTORE)
+	a.push(totalSupplySlot).op(vm.SLOAD)
+	arg(a, 1).op(vm.ADD).push(totalSupplySlot).op(vm.SSTORE)
+	arg(a, 1).op(vm.PUSH0, vm.MSTORE)
+	addressArg(a, 0).op(vm.PUSH0)
+	pushTopic(a, "Transfer").push(0x20).op(vm.PUSH0, vm.LOG3)
+	a.op(vm.STOP)
+}
+
+// wethFunctions emits deposit() and withdraw(wad).
+func wethFunctions(a *assembler) {
+	a.label("deposit").op(vm.POP)
+	a.op(vm.CALLER, vm.DUP1, vm.SLOAD, vm.CALLVALUE, vm.ADD, vm.SWAP1, vm.SSTORE)
+	a.push(totalSupplySlot).op(vm.SLOAD, vm.CALLVALUE, vm.ADD).push(totalSupplySlot).op(vm.SSTORE)
+	a.op(vm.CALLVALUE, vm.PUSH0, vm.MSTORE, vm.CALLER)
+	pushTopic(a, "Deposit").push(0x20).op(vm.PUSH0, vm.LOG2)
+	a.op(vm.STOP)
+
+	a.label("withdraw").op(vm.POP)
+	arg(a, 0)                                      // [wad]
+	a.op(vm.CALLER, vm.SLOAD)                      // [wad, balance]
+	a.op(vm.DUP2, vm.DUP2, vm.LT).jumpIf("revert") // balance < wad
+	a.op(vm.DUP2, vm.SWAP1, vm.SUB, vm.CALLER, vm.SSTORE)
+	a.push(totalSupplySlot).op(vm.SLOAD, vm.DUP2, vm.SWAP1, vm.SUB).push(totalSupplySlot).op(vm.SSTORE)
+	a.op(vm.DUP1, vm.PUSH0, vm.MSTORE, vm.CALLER)
+	pushTopic(a, "Withdrawal").push(0x20).op(vm.PUSH0, vm.LOG2)
+	a.op(vm.PUSH0, vm.PUSH0, vm.PUSH0, vm.PUSH0, vm.DUP5, vm.CALLER, vm.GAS, vm.CALL)
+	a.op(vm.ISZERO).jumpIf("revert")
+	a.op(vm.STOP)
+}
@@ -0,0 +1,119 @@
+package testharness
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"retro/internal/evm"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestERC20Mock(t *testing.T) {
+	devnet := NewDevnet(t, 3)
+	client := devnet.Client(DevnetNetwork)
+	ctx := context.Background()
+	owner, spender, receiver := devnet.Address(0), devnet.Address(1), devnet.Address(2)
+
+	token := devnet.DeployERC20(t, "Test USD", "TUSD", 6)
+	if decimals, err := evm.ERC20Decimals(ctx, client, token); err != nil || decimals != 6 {
+		t.Fatalf("decimals = %d, %v; want 6", decimals, err)
+	}
+	if symbol := callString(t, client, token, "symbol"); symbol != "TUSD" {
+		t.Fatalf("symbol = %q, want TUSD", symbol)
+	}
+
+	devnet.Mint(t, token, owner, big.NewInt(1000))
+	receipt := devnet.Transact(t, 0, token, nil, "transfer", receiver, big.NewInt(300))
+	if len(receipt.Logs) != 1 || receipt.Logs[0].Topics[0] != TokenABI.Events["Transfer"].ID {
+		t.Fatalf("transfer logs = %+v, want one Transfer event", receipt.Logs)
+	}
+
+	devnet.Transact(t, 0, token, nil, "approve", spender, big.NewInt(200))
+	devnet.Transact(t, 1, token, nil, "transferFrom", owner, receiver, big.NewInt(150))
+
+	assertTokenBalance(t, client, token, owner, 550)
+	assertTokenBalance(t, client, token, receiver, 450)
+	if allowance := callUint(t, client, token, "allowance", owner, spender); allowance.Int64() != 50 {
+		t.Fatalf("allowance = %s, want 50", allowance)
+	}
+	if supply := callUint(t, client, token, "totalSupply"); supply.Int64() != 1000 {
+		t.Fatalf("totalSupply = %s, want 1000", supply)
+	}
+
+	data, _ := TokenABI.Pack("transferFrom", owner, receiver, big.NewInt(51))
+	if _, err := client.EstimateGasLimit(ctx, ethereum.CallMsg{From: spender, To: &token, Data: data}); err == nil {
+		t.Fatal("transferFrom above the allowance must revert")
+	}
+}
+
+func TestWETHMock(t *testing.T) {
+	devnet := NewDevnet(t, 1)
+	client := devnet.Client(DevnetNetwork)
+	ctx := context.Background()
+	holder := devnet.Address(0)
+
+	weth := devnet.DeployWETH(t)
+	devnet.Transact(t, 0, weth, big.NewInt(1e18), "deposit")
+	if _, err := evm.SendTx(ctx, client, devnet.Signer(0), weth, big.NewInt(5e17), nil); err != nil {
+		t.Fatalf("plain transfer to WETH: %v", err)
+	}
+	assertTokenBalance(t, client, weth, holder, 15e17)
+
+	before, err := client.GetBalance(ctx, holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := devnet.Transact(t, 0, weth, nil, "withdraw", big.NewInt(1e18))
+	after, err := client.GetBalance(ctx, holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	if got := new(big.Int).Sub(new(big.Int).Add(after, fee), before); got.Cmp(big.NewInt(1e18)) != 0 {
+		t.Fatalf("withdraw returned %s wei, want 1e18", got)
+	}
+	assertTokenBalance(t, client, weth, holder, 5e17)
+}
+
+func assertTokenBalance(t *testing.T, client evm.EVMClient, token, owner common.Address, want int64) {
+	t.Helper()
+	balance, err := evm.ERC20BalanceOf(context.Background(), client, token, owner)
+	if err != nil {
+		t.Fatalf("balanceOf(%s): %v", owner.Hex(), err)
+	}
+	if balance.Cmp(big.NewInt(want)) != 0 {
+		t.Fatalf("balanceOf(%s) = %s, want %d", owner.Hex(), balance, want)
+	}
+}
+
+func callUint(t *testing.T, client evm.EVMClient, token common.Address, method string, args ...interface{}) *big.Int {
+	t.Helper()
+	var out *big.Int
+	call(t, client, token, &out, method, args...)
+	return out
+}
+
+func callString(t *testing.T, client evm.EVMClient, token common.Address, method string) string {
+	t.Helper()
+	var out string
+	call(t, client, token, &out, method)
+	return out
+}
+
+func call(t *testing.T, client evm.EVMClient, token common.Address, out interface{}, method string, args ...interface{}) {
+	t.Helper()
+	data, err := TokenABI.Pack(method, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.SimulateCall(context.Background(), ethereum.CallMsg{To: &token, Data: data})
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	if err := TokenABI.UnpackIntoInterface(out, method, result); err != nil {
+		t.Fatalf("unpack %s: %v", method, err)
+	}
+}
@@ -0,0 +1,89 @@
+package testharness
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"retro/internal/evm"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DeployERC20 deploys a mintable ERC-20 mock from account 0 and returns its address.
+func (d *Devnet) DeployERC20(t testing.TB, name, symbol string, decimals uint8) common.Address {
+	t.Helper()
+	return d.deploy(t, ERC20Code(name, symbol, decimals))
+}
+
+// DeployWETH deploys a WETH mock from account 0 and returns its address.
+func (d *Devnet) DeployWETH(t testing.TB) common.Address {
+	t.Helper()
+	return d.deploy(t, WETHCode())
+}
+
+// Mint mints amount of the ERC-20 mock to the address.
+func (d *Devnet) Mint(t testing.TB, token, to common.Address, amount *big.Int) {
+	t.Helper()
+	d.Transact(t, 0, token, nil, "mint", to, amount)
+}
+
+// Transact calls a TokenABI method of the contract from the i-th account and fails the test
+// unless the transaction succeeds.
+func (d *Devnet) Transact(t testing.TB, account int, contract common.Address, value *big.Int, method string, args ...interface{}) *types.Receipt {
+	t.Helper()
+	data, err := TokenABI.Pack(method, args...)
+	if err != nil {
+		t.Fatalf("pack %s: %v", method, err)
+	}
+	receipt, err := evm.SendTx(context.Background(), d.Client(DevnetNetwork), d.Signer(account), contract, value, data)
+	if err != nil {
+		t.Fatalf("%s on %s: %v", method, contract.Hex(), err)
+	}
+	return receipt
+}
+
+// deploy sends a contract creation transaction from account 0 and returns the contract address.
+func (d *Devnet) deploy(t testing.TB, code []byte) common.Address {
+	t.Helper()
+	ctx := context.Background()
+	client := d.Client(DevnetNetwork)
+	signer := d.Signer(0)
+
+	nonce, err := client.GetNonce(ctx, signer.Address())
+	if err != nil {
+		t.Fatalf("deploy: nonce: %v", err)
+	}
+	gas, err := client.EstimateGasLimit(ctx, ethereum.CallMsg{From: signer.Address(), Data: code})
+	if err != nil {
+		t.Fatalf("deploy: estimate gas: %v", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		t.Fatalf("deploy: gas price: %v", err)
+	}
+	tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   client.GetChainID(),
+		Nonce:     nonce,
+		GasTipCap: gasPrice,
+		GasFeeCap: new(big.Int).Mul(gasPrice, big.NewInt(2)),
+		Gas:       gas * 2,
+		Data:      code,
+	}), client.GetChainID())
+	if err != nil {
+		t.Fatalf("deploy: sign: %v", err)
+	}
+	if err := client.SendRawTransaction(ctx, tx); err != nil {
+		t.Fatalf("deploy: send: %v", err)
+	}
+	receipt, err := client.WaitForReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("deploy: receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deploy: transaction %s reverted", tx.Hash().Hex())
+	}
+	return receipt.ContractAddress
+}
@@ -0,0 +1,137 @@
+package testharness
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"retro/internal/evm"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// limitedLogsClient serves eth_getLogs like a provider with a block range limit: ranges over
+// maxRange blocks fail with rangeErr.
+type limitedLogsClient struct {
+	*SimClient
+	blockRange uint64
+	maxRange   uint64
+	rangeErr   error
+	ranges     [][2]uint64
+}
+
+func (c *limitedLogsClient) Network() evm.NetworkInfo {
+	network := c.SimClient.Network()
+	network.LogsBlockRange = c.blockRange
+	return network
+}
+
+func (c *limitedLogsClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	c.ranges = append(c.ranges, [2]uint64{from, to})
+	if to-from+1 > c.maxRange {
+		return nil, c.rangeErr
+	}
+	return c.SimClient.FilterLogs(ctx, query)
+}
+
+func TestFilterLogsSplitsAndHalvesRanges(t *testing.T) {
+	devnet := NewDevnet(t, 1)
+	token := devnet.DeployERC20(t, "Test USD", "TUSD", 6)
+	for i := 0; i < 9; i++ {
+		devnet.Mint(t, token, devnet.Address(0), big.NewInt(1))
+	}
+	query := ethereum.FilterQuery{Addresses: []common.Address{token}}
+	want, err := devnet.Client(DevnetNetwork).FilterLogs(context.Background(), ethereum.FilterQuery{
+		Addresses: query.Addresses, FromBlock: big.NewInt(0),
+	})
+	if err != nil || len(want) != 9 {
+		t.Fatalf("unchunked logs = %d, %v; want 9", len(want), err)
+	}
+
+	client := &limitedLogsClient{SimClient: devnet.Client(DevnetNetwork), blockRange: 8, maxRange: 2,
+		rangeErr: errors.New("eth_getLogs block range is too wide: max 2 blocks, requested from block 429")}
+	logs, err := evm.FilterLogs(context.Background(), client, query)
+	if err != nil {
+		t.Fatalf("FilterLogs: %v", err)
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("FilterLogs returned %d logs, want %d", len(logs), len(want))
+	}
+	for i := range logs {
+		if logs[i].TxHash != want[i].TxHash || logs[i].Index != want[i].Index {
+			t.Fatalf("log %d = %s/%d, want %s/%d", i, logs[i].TxHash, logs[i].Index, want[i].TxHash, want[i].Index)
+		}
+	}
+	// 8 blocks are rejected, then 4, then the range settles at 2 blocks for the rest of the scan.
+	if first := client.ranges[0]; first != [2]uint64{0, 7} {
+		t.Fatalf("first request covers blocks %v, want [0 7]", first)
+	}
+	for _, r := range client.ranges[2:] {
+		if r[1]-r[0]+1 > 4 {
+			t.Fatalf("request %v after halving covers more than 4 blocks", r)
+		}
+	}
+	last := client.ranges[len(client.ranges)-1]
+	latest, _ := client.BlockNumber(context.Background())
+	if last[1] != latest {
+		t.Fatalf("last request ends at block %d, want latest %d", last[1], latest)
+	}
+}
+
+func TestFilterLogsDoesNotShrinkRangeOnRateLimit(t *testing.T) {
+	devnet := NewDevnet(t, 1)
+	rateLimited := errors.New("429 Too Many Requests: rate limit exceeded")
+	client := &limitedLogsClient{SimClient: devnet.Client(DevnetNetwork), blockRange: 8, maxRange: 0, rangeErr: rateLimited}
+
+	_, err := evm.FilterLogs(context.Background(), client, ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(7)})
+	if !errors.Is(err, rateLimited) {
+		t.Fatalf("FilterLogs error = %v, want the rate limit error", err)
+	}
+	if len(client.ranges) != 1 {
+		t.Fatalf("FilterLogs sent %d requests on a rate limit error, want 1", len(client.ranges))
+	}
+}
+
+func TestFindEventDecodesReceiptLogs(t *testing.T) {
+	devnet := NewDevnet(t, 2)
+	token := devnet.DeployERC20(t, "Test USD", "TUSD", 6)
+	other := devnet.DeployERC20(t, "Other", "OTH", 18)
+	devnet.Mint(t, token, devnet.Address(0), big.NewInt(1_000))
+	receipt := devnet.Transact(t, 0, token, nil, "transfer", devnet.Address(1), big.NewInt(250))
+
+	var transfer struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+	}
+	if err := evm.FindEvent(receipt, evm.ERC20ABI, "Transfer", &token, &transfer); err != nil {
+		t.Fatalf("FindEvent: %v", err)
+	}
+	if transfer.From != devnet.Address(0) || transfer.To != devnet.Address(1) || transfer.Value.Int64() != 250 {
+		t.Fatalf("decoded Transfer = %+v, want %s -> %s, 250", transfer, devnet.Address(0), devnet.Address(1))
+	}
+
+	logs, err := evm.FindEvents(receipt, evm.ERC20ABI, "Transfer", nil)
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("FindEvents = %d logs, %v; want 1", len(logs), err)
+	}
+	values, err := evm.UnpackLogIntoMap(evm.ERC20ABI, "Transfer", logs[0])
+	if err != nil || values["to"] != devnet.Address(1) || values["value"].(*big.Int).Int64() != 250 {
+		t.Fatalf("UnpackLogIntoMap = %v, %v", values, err)
+	}
+	if err := evm.UnpackLog(evm.ERC20ABI, "Approval", logs[0], &transfer); err == nil {
+		t.Fatal("UnpackLog decoded a Transfer log as Approval")
+	}
+
+	// Logs of another emitter and other events are not matched.
+	if err := evm.FindEvent(receipt, evm.ERC20ABI, "Transfer", &other, &transfer); !errors.Is(err, evm.ErrEventNotFound) {
+		t.Fatalf("FindEvent with another emitter: %v, want ErrEventNotFound", err)
+	}
+	if err := evm.FindEvent(receipt, evm.ERC20ABI, "Approval", nil, &transfer); !errors.Is(err, evm.ErrEventNotFound) {
+		t.Fatalf("FindEvent of a missing event: %v, want ErrEventNotFound", err)
+	}
+}
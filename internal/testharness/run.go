@@ -0,0 +1,91 @@
+package testharness
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"retro/internal/app"
+	"retro/internal/config"
+	"retro/internal/logger"
+	"retro/internal/platform/database"
+	"retro/internal/storage"
+	"retro/internal/types"
+)
+
+// Storage is a SQLite database shared by consecutive runs of one test, so resume can be exercised.
+type Storage struct {
+	TxLogger storage.TransactionLogger
+	State    storage.StateStorage
+}
+
+// OpenStorage opens (and migrates) the SQLite database at path; it is closed on test cleanup.
+// Opening the same path again gives a later run the state of the earlier ones.
+func OpenStorage(t testing.TB, path string) *Storage {
+	t.Helper()
+	txLogger, state, err := database.NewStorage(context.Background(), logger.NewColorLogger(), types.SQLite, path, "")
+	if err != nil {
+		t.Fatalf("open sqlite storage %s: %v", path, err)
+	}
+	t.Cleanup(func() { _ = txLogger.Close() })
+	return &Storage{TxLogger: txLogger, State: state}
+}
+
+// NewStorage opens a fresh SQLite database in the test's temporary directory.
+func NewStorage(t testing.TB) *Storage {
+	t.Helper()
+	return OpenStorage(t, filepath.Join(t.TempDir(), "retro.db"))
+}
+
+// NewConfig returns a config that runs the given tasks on the devnet, in order, for every wallet,
+// one wallet at a time, without delays and with resume enabled. Every task needs a unique name.
+func NewConfig(taskEntries ...config.TaskConfigEntry) *config.Config {
+	cfg := &config.Config{
+		RPCNodes: map[string]config.NetworkConfig{
+			DevnetNetwork: {URLs: []string{"simulated"}, ChainID: DevnetChainID, NativeSymbol: "ETH"},
+		},
+		Concurrency: config.ConcurrencyConfig{MaxParallelWallets: 1},
+		Wallets:     config.WalletsConfig{ProcessOrder: types.OrderSequential},
+		Delay: config.DelayConfig{
+			BetweenAccounts: config.DelayRange{Unit: types.TimeUnitSeconds},
+			BetweenActions:  config.DelayRange{Unit: types.TimeUnitSeconds},
+			AfterError:      config.DelayRange{Unit: types.TimeUnitSeconds},
+			BetweenRetries:  config.RetryDelay{Delay: config.DelayRange{Unit: types.TimeUnitSeconds}, Attempts: 1},
+		},
+		Actions: config.ActionsConfig{
+			ActionsPerAccount: config.MinMax{Min: len(taskEntries), Max: len(taskEntries)},
+			TaskOrder:         types.TaskOrderSequential,
+		},
+		Database: config.DatabaseConfig{Type: types.SQLite},
+		State:    config.StateConfig{ResumeEnabled: true},
+	}
+	for _, entry := range taskEntries {
+		entry.Enabled = true
+		if entry.Network == "" {
+			entry.Network = DevnetNetwork
+		}
+		cfg.Tasks = append(cfg.Tasks, entry)
+		cfg.Actions.ExplicitTaskSequence = append(cfg.Actions.ExplicitTaskSequence, string(entry.Name))
+	}
+	return cfg
+}
+
+// RunApp runs the application once over the devnet wallets, the same way cmd/app does, and
+// returns after all wallet processing has finished.
+func RunApp(ctx context.Context, t testing.TB, cfg *config.Config, devnet *Devnet, store *Storage) {
+	t.Helper()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid harness config: %v", err)
+	}
+	RegisterTasks()
+
+	var wg sync.WaitGroup
+	application := app.NewApplication(cfg, devnet.LoadedKeys(), &wg, store.TxLogger, store.State,
+		logger.NewColorLogger(), app.WithClientDialer(devnet.Dialer()))
+	application.Run(ctx)
+	wg.Wait()
+	if err := application.Close(); err != nil {
+		t.Errorf("close application: %v", err)
+	}
+}
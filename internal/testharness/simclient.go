@@ -0,0 +1,140 @@
+package testharness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"retro/internal/evm"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// receiptPollInterval is how often WaitForReceipt checks for a receipt.
+const receiptPollInterval = 10 * time.Millisecond
+
+// SimClient implements evm.EVMClient on top of the simulated backend.
+// Every accepted transaction is mined right away.
+type SimClient struct {
+	devnet  *Devnet
+	client  simulated.Client
+	chainID *big.Int
+	network evm.NetworkInfo
+}
+
+// Ensure SimClient implements EVMClient interface at compile time.
+var _ evm.EVMClient = (*SimClient)(nil)
+
+// Close is a no-op: the backend is owned by the devnet.
+func (c *SimClient) Close() {}
+
+// GetChainID returns the devnet chain ID.
+func (c *SimClient) GetChainID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+// Network returns the description of the network the client was created for.
+func (c *SimClient) Network() evm.NetworkInfo {
+	return c.network
+}
+
+// GetBalance returns the native balance at the latest block.
+func (c *SimClient) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	return c.client.BalanceAt(ctx, address, nil)
+}
+
+// GetNonce returns the next nonce of the account including pending transactions.
+func (c *SimClient) GetNonce(ctx context.Context, address common.Address) (uint64, error) {
+	return c.client.PendingNonceAt(ctx, address)
+}
+
+// GetConfirmedNonce returns the next nonce of the account at the latest block.
+func (c *SimClient) GetConfirmedNonce(ctx context.Context, address common.Address) (uint64, error) {
+	return c.client.NonceAt(ctx, address, nil)
+}
+
+// SuggestGasPrice suggests a legacy gas price.
+func (c *SimClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.client.SuggestGasPrice(ctx)
+}
+
+// SuggestGasTipCap suggests a priority fee.
+func (c *SimClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return c.client.SuggestGasTipCap(ctx)
+}
+
+// EstimateGasLimit estimates the gas of a call.
+func (c *SimClient) EstimateGasLimit(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return c.client.EstimateGas(ctx, msg)
+}
+
+// SendRawTransaction submits the transaction and mines a block with it.
+func (c *SimClient) SendRawTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.client.SendTransaction(ctx, tx); err != nil {
+		return fmt.Errorf("sending transaction failed: %w", err)
+	}
+	c.devnet.Commit()
+	return nil
+}
+
+// WaitForReceipt polls for the receipt until it is available or ctx is done.
+func (c *SimClient) WaitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	for {
+		receipt, err := c.client.TransactionReceipt(ctx, txHash)
+		if err == nil && receipt != nil {
+			return receipt, nil
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("error fetching receipt: %w", err)
+		}
+		select {
+		case <-time.After(receiptPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// SimulateCall performs eth_call at the latest block.
+func (c *SimClient) SimulateCall(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	result, err := c.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка eth_call: %w", err)
+	}
+	return result, nil
+}
+
+// BlockNumber returns the number of the latest devnet block.
+func (c *SimClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.client.BlockNumber(ctx)
+}
+
+// FilterLogs returns the devnet logs matching the query.
+func (c *SimClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return c.client.FilterLogs(ctx, query)
+}
+
+// GetTransactionState reports whether a transaction is mined, pending or unknown to the devnet.
+func (c *SimClient) GetTransactionState(ctx context.Context, txHash common.Hash) (evm.TxState, *types.Receipt, error) {
+	receipt, err := c.client.TransactionReceipt(ctx, txHash)
+	if err == nil && receipt != nil {
+		return evm.TxStateMined, receipt, nil
+	}
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return "", nil, fmt.Errorf("error fetching receipt: %w", err)
+	}
+
+	_, _, err = c.client.TransactionByHash(ctx, txHash)
+	if err == nil {
+		return evm.TxStatePending, nil, nil
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return evm.TxStateDropped, nil, nil
+	}
+	return "", nil, fmt.Errorf("error fetching transaction: %w", err)
+}
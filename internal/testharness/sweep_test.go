@@ -0,0 +1,70 @@
+package testharness
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"retro/internal/config"
+	"retro/internal/tasks"
+	sweeptask "retro/internal/tasks/sweep"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func init() {
+	tasks.MustRegisterConstructor(types.TaskNameSweep, sweeptask.NewTask)
+}
+
+func TestSweepLeavesOnlyUnspentFee(t *testing.T) {
+	devnet := NewDevnet(t, 2)
+	client := devnet.Client(DevnetNetwork)
+	token := devnet.DeployERC20(t, "Test USD", "TUSD", 6)
+	for i := range devnet.Keys {
+		devnet.Mint(t, token, devnet.Address(i), big.NewInt(1_000_000))
+	}
+	collector := common.HexToAddress("0x000000000000000000000000000000000000c011")
+
+	cfg := NewConfig(config.TaskConfigEntry{Name: types.TaskNameSweep, Params: map[string]interface{}{
+		"to":     collector.Hex(),
+		"tokens": []interface{}{token.Hex()},
+	}})
+	store := NewStorage(t)
+	RunApp(context.Background(), t, cfg, devnet, store)
+
+	ctx := context.Background()
+	for i := range devnet.Keys {
+		assertTokenBalance(t, client, token, devnet.Address(i), 0)
+		records := listRecords(t, store, devnet.Address(i))
+		if len(records) != 1 || records[0].Status != types.TxStatusSuccess {
+			t.Fatalf("wallet %d: unexpected records %+v", i, records)
+		}
+
+		// The native sweep is the last transaction of the task; the wallet keeps only the part of
+		// the max fee that was not charged.
+		txHash := common.HexToHash(records[0].TxHash)
+		tx, _, err := devnet.Backend.Client().TransactionByHash(ctx, txHash)
+		if err != nil {
+			t.Fatalf("wallet %d: sweep transaction: %v", i, err)
+		}
+		receipt, err := devnet.Backend.Client().TransactionReceipt(ctx, txHash)
+		if err != nil {
+			t.Fatalf("wallet %d: sweep receipt: %v", i, err)
+		}
+		if tx.GasTipCap().Cmp(tx.GasFeeCap()) >= 0 {
+			t.Fatalf("wallet %d: tip %s is not below the max fee %s", i, tx.GasTipCap(), tx.GasFeeCap())
+		}
+		maxFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+		paidFee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+		want := maxFee.Sub(maxFee, paidFee)
+		balance, err := client.GetBalance(ctx, devnet.Address(i))
+		if err != nil || balance.Cmp(want) != 0 {
+			t.Fatalf("wallet %d native balance after sweep = %v, %v; want the unspent fee %s", i, balance, err, want)
+		}
+	}
+	assertTokenBalance(t, client, token, collector, 2_000_000)
+	if balance, err := client.GetBalance(ctx, collector); err != nil || balance.Sign() <= 0 {
+		t.Fatalf("collector native balance = %v, %v; want the swept funds", balance, err)
+	}
+}
@@ -0,0 +1,145 @@
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"retro/internal/evm"
+	"retro/internal/logger"
+	"retro/internal/runctx"
+	"retro/internal/tasks"
+	"retro/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Task names of the harness tasks. They send real transactions to the devnet.
+const (
+	// TaskNativeTransfer sends amount_wei of the native coin to the address "to".
+	TaskNativeTransfer types.TaskName = "harness_native_transfer"
+	// TaskERC20Transfer transfers "amount" units of the ERC-20 "token" to the address "to".
+	TaskERC20Transfer types.TaskName = "harness_erc20_transfer"
+	// TaskWETHDeposit wraps amount_wei of the native coin into the WETH mock at "weth".
+	TaskWETHDeposit types.TaskName = "harness_weth_deposit"
+)
+
+var registerOnce sync.Once
+
+// RegisterTasks registers the harness tasks in the global task registry. It can be called any number of times.
+func RegisterTasks() {
+	registerOnce.Do(func() {
+		tasks.MustRegisterConstructor(TaskNativeTransfer, newTxTask(nativeTransfer,
+			tasks.ParamSpec{Name: "to", Type: "address", Required: true, Description: "получатель"},
+			tasks.ParamSpec{Name: "amount_wei", Type: "wei", Required: true, Description: "сумма в wei"}))
+		tasks.MustRegisterConstructor(TaskERC20Transfer, newTxTask(erc20Transfer,
+			tasks.ParamSpec{Name: "token", Type: "address", Required: true, Description: "адрес токена"},
+			tasks.ParamSpec{Name: "to", Type: "address", Required: true, Description: "получатель"},
+			tasks.ParamSpec{Name: "amount", Type: "units", Required: true, Description: "сумма в минимальных единицах токена"}))
+		tasks.MustRegisterConstructor(TaskWETHDeposit, newTxTask(wethDeposit,
+			tasks.ParamSpec{Name: "weth", Type: "address", Required: true, Description: "адрес контракта WETH"},
+			tasks.ParamSpec{Name: "amount_wei", Type: "wei", Required: true, Description: "сумма в wei"}))
+	})
+}
+
+// txBuilder turns task params into the destination, value and calldata of one transaction.
+type txBuilder func(params map[string]interface{}) (to common.Address, value *big.Int, data []byte, err error)
+
+// txTask sends the transaction built from its params and stores the hash as the "tx_hash" output.
+type txTask struct {
+	build  txBuilder
+	schema []tasks.ParamSpec
+	log    logger.Logger
+}
+
+func newTxTask(build txBuilder, schema ...tasks.ParamSpec) tasks.TaskConstructor {
+	return func(log logger.Logger) tasks.TaskRunner {
+		return &txTask{build: build, schema: schema, log: log}
+	}
+}
+
+// ParamSchema implements tasks.ParamDescriber.
+func (t *txTask) ParamSchema() []tasks.ParamSpec {
+	return t.schema
+}
+
+// Run builds, sends and waits for the task's transaction.
+func (t *txTask) Run(ctx context.Context, signer *evm.Signer, client evm.EVMClient, params map[string]interface{}) error {
+	to, value, data, err := t.build(params)
+	if err != nil {
+		return err
+	}
+	receipt, err := evm.SendTx(ctx, client, signer, to, value, data)
+	if err != nil {
+		return err
+	}
+	runctx.StepFromContext(ctx).SetTxHash("tx_hash", receipt.TxHash)
+	t.log.Success("Транзакция тестовой задачи подтверждена", "wallet", signer.Address().Hex(), "tx_hash", receipt.TxHash.Hex())
+	return nil
+}
+
+func nativeTransfer(params map[string]interface{}) (common.Address, *big.Int, []byte, error) {
+	to, err := addressParam(params, "to")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	amount, err := amountParam(params, "amount_wei")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return to, amount, nil, nil
+}
+
+func erc20Transfer(params map[string]interface{}) (common.Address, *big.Int, []byte, error) {
+	token, err := addressParam(params, "token")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	to, err := addressParam(params, "to")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	amount, err := amountParam(params, "amount")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	data, err := TokenABI.Pack("transfer", to, amount)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to pack transfer call: %w", err)
+	}
+	return token, nil, data, nil
+}
+
+func wethDeposit(params map[string]interface{}) (common.Address, *big.Int, []byte, error) {
+	weth, err := addressParam(params, "weth")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	amount, err := amountParam(params, "amount_wei")
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	data, err := TokenABI.Pack("deposit")
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to pack deposit call: %w", err)
+	}
+	return weth, amount, data, nil
+}
+
+func addressParam(params map[string]interface{}, key string) (common.Address, error) {
+	value := fmt.Sprint(params[key])
+	if !common.IsHexAddress(value) {
+		return common.Address{}, fmt.Errorf("параметр '%s' должен быть адресом, получено '%s'", key, value)
+	}
+	return common.HexToAddress(value), nil
+}
+
+func amountParam(params map[string]interface{}, key string) (*big.Int, error) {
+	value := fmt.Sprint(params[key])
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("параметр '%s' должен быть неотрицательным целым числом, получено '%s'", key, value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,12 @@
+package types
+
+// BroadcastStatus defines the lifecycle status of a broadcast transaction.
+type BroadcastStatus string
+
+const (
+	// BroadcastPending means the transaction was signed and handed to the node but no receipt was seen yet.
+	BroadcastPending  BroadcastStatus = "pending"
+	BroadcastMined    BroadcastStatus = "mined"
+	BroadcastReverted BroadcastStatus = "reverted"
+	BroadcastDropped  BroadcastStatus = "dropped"
+)
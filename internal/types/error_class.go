@@ -0,0 +1,10 @@
+package types
+
+// ErrorClass defines how the executor treats a task error.
+type ErrorClass string
+
+const (
+	ErrorClassRetryable    ErrorClass = "retryable"
+	ErrorClassNonRetryable ErrorClass = "non_retryable"
+	ErrorClassFatal        ErrorClass = "fatal"
+)
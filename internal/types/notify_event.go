@@ -0,0 +1,26 @@
+package types
+
+// NotifyEvent is the type of a notification sent to the configured sinks.
+type NotifyEvent string
+
+const (
+	NotifyRunStarted   NotifyEvent = "run_started"
+	NotifyRunFinished  NotifyEvent = "run_finished"
+	NotifyWalletFailed NotifyEvent = "wallet_failed"
+	NotifyCircuitOpen  NotifyEvent = "circuit_open"
+	NotifyLowBalance   NotifyEvent = "low_balance"
+)
+
+// NotifyEvents lists all notification types.
+var NotifyEvents = []NotifyEvent{
+	NotifyRunStarted, NotifyRunFinished, NotifyWalletFailed, NotifyCircuitOpen, NotifyLowBalance,
+}
+
+// SinkType is the kind of a notification sink.
+type SinkType string
+
+const (
+	SinkWebhook  SinkType = "webhook"
+	SinkTelegram SinkType = "telegram"
+	SinkDiscord  SinkType = "discord"
+)
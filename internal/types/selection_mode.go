@@ -0,0 +1,9 @@
+package types
+
+// SelectionMode defines how random tasks are picked for a single wallet.
+type SelectionMode string
+
+const (
+	SelectionWithReplacement    SelectionMode = "with_replacement"
+	SelectionWithoutReplacement SelectionMode = "without_replacement"
+)
@@ -0,0 +1,10 @@
+package types
+
+// StepStatus defines the outcome of a single task step within one wallet run.
+type StepStatus string
+
+const (
+	StepStatusSuccess StepStatus = "success"
+	StepStatusFailed  StepStatus = "failed"
+	StepStatusSkipped StepStatus = "skipped"
+)
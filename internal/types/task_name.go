@@ -4,6 +4,10 @@ package types
 type TaskName string
 
 const (
-	TaskNameLogBalance TaskName = "log_balance"
-	TaskNameDummy      TaskName = "dummy_task"
+	TaskNameLogBalance      TaskName = "log_balance"
+	TaskNameDummy           TaskName = "dummy_task"
+	TaskNameSweep           TaskName = "sweep"
+	TaskNameRevokeApprovals TaskName = "revoke_approvals"
+	TaskNameWaitForBalance  TaskName = "wait_for_balance"
+	TaskNameBridge          TaskName = "bridge"
 )
@@ -1,9 +1,32 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
 // TimeUnit defines the units for time delays.
 type TimeUnit string
 
 const (
 	TimeUnitSeconds TimeUnit = "seconds"
 	TimeUnitMinutes TimeUnit = "minutes"
+	TimeUnitHours   TimeUnit = "hours"
+	TimeUnitDays    TimeUnit = "days"
 )
+
+// Duration returns the length of one unit. An empty unit means seconds.
+func (u TimeUnit) Duration() (time.Duration, error) {
+	switch u {
+	case TimeUnitSeconds, "":
+		return time.Second, nil
+	case TimeUnitMinutes:
+		return time.Minute, nil
+	case TimeUnitHours:
+		return time.Hour, nil
+	case TimeUnitDays:
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unknown delay unit: %s", u)
+	}
+}
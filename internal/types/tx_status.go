@@ -7,4 +7,9 @@ const (
 	TxStatusSuccess         TxStatus = "Success"
 	TxStatusFailed          TxStatus = "Failed"
 	TxStatusErrorBeforeSend TxStatus = "ErrorBeforeSend"
+	TxStatusSkipped         TxStatus = "Skipped"
+	// TxStatusPending marks a transaction that was sent but whose receipt was never seen.
+	TxStatusPending TxStatus = "Pending"
+	// TxStatusDryRun marks a step executed in dry-run mode: its transactions were signed but not sent.
+	TxStatusDryRun TxStatus = "DryRun"
 )
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"retro/internal/config"
+)
+
+// BackoffDuration grows the base delay exponentially for the given retry attempt (1-based),
+// caps it at the configured maximum and applies random jitter.
+func BackoffDuration(base time.Duration, attempt int, backoff config.BackoffConfig) time.Duration {
+	delay := float64(base)
+	if backoff.Multiplier > 1 && attempt > 1 {
+		delay *= math.Pow(backoff.Multiplier, float64(attempt-1))
+	}
+
+	if maxDelay, err := backoff.MaxDelay.Duration(); err == nil && maxDelay > 0 && delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+
+	if backoff.Jitter > 0 {
+		delay *= 1 + backoff.Jitter*(2*rand.Float64()-1)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
@@ -65,3 +65,34 @@ func FromGwei(gweiAmount *big.Int) string {
 	amountFloat.Quo(amountFloat, gweiScale)
 	return strings.TrimRight(strings.TrimRight(amountFloat.Text('f', 9), "0"), ".")
 }
+
+// ToUnits converts a decimal string to *big.Int in the smallest units of a token with the given decimals.
+func ToUnits(decimalAmount string, decimals uint8) (*big.Int, error) {
+	amountFloat, _, err := big.ParseFloat(decimalAmount, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка парсинга строки '%s' в число: %w", decimalAmount, err)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	amountFloat.Mul(amountFloat, new(big.Float).SetInt(scale))
+
+	units := new(big.Int)
+	amountFloat.Int(units)
+
+	return units, nil
+}
+
+// FromUnits converts a *big.Int in the smallest token units to a decimal string.
+func FromUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	amountFloat := new(big.Float).SetInt(amount)
+	amountFloat.Quo(amountFloat, new(big.Float).SetInt(scale))
+	text := amountFloat.Text('f', int(decimals))
+	if decimals == 0 {
+		return text
+	}
+	return strings.TrimRight(strings.TrimRight(text, "0"), ".")
+}
@@ -1,12 +1,10 @@
 package utils
 
 import (
-	"fmt"
 	"math/rand"
 	"time"
 
 	"retro/internal/config"
-	"retro/internal/types"
 )
 
 // RandomIntInRange returns a random integer within the range [min, max]
@@ -23,16 +21,11 @@ func RandomIntInRange(min, max int) int {
 // RandomDuration returns a random time.Duration based on the config DelayRange
 func RandomDuration(delayRange config.DelayRange) (time.Duration, error) {
 	randomVal := RandomIntInRange(delayRange.Min, delayRange.Max)
-	switch delayRange.Unit {
-	case types.TimeUnitSeconds:
-		return time.Duration(randomVal) * time.Second, nil
-	case types.TimeUnitMinutes:
-		return time.Duration(randomVal) * time.Minute, nil
-	case "":
-		return time.Duration(randomVal) * time.Second, nil
-	default:
-		return 0, fmt.Errorf("unknown delay unit: %s", delayRange.Unit)
+	unit, err := delayRange.Unit.Duration()
+	if err != nil {
+		return 0, err
 	}
+	return time.Duration(randomVal) * unit, nil
 }
 
 // init initializes the random number generator seed when the package is loaded